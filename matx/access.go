@@ -75,7 +75,11 @@ func Set(a float64, m *Matx, coordinates ...int) error {
 // GetRow returns a slice representing the `row`th row of a 2D matrix `m`.
 // Returns an error if `row` is out of bounds.
 // Assumes matrix has exactly two dimensions.
-func GetRow(m *Matx, row int) ([]float64, error) {
+func GetRow(m *Matx, row int) (_ []float64, err error) {
+	if span := startTrace("GetRow", m); span != nil {
+		defer func() { span.end(0, 0, err) }()
+	}
+
 	if len(m.Dimensions) != 2 {
 		return nil, fmt.Errorf("GetRow only supports 2D matrices")
 	}
@@ -92,7 +96,11 @@ func GetRow(m *Matx, row int) ([]float64, error) {
 // GetCol returns a slice representing the `col`th column of a 2D matrix `m`.
 // Returns an error if `col` is out of bounds.
 // Assumes matrix has exactly two dimensions.
-func GetCol(m *Matx, col int) ([]float64, error) {
+func GetCol(m *Matx, col int) (result []float64, err error) {
+	if span := startTrace("GetCol", m); span != nil {
+		defer func() { span.end(0, 8*int64(len(result)), err) }()
+	}
+
 	if len(m.Dimensions) != 2 {
 		return nil, fmt.Errorf("GetCol only supports 2D matrices")
 	}
@@ -100,7 +108,7 @@ func GetCol(m *Matx, col int) ([]float64, error) {
 		return nil, fmt.Errorf("column out of range")
 	}
 
-	result := make([]float64, m.Dimensions[0])
+	result = make([]float64, m.Dimensions[0])
 	for i := 0; i < m.Dimensions[0]; i++ {
 		result[i] = m.Data[i*m.Dimensions[1]+col]
 	}
@@ -0,0 +1,485 @@
+package matx
+
+import (
+	"fmt"
+	"math"
+)
+
+// Tridiagonal is an n×n matrix with nonzeros only on the main diagonal and the
+// diagonals directly below and above it.
+//   - Diag holds the n diagonal entries.
+//   - Lower[i] is the entry at (i+1, i) and Upper[i] the entry at (i, i+1).
+type Tridiagonal struct {
+	Lower []float64
+	Diag  []float64
+	Upper []float64
+}
+
+// Band is an n×n matrix with KL subdiagonals and KU superdiagonals.
+// Each row stores the KL+KU+1 band entries contiguously: entry (i, j) lives at
+// Data[i*(KL+KU+1) + j-i+KL]. Slots that fall outside the matrix are kept at zero.
+type Band struct {
+	N, KL, KU int
+	Data      []float64
+}
+
+// BandLU is an LU factorization with partial pivoting of a band matrix.
+// U has KL+KU superdiagonals, since row interchanges widen the upper band.
+// Pivots[k] is the row interchanged with row k at step k; unlike LU.Pivots it is a
+// sequence of swaps, because applying them to L would break its band structure.
+type BandLU struct {
+	N, KL, KU int
+	Pivots    []int
+	Swaps     int
+	factors   []float64 // rows of width 2·KL+KU+1, entry (i, j) at j-i+KL
+}
+
+// BandCholesky is the Cholesky factorization A = L·Lᵀ of a symmetric positive definite
+// band matrix. L has the same lower bandwidth as A.
+type BandCholesky struct {
+	N, KD int
+	l     []float64 // rows of width KD+1, entry (i, j) at j-i+KD
+}
+
+// NewTridiagonal builds a tridiagonal matrix from its three diagonals.
+// The slices are used as-is, not copied. Returns an error if len(lower) or
+// len(upper) is not len(diag)-1.
+func NewTridiagonal(lower, diag, upper []float64) (*Tridiagonal, error) {
+	if len(diag) == 0 {
+		return nil, fmt.Errorf("tridiagonal matrix needs at least one diagonal entry")
+	}
+	if len(lower) != len(diag)-1 || len(upper) != len(diag)-1 {
+		return nil, fmt.Errorf("off-diagonals must have length %d, got %d and %d", len(diag)-1, len(lower), len(upper))
+	}
+	return &Tridiagonal{Lower: lower, Diag: diag, Upper: upper}, nil
+}
+
+// Dims returns the number of rows and columns.
+func (t *Tridiagonal) Dims() (int, int) { return len(t.Diag), len(t.Diag) }
+
+// At returns the entry at (i, j); entries off the three diagonals are zero.
+func (t *Tridiagonal) At(i, j int) (float64, error) {
+	n := len(t.Diag)
+	if i < 0 || i >= n || j < 0 || j >= n {
+		return 0, fmt.Errorf("index (%d, %d) out of bounds for %dx%d matrix", i, j, n, n)
+	}
+	switch j - i {
+	case 0:
+		return t.Diag[i], nil
+	case -1:
+		return t.Lower[j], nil
+	case 1:
+		return t.Upper[i], nil
+	}
+	return 0, nil
+}
+
+// MulVec returns A·x for a vector of length n.
+func (t *Tridiagonal) MulVec(x []float64) ([]float64, error) {
+	y := make([]float64, len(t.Diag))
+	if err := t.Apply(y, x); err != nil {
+		return nil, err
+	}
+	return y, nil
+}
+
+// Apply writes A·x into dst, making *Tridiagonal an Operator.
+func (t *Tridiagonal) Apply(dst, x []float64) error {
+	n := len(t.Diag)
+	if err := checkApply(n, n, dst, x); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		sum := t.Diag[i] * x[i]
+		if i > 0 {
+			sum += t.Lower[i-1] * x[i-1]
+		}
+		if i < n-1 {
+			sum += t.Upper[i] * x[i+1]
+		}
+		dst[i] = sum
+	}
+	return nil
+}
+
+// ApplyTranspose writes Aᵀ·x into dst, making *Tridiagonal a LinearOperator.
+func (t *Tridiagonal) ApplyTranspose(dst, x []float64) error {
+	return (&Tridiagonal{Lower: t.Upper, Diag: t.Diag, Upper: t.Lower}).Apply(dst, x)
+}
+
+// Solve returns x such that A x = b using the Thomas algorithm in O(n).
+// No pivoting is done, which is stable for diagonally dominant or symmetric positive
+// definite matrices; use ToBand and BandLUFactor for anything else.
+func (t *Tridiagonal) Solve(b []float64) ([]float64, error) {
+	return ThomasSolve(t.Lower, t.Diag, t.Upper, b)
+}
+
+// ToBand returns a copy of the matrix in general band storage with KL = KU = 1.
+func (t *Tridiagonal) ToBand() *Band {
+	n := len(t.Diag)
+	b := &Band{N: n, KL: 1, KU: 1, Data: make([]float64, 3*n)}
+	for i := 0; i < n; i++ {
+		b.Data[3*i+1] = t.Diag[i]
+		if i > 0 {
+			b.Data[3*i] = t.Lower[i-1]
+		}
+		if i < n-1 {
+			b.Data[3*i+2] = t.Upper[i]
+		}
+	}
+	return b
+}
+
+// ToMatx expands the matrix to a dense 2D Matx.
+func (t *Tridiagonal) ToMatx() (*Matx, error) {
+	return t.ToBand().ToMatx()
+}
+
+// ThomasSolve solves the tridiagonal system with sub-diagonal `lower`, diagonal `diag`
+// and super-diagonal `upper` in O(n), without modifying the inputs.
+// Returns an error on mismatched lengths or a zero pivot.
+func ThomasSolve(lower, diag, upper, b []float64) (x []float64, err error) {
+	n := len(diag)
+	if span := startTraceShapes("ThomasSolve", []int{n, n}, []int{len(b)}); span != nil {
+		defer func() { span.end(8*int64(n), 8*int64(len(x)), err) }()
+	}
+
+	if n == 0 || len(lower) != n-1 || len(upper) != n-1 {
+		return nil, fmt.Errorf("off-diagonals must have length %d, got %d and %d", n-1, len(lower), len(upper))
+	}
+	if len(b) != n {
+		return nil, fmt.Errorf("right-hand side length %d does not match matrix order %d", len(b), n)
+	}
+
+	// Forward sweep: c holds the modified super-diagonal, x the modified right-hand side
+	c := make([]float64, n)
+	x = make([]float64, n)
+	denom := diag[0]
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			denom = diag[i] - lower[i-1]*c[i-1]
+		}
+		if denom == 0 {
+			return nil, fmt.Errorf("zero pivot in row %d of tridiagonal system", i)
+		}
+		if i < n-1 {
+			c[i] = upper[i] / denom
+		}
+		x[i] = b[i]
+		if i > 0 {
+			x[i] -= lower[i-1] * x[i-1]
+		}
+		x[i] /= denom
+	}
+
+	// Back substitution
+	for i := n - 2; i >= 0; i-- {
+		x[i] -= c[i] * x[i+1]
+	}
+	return x, nil
+}
+
+// NewBand returns an n×n zero band matrix with kl subdiagonals and ku superdiagonals.
+func NewBand(n, kl, ku int) (*Band, error) {
+	if n <= 0 || kl < 0 || ku < 0 {
+		return nil, fmt.Errorf("invalid band shape n=%d kl=%d ku=%d", n, kl, ku)
+	}
+	return &Band{N: n, KL: kl, KU: ku, Data: make([]float64, n*(kl+ku+1))}, nil
+}
+
+// BandFromMatx copies the band of a square 2D matrix into band storage.
+// Returns an error if any entry outside the band is nonzero.
+func BandFromMatx(m *Matx, kl, ku int) (*Band, error) {
+	if m == nil || len(m.Dimensions) != 2 || m.Dimensions[0] != m.Dimensions[1] {
+		return nil, fmt.Errorf("band storage requires a square 2D matrix")
+	}
+	n := m.Dimensions[0]
+	b, err := NewBand(n, kl, ku)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			v := m.Data[i*n+j]
+			if j-i < -kl || j-i > ku {
+				if v != 0 {
+					return nil, fmt.Errorf("entry (%d, %d) lies outside the band kl=%d ku=%d", i, j, kl, ku)
+				}
+				continue
+			}
+			b.Data[i*(kl+ku+1)+j-i+kl] = v
+		}
+	}
+	return b, nil
+}
+
+// Dims returns the number of rows and columns.
+func (b *Band) Dims() (int, int) { return b.N, b.N }
+
+// inBand reports whether (i, j) is inside the matrix and the band.
+func (b *Band) inBand(i, j int) bool {
+	return i >= 0 && i < b.N && j >= 0 && j < b.N && j-i >= -b.KL && j-i <= b.KU
+}
+
+// At returns the entry at (i, j); entries outside the band are zero.
+func (b *Band) At(i, j int) (float64, error) {
+	if i < 0 || i >= b.N || j < 0 || j >= b.N {
+		return 0, fmt.Errorf("index (%d, %d) out of bounds for %dx%d matrix", i, j, b.N, b.N)
+	}
+	if !b.inBand(i, j) {
+		return 0, nil
+	}
+	return b.Data[i*(b.KL+b.KU+1)+j-i+b.KL], nil
+}
+
+// Set stores v at (i, j). Returns an error if (i, j) is outside the band.
+func (b *Band) Set(i, j int, v float64) error {
+	if !b.inBand(i, j) {
+		return fmt.Errorf("index (%d, %d) outside band kl=%d ku=%d of %dx%d matrix", i, j, b.KL, b.KU, b.N, b.N)
+	}
+	b.Data[i*(b.KL+b.KU+1)+j-i+b.KL] = v
+	return nil
+}
+
+// MulVec returns A·x for a vector of length n.
+func (b *Band) MulVec(x []float64) ([]float64, error) {
+	y := make([]float64, b.N)
+	if err := b.Apply(y, x); err != nil {
+		return nil, err
+	}
+	return y, nil
+}
+
+// Apply writes A·x into dst, making *Band an Operator.
+func (b *Band) Apply(dst, x []float64) error {
+	if err := checkApply(b.N, b.N, dst, x); err != nil {
+		return err
+	}
+	w := b.KL + b.KU + 1
+	for i := 0; i < b.N; i++ {
+		lo, hi := max(0, i-b.KL), min(b.N-1, i+b.KU)
+		row := b.Data[i*w+lo-i+b.KL : i*w+hi-i+b.KL+1]
+		sum := 0.0
+		for k, v := range row {
+			sum += v * x[lo+k]
+		}
+		dst[i] = sum
+	}
+	return nil
+}
+
+// ApplyTranspose writes Aᵀ·x into dst by scattering each row, making *Band a
+// LinearOperator.
+func (b *Band) ApplyTranspose(dst, x []float64) error {
+	if err := checkApply(b.N, b.N, dst, x); err != nil {
+		return err
+	}
+	for j := range dst {
+		dst[j] = 0
+	}
+	w := b.KL + b.KU + 1
+	for i := 0; i < b.N; i++ {
+		lo, hi := max(0, i-b.KL), min(b.N-1, i+b.KU)
+		row := b.Data[i*w+lo-i+b.KL : i*w+hi-i+b.KL+1]
+		for k, v := range row {
+			dst[lo+k] += v * x[i]
+		}
+	}
+	return nil
+}
+
+// ToMatx expands the band matrix to a dense 2D Matx.
+func (b *Band) ToMatx() (*Matx, error) {
+	m, err := Zeros([]int{b.N, b.N})
+	if err != nil {
+		return nil, err
+	}
+	w := b.KL + b.KU + 1
+	for i := 0; i < b.N; i++ {
+		for j := max(0, i-b.KL); j <= min(b.N-1, i+b.KU); j++ {
+			m.Data[i*b.N+j] = b.Data[i*w+j-i+b.KL]
+		}
+	}
+	return m, nil
+}
+
+// BandLUFactor computes the LU factorization with partial pivoting of a band matrix
+// in O(n·KL·(KL+KU)) without modifying it. Returns an error if the matrix is singular.
+func BandLUFactor(b *Band) (result *BandLU, err error) {
+	if b == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	if span := startTraceShapes("BandLU", []int{b.N, b.N}); span != nil {
+		defer func() { span.end(2*int64(b.N)*int64(b.KL)*int64(b.KL+b.KU+1), 8*int64(b.N*(2*b.KL+b.KU+1)), err) }()
+	}
+
+	n, kl, ku := b.N, b.KL, b.KU
+	w := 2*kl + ku + 1
+	lu := &BandLU{N: n, KL: kl, KU: ku, Pivots: make([]int, n), factors: make([]float64, n*w)}
+	for i := 0; i < n; i++ {
+		copy(lu.factors[i*w:i*w+kl+ku+1], b.Data[i*(kl+ku+1):(i+1)*(kl+ku+1)])
+	}
+	f := lu.factors
+	at := func(i, j int) int { return i*w + j - i + kl }
+
+	for k := 0; k < n; k++ {
+		last := min(n-1, k+kl)
+		right := min(n-1, k+kl+ku)
+
+		p := k
+		for i := k + 1; i <= last; i++ {
+			if math.Abs(f[at(i, k)]) > math.Abs(f[at(p, k)]) {
+				p = i
+			}
+		}
+		if f[at(p, k)] == 0 {
+			return nil, fmt.Errorf("%w (column %d)", errSingular, k)
+		}
+		lu.Pivots[k] = p
+		if p != k {
+			lu.Swaps++
+			for j := k; j <= right; j++ {
+				f[at(k, j)], f[at(p, j)] = f[at(p, j)], f[at(k, j)]
+			}
+		}
+
+		pivot := f[at(k, k)]
+		for i := k + 1; i <= last; i++ {
+			l := f[at(i, k)] / pivot
+			f[at(i, k)] = l
+			if l == 0 {
+				continue
+			}
+			for j := k + 1; j <= right; j++ {
+				f[at(i, j)] -= l * f[at(k, j)]
+			}
+		}
+	}
+
+	return lu, nil
+}
+
+// Solve returns x such that A x = b for the factored matrix A.
+// Returns an error if len(b) does not match the matrix order.
+func (lu *BandLU) Solve(b []float64) ([]float64, error) {
+	n, kl, ku := lu.N, lu.KL, lu.KU
+	if len(b) != n {
+		return nil, fmt.Errorf("right-hand side length %d does not match matrix order %d", len(b), n)
+	}
+	w := 2*kl + ku + 1
+	f := lu.factors
+
+	// Forward: apply each swap, then eliminate below the pivot
+	x := append([]float64(nil), b...)
+	for k := 0; k < n; k++ {
+		if p := lu.Pivots[k]; p != k {
+			x[k], x[p] = x[p], x[k]
+		}
+		for i := k + 1; i <= min(n-1, k+kl); i++ {
+			x[i] -= f[i*w+k-i+kl] * x[k]
+		}
+	}
+
+	// Backward substitution with U, which has KL+KU superdiagonals
+	for i := n - 1; i >= 0; i-- {
+		sum := x[i]
+		for j := i + 1; j <= min(n-1, i+kl+ku); j++ {
+			sum -= f[i*w+j-i+kl] * x[j]
+		}
+		x[i] = sum / f[i*w+kl]
+	}
+	return x, nil
+}
+
+// Det returns the determinant of the factored matrix.
+func (lu *BandLU) Det() float64 {
+	w := 2*lu.KL + lu.KU + 1
+	det := 1.0
+	for i := 0; i < lu.N; i++ {
+		det *= lu.factors[i*w+lu.KL]
+	}
+	if lu.Swaps%2 != 0 {
+		det = -det
+	}
+	return det
+}
+
+// BandCholeskyFactor computes the Cholesky factorization of a symmetric positive
+// definite band matrix in O(n·KD²), where KD = KL must equal KU. Only the lower band
+// is read. Returns an error if the matrix is not positive definite.
+func BandCholeskyFactor(b *Band) (result *BandCholesky, err error) {
+	if b == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	if span := startTraceShapes("BandCholesky", []int{b.N, b.N}); span != nil {
+		defer func() { span.end(int64(b.N)*int64(b.KL+1)*int64(b.KL+1), 8*int64(b.N*(b.KL+1)), err) }()
+	}
+
+	if b.KL != b.KU {
+		return nil, fmt.Errorf("band Cholesky requires a symmetric band, got kl=%d ku=%d", b.KL, b.KU)
+	}
+
+	n, kd := b.N, b.KL
+	w := kd + 1
+	l := make([]float64, n*w)
+	for i := 0; i < n; i++ {
+		for j := max(0, i-kd); j <= i; j++ {
+			// L[i,j] = (A[i,j] − Σ_k L[i,k]·L[j,k]) / L[j,j], k from the start of row i's band
+			sum := b.Data[i*(2*kd+1)+j-i+kd]
+			for k := max(0, i-kd); k < j; k++ {
+				sum -= l[i*w+k-i+kd] * l[j*w+k-j+kd]
+			}
+			if i == j {
+				if sum <= 0 || math.IsNaN(sum) {
+					return nil, fmt.Errorf("matrix is not positive definite (pivot %d)", i)
+				}
+				l[i*w+kd] = math.Sqrt(sum)
+			} else {
+				l[i*w+j-i+kd] = sum / l[j*w+kd]
+			}
+		}
+	}
+
+	return &BandCholesky{N: n, KD: kd, l: l}, nil
+}
+
+// Solve returns x such that A x = b for the factored matrix A.
+// Returns an error if len(b) does not match the matrix order.
+func (c *BandCholesky) Solve(b []float64) ([]float64, error) {
+	n, kd := c.N, c.KD
+	if len(b) != n {
+		return nil, fmt.Errorf("right-hand side length %d does not match matrix order %d", len(b), n)
+	}
+	w := kd + 1
+
+	// L·y = b
+	x := make([]float64, n)
+	for i := 0; i < n; i++ {
+		sum := b[i]
+		for k := max(0, i-kd); k < i; k++ {
+			sum -= c.l[i*w+k-i+kd] * x[k]
+		}
+		x[i] = sum / c.l[i*w+kd]
+	}
+
+	// Lᵀ·x = y
+	for i := n - 1; i >= 0; i-- {
+		sum := x[i]
+		for k := i + 1; k <= min(n-1, i+kd); k++ {
+			sum -= c.l[k*w+i-k+kd] * x[k]
+		}
+		x[i] = sum / c.l[i*w+kd]
+	}
+	return x, nil
+}
+
+// Det returns the determinant of the factored matrix.
+func (c *BandCholesky) Det() float64 {
+	det := 1.0
+	for i := 0; i < c.N; i++ {
+		d := c.l[i*(c.KD+1)+c.KD]
+		det *= d * d
+	}
+	return det
+}
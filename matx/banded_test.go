@@ -0,0 +1,153 @@
+package matx
+
+import (
+	"math"
+	"testing"
+)
+
+// randomBand returns an n×n band matrix with random entries, boosted on the diagonal by `shift`.
+func randomBand(n, kl, ku int, shift float64) *Band {
+	b, _ := NewBand(n, kl, ku)
+	r, _ := Rand(n, kl+ku+1, -1, 1)
+	for i := 0; i < n; i++ {
+		for j := max(0, i-kl); j <= min(n-1, i+ku); j++ {
+			v := r.Data[i*(kl+ku+1)+j-i+kl]
+			if i == j {
+				v += shift
+			}
+			b.Set(i, j, v)
+		}
+	}
+	return b
+}
+
+func TestBanded(t *testing.T) {
+	n := 1
+
+	{ // Tridiagonal storage matches the dense matrix
+		m := begin(t, n, "Tridiagonal.MulVec()/At()")
+		n++
+		tri, err := NewTridiagonal([]float64{1, 2}, []float64{4, 5, 6}, []float64{7, 8})
+		dense, _ := tri.ToMatx()
+		y, _ := tri.MulVec([]float64{1, 1, 1})
+		v, _ := tri.At(1, 0)
+		_, errShape := NewTridiagonal([]float64{1}, []float64{4, 5, 6}, []float64{7, 8})
+		m.end(err == nil && errShape != nil && v == 1 &&
+			closeSlices(dense.Data, []float64{4, 7, 0, 1, 5, 8, 0, 2, 6}, 0) &&
+			closeSlices(y, []float64{11, 14, 8}, 0))
+	}
+
+	{ // Thomas algorithm on a second-difference system
+		m := begin(t, n, "Tridiagonal.Solve()")
+		n++
+		size := 200
+		lower, diag, upper := make([]float64, size-1), make([]float64, size), make([]float64, size-1)
+		b := make([]float64, size)
+		for i := range diag {
+			diag[i] = 2
+			b[i] = math.Sin(float64(i))
+			if i < size-1 {
+				lower[i], upper[i] = -1, -1
+			}
+		}
+		tri, _ := NewTridiagonal(lower, diag, upper)
+		x, err := tri.Solve(b)
+		_, errZero := ThomasSolve([]float64{1}, []float64{0, 1}, []float64{1}, []float64{1, 1})
+		m.end(err == nil && errZero != nil && relResidual(tri, b, x) < 1e-12)
+	}
+
+	{ // Band matvec agrees with the dense product
+		m := begin(t, n, "Band.MulVec()")
+		n++
+		band := randomBand(40, 3, 2, 0)
+		dense, _ := band.ToMatx()
+		back, err := BandFromMatx(dense, 3, 2)
+		_, errOut := BandFromMatx(dense, 1, 1)
+		x := make([]float64, 40)
+		for i := range x {
+			x[i] = float64(i%5) - 2
+		}
+		y, _ := band.MulVec(x)
+		op, _ := DenseOperator(dense)
+		want := make([]float64, 40)
+		op.Apply(want, x)
+		m.end(err == nil && errOut != nil && closeSlices(back.Data, band.Data, 0) && closeSlices(y, want, 1e-12))
+	}
+
+	{ // Band LU with pivoting: small diagonal forces row interchanges
+		m := begin(t, n, "BandLUFactor().Solve()")
+		n++
+		band := randomBand(60, 2, 3, 0)
+		lu, err := BandLUFactor(band)
+		b := make([]float64, 60)
+		for i := range b {
+			b[i] = 1 / float64(i+1)
+		}
+		ok := err == nil && lu.Swaps > 0
+		if ok {
+			x, _ := lu.Solve(b)
+			ok = relResidual(band, b, x) < 1e-10
+		}
+		small := randomBand(8, 2, 3, 0)
+		smallLU, _ := BandLUFactor(small)
+		smallDense, _ := small.ToMatx()
+		want, _ := Det(smallDense)
+		m.end(ok && smallLU != nil && math.Abs(smallLU.Det()-want) <= 1e-10*math.Max(1, math.Abs(want)))
+	}
+
+	{ // Band Cholesky on an SPD pentadiagonal matrix
+		m := begin(t, n, "BandCholeskyFactor().Solve()")
+		n++
+		size := 50
+		band, _ := NewBand(size, 2, 2)
+		for i := 0; i < size; i++ {
+			band.Set(i, i, 6)
+			if i+1 < size {
+				band.Set(i, i+1, -4)
+				band.Set(i+1, i, -4)
+			}
+			if i+2 < size {
+				band.Set(i, i+2, 1)
+				band.Set(i+2, i, 1)
+			}
+		}
+		band.Set(0, 0, 7)
+		c, err := BandCholeskyFactor(band)
+		b := make([]float64, size)
+		b[size/2] = 1
+		ok := err == nil
+		if ok {
+			x, _ := c.Solve(b)
+			ok = relResidual(band, b, x) < 1e-9
+		}
+		small, _ := NewBand(4, 1, 1)
+		for i := 0; i < 4; i++ {
+			small.Set(i, i, 4)
+			if i < 3 {
+				small.Set(i, i+1, 1)
+				small.Set(i+1, i, 1)
+			}
+		}
+		sc, _ := BandCholeskyFactor(small)
+		smallDense, _ := small.ToMatx()
+		want, _ := Det(smallDense)
+		indef, _ := NewBand(2, 1, 1)
+		indef.Data = []float64{0, 1, 2, 2, 1, 0}
+		_, errIndef := BandCholeskyFactor(indef)
+		_, errShape := BandCholeskyFactor(randomBand(5, 1, 2, 4))
+		m.end(ok && math.Abs(sc.Det()-want) < 1e-10 && errIndef != nil && errShape != nil)
+	}
+
+	{ // Singular band and wrong right-hand side length
+		m := begin(t, n, "BandLUFactor() errors")
+		n++
+		band, _ := NewBand(3, 1, 1)
+		band.Set(0, 0, 1)
+		band.Set(1, 1, 1)
+		_, err1 := BandLUFactor(band)
+		band.Set(2, 2, 1)
+		lu, _ := BandLUFactor(band)
+		_, err2 := lu.Solve([]float64{1, 2})
+		m.end(err1 != nil && err2 != nil)
+	}
+}
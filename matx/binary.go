@@ -0,0 +1,365 @@
+package matx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+)
+
+// binaryMagic identifies the native serialization written by MarshalBinary, EncodeBinary and Save.
+var binaryMagic = [8]byte{'G', 'O', 'T', 'E', 'N', 'M', 'X', 0}
+
+// binaryVersion is the current format version; newer files are rejected.
+const binaryVersion = 1
+
+// Element types of the native format.
+const (
+	binaryFloat64 = 1
+	binaryFloat32 = 2
+)
+
+// binaryCompressed marks a payload compressed with DEFLATE.
+const binaryCompressed = 1 << 0
+
+// binaryMaxDims is the largest number of dimensions the native format accepts.
+const binaryMaxDims = 64
+
+// BinaryOptions configures EncodeBinary and Save. Zero values write lossless,
+// uncompressed float64 data.
+//   - Float32 stores elements as float32, halving the size at the cost of precision.
+//   - Compress deflates the element payload with compress/flate.
+//   - Level is the flate compression level; 0 selects flate.DefaultCompression, so
+//     flate.NoCompression cannot be chosen. Leave Compress off to store the elements raw.
+type BinaryOptions struct {
+	Float32  bool
+	Compress bool
+	Level    int
+}
+
+// The native format (little endian):
+// - 8 byte magic "GOTENMX\x00", uint32 version
+// - uint8 dtype (1 = float64, 2 = float32), uint8 flags (bit 0: compressed), 2 reserved bytes
+// - uint32 number of dimensions, one uint64 per dimension
+// - the elements in row-major order, raw or as a DEFLATE stream
+// - uint32 CRC-32 (IEEE) of every preceding byte
+
+// MarshalBinary implements encoding.BinaryMarshaler with lossless, uncompressed float64 data.
+func (m *Matx) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := EncodeBinary(&buf, m, BinaryOptions{}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler for any data written by
+// MarshalBinary, EncodeBinary or Save, replacing the contents of `m`.
+func (m *Matx) UnmarshalBinary(data []byte) error {
+	decoded, n, err := decodeBinary(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return err
+	}
+	if n != int64(len(data)) {
+		return fmt.Errorf("binary matrix: %d trailing bytes", int64(len(data))-n)
+	}
+	*m = *decoded
+	return nil
+}
+
+// EncodeBinary writes `m` to `w` in the native format. A 0-d matrix is written with
+// shape [1]; shapes the decoder would reject (more than 64 dimensions, or a dimension
+// above math.MaxInt32) are refused.
+func EncodeBinary(w io.Writer, m *Matx, opts BinaryOptions) error {
+	if m == nil || m.Data == nil || m.Dimensions == nil {
+		return fmt.Errorf("Matrix is nil")
+	}
+	dims := m.Dimensions
+	if len(dims) == 0 {
+		dims = []int{1}
+	}
+	if len(dims) > binaryMaxDims {
+		return fmt.Errorf("binary matrix supports at most %d dimensions, got %d", binaryMaxDims, len(dims))
+	}
+	for i, d := range dims {
+		if d < 0 || d > math.MaxInt32 {
+			return fmt.Errorf("binary matrix dimension %d at axis %d is out of range", d, i)
+		}
+	}
+	if count, ok := shapeSize(dims); !ok || len(m.Data) != count {
+		return fmt.Errorf("data size %d does not match shape %v", len(m.Data), m.Dimensions)
+	}
+
+	crc := crc32.NewIEEE()
+	out := io.MultiWriter(w, crc)
+
+	header := make([]byte, 20+8*len(dims))
+	copy(header, binaryMagic[:])
+	binary.LittleEndian.PutUint32(header[8:], binaryVersion)
+	header[12] = binaryFloat64
+	if opts.Float32 {
+		header[12] = binaryFloat32
+	}
+	if opts.Compress {
+		header[13] = binaryCompressed
+	}
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(dims)))
+	for i, d := range dims {
+		binary.LittleEndian.PutUint64(header[20+8*i:], uint64(d))
+	}
+	if _, err := out.Write(header); err != nil {
+		return fmt.Errorf("failed to write binary matrix header: %w", err)
+	}
+
+	payload := out
+	var fw *flate.Writer
+	if opts.Compress {
+		level := opts.Level
+		if level == 0 {
+			level = flate.DefaultCompression
+		}
+		var err error
+		if fw, err = flate.NewWriter(out, level); err != nil {
+			return fmt.Errorf("invalid compression level: %w", err)
+		}
+		payload = fw
+	}
+
+	// Encode in blocks to bound the temporary buffer
+	size := 8
+	if opts.Float32 {
+		size = 4
+	}
+	block := make([]byte, size*min(len(m.Data), 1<<13))
+	for start := 0; start < len(m.Data); start += 1 << 13 {
+		chunk := m.Data[start:min(start+1<<13, len(m.Data))]
+		for i, v := range chunk {
+			if opts.Float32 {
+				binary.LittleEndian.PutUint32(block[4*i:], math.Float32bits(float32(v)))
+			} else {
+				binary.LittleEndian.PutUint64(block[8*i:], math.Float64bits(v))
+			}
+		}
+		if _, err := payload.Write(block[:size*len(chunk)]); err != nil {
+			return fmt.Errorf("failed to write binary matrix data: %w", err)
+		}
+	}
+	if fw != nil {
+		if err := fw.Close(); err != nil {
+			return fmt.Errorf("failed to compress binary matrix data: %w", err)
+		}
+	}
+
+	var trailer [4]byte
+	binary.LittleEndian.PutUint32(trailer[:], crc.Sum32())
+	if _, err := w.Write(trailer[:]); err != nil {
+		return fmt.Errorf("failed to write binary matrix checksum: %w", err)
+	}
+	return nil
+}
+
+// crcReader hashes and counts every byte read through it. It implements
+// io.ByteReader so that the flate decompressor never reads past the end of its stream.
+type crcReader struct {
+	r   *bufio.Reader
+	crc hash.Hash32
+	n   int64
+}
+
+func (c *crcReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.crc.Write(p[:n])
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *crcReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.crc.Write([]byte{b})
+		c.n++
+	}
+	return b, err
+}
+
+// DecodeBinary reads one matrix in the native format from `r`, verifying the checksum.
+// Input is buffered, so pass a *bufio.Reader to read several matrices back to back
+// from the same stream.
+func DecodeBinary(r io.Reader) (*Matx, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	m, _, err := decodeBinary(br)
+	return m, err
+}
+
+// decodeBinary parses the header, payload and trailer, returning the matrix and
+// the number of bytes consumed.
+func decodeBinary(br *bufio.Reader) (*Matx, int64, error) {
+	cr := &crcReader{r: br, crc: crc32.NewIEEE()}
+	h, err := readBinaryHeader(cr)
+	if err != nil {
+		return nil, 0, err
+	}
+	count := 1
+	for _, d := range h.dims {
+		count *= d
+	}
+
+	payload := h.payload(cr)
+	// Grow the data block by block as the payload arrives, so that dimensions from a
+	// corrupt or hostile header cannot force a huge allocation up front
+	data := make([]float64, 0, min(count, 1<<13))
+	block := make([]byte, h.size*min(count, 1<<13))
+	for start := 0; start < count; start += 1 << 13 {
+		if data, err = h.readElems(payload, data, min(1<<13, count-start), block); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := h.finish(cr, payload); err != nil {
+		return nil, 0, err
+	}
+	m, err := New(data, h.dims)
+	return m, cr.n, err
+}
+
+// binaryHeader is the parsed header of the native format.
+type binaryHeader struct {
+	dims       []int
+	size       int // bytes per element
+	compressed bool
+}
+
+// readBinaryHeader parses and validates the header, checking that the element
+// count and its byte size fit in an int.
+func readBinaryHeader(cr *crcReader) (*binaryHeader, error) {
+	fixed := make([]byte, 20)
+	if _, err := io.ReadFull(cr, fixed); err != nil {
+		return nil, fmt.Errorf("failed to read binary matrix header: %w", err)
+	}
+	if [8]byte(fixed[:8]) != binaryMagic {
+		return nil, fmt.Errorf("not a binary matrix: bad magic %q", fixed[:8])
+	}
+	if v := binary.LittleEndian.Uint32(fixed[8:]); v == 0 || v > binaryVersion {
+		return nil, fmt.Errorf("unsupported binary matrix version %d (newest supported is %d)", v, binaryVersion)
+	}
+	dtype, flags := fixed[12], fixed[13]
+	h := &binaryHeader{compressed: flags&binaryCompressed != 0}
+	switch dtype {
+	case binaryFloat64:
+		h.size = 8
+	case binaryFloat32:
+		h.size = 4
+	default:
+		return nil, fmt.Errorf("unknown binary matrix dtype %d", dtype)
+	}
+	if flags&^binaryCompressed != 0 {
+		return nil, fmt.Errorf("unknown binary matrix flags %#x", flags)
+	}
+
+	ndims := int(binary.LittleEndian.Uint32(fixed[16:]))
+	if ndims == 0 || ndims > binaryMaxDims {
+		return nil, fmt.Errorf("invalid number of dimensions in binary matrix header: %d", ndims)
+	}
+	raw := make([]byte, 8*ndims)
+	if _, err := io.ReadFull(cr, raw); err != nil {
+		return nil, fmt.Errorf("failed to read binary matrix dimensions: %w", err)
+	}
+	h.dims = make([]int, ndims)
+	count := 1
+	for i := range h.dims {
+		d := binary.LittleEndian.Uint64(raw[8*i:])
+		if d > math.MaxInt32 || (d > 0 && uint64(count) > math.MaxInt64/8/d) {
+			return nil, fmt.Errorf("binary matrix dimensions too large: %d at axis %d", d, i)
+		}
+		h.dims[i] = int(d)
+		count *= h.dims[i]
+	}
+	return h, nil
+}
+
+// payload returns the reader of the elements that follow the header.
+func (h *binaryHeader) payload(cr *crcReader) io.Reader {
+	if h.compressed {
+		return flate.NewReader(cr)
+	}
+	return cr
+}
+
+// readElems reads `n` elements from `payload` through `block` and appends them to `data`.
+func (h *binaryHeader) readElems(payload io.Reader, data []float64, n int, block []byte) ([]float64, error) {
+	if _, err := io.ReadFull(payload, block[:h.size*n]); err != nil {
+		return data, fmt.Errorf("failed to read binary matrix data: %w", noEOF(err))
+	}
+	for i := 0; i < n; i++ {
+		if h.size == 4 {
+			data = append(data, float64(math.Float32frombits(binary.LittleEndian.Uint32(block[4*i:]))))
+		} else {
+			data = append(data, math.Float64frombits(binary.LittleEndian.Uint64(block[8*i:])))
+		}
+	}
+	return data, nil
+}
+
+// finish consumes the end of the payload and verifies the trailing checksum.
+func (h *binaryHeader) finish(cr *crcReader, payload io.Reader) error {
+	if h.compressed {
+		// Consume the end of the DEFLATE stream so the checksum covers it
+		fr := payload.(io.ReadCloser)
+		n, err := io.Copy(io.Discard, fr)
+		fr.Close()
+		if err != nil || n != 0 {
+			return fmt.Errorf("corrupt compressed binary matrix data")
+		}
+	}
+
+	want := cr.crc.Sum32()
+	var trailer [4]byte
+	if _, err := io.ReadFull(cr.r, trailer[:]); err != nil {
+		return fmt.Errorf("failed to read binary matrix checksum: %w", err)
+	}
+	cr.n += 4
+	if got := binary.LittleEndian.Uint32(trailer[:]); got != want {
+		return fmt.Errorf("binary matrix checksum mismatch: stored %08x, computed %08x", got, want)
+	}
+	return nil
+}
+
+// Save writes `m` to the file at `path` in the native format, replacing any existing file.
+func Save(path string, m *Matx, opts ...BinaryOptions) error {
+	var o BinaryOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create binary matrix file: %w", err)
+	}
+	bw := bufio.NewWriter(f)
+	if err := EncodeBinary(bw, m, o); err != nil {
+		f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write binary matrix file: %w", err)
+	}
+	return f.Close()
+}
+
+// Load reads a matrix written by Save, MarshalBinary or EncodeBinary from the file at `path`.
+func Load(path string) (*Matx, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open binary matrix file: %w", err)
+	}
+	defer f.Close()
+	return DecodeBinary(bufio.NewReader(f))
+}
@@ -0,0 +1,133 @@
+package matx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBinary(t *testing.T) {
+	n := 1
+
+	a := randomSparse(30, 20, 0.2)
+	a.Data[0] = math.NaN()
+	a.Data[1] = math.Inf(-1)
+	a.Data[2] = 1.0 / 3
+
+	{ // MarshalBinary/UnmarshalBinary are lossless
+		m := begin(t, n, "MarshalBinary()/UnmarshalBinary()")
+		n++
+		var _ encoding.BinaryMarshaler = a
+		var _ encoding.BinaryUnmarshaler = a
+		data, err := a.MarshalBinary()
+		var got Matx
+		err2 := got.UnmarshalBinary(data)
+		ok := err == nil && err2 == nil && CheckDimensionEquality(got.Dimensions, a.Dimensions) && math.IsNaN(got.Data[0])
+		for i := 1; ok && i < len(a.Data); i++ {
+			ok = got.Data[i] == a.Data[i]
+		}
+		errTrailing := got.UnmarshalBinary(append(data, 0))
+
+		// Every shape the encoder accepts decodes again: 0-d is written as [1]
+		scalar, _ := (&Matx{Data: []float64{2.5}, Dimensions: []int{}}).MarshalBinary()
+		var gotScalar Matx
+		errScalar := gotScalar.UnmarshalBinary(scalar)
+		_, errHuge := (&Matx{Data: []float64{}, Dimensions: []int{0, math.MaxInt32 + 1}}).MarshalBinary()
+		_, errDeep := (&Matx{Data: []float64{}, Dimensions: make([]int, 65)}).MarshalBinary()
+		_, errNeg := (&Matx{Data: []float64{}, Dimensions: []int{-1, 0}}).MarshalBinary()
+		m.end(ok && len(data) == 20+16+8*600+4 && errTrailing != nil &&
+			errScalar == nil && CheckDimensionEquality(gotScalar.Dimensions, []int{1}) && gotScalar.Data[0] == 2.5 &&
+			errHuge != nil && errDeep != nil && errNeg != nil)
+	}
+
+	{ // Compression and float32 storage
+		m := begin(t, n, "EncodeBinary() options")
+		n++
+		var raw, packed, single bytes.Buffer
+		err1 := EncodeBinary(&raw, a, BinaryOptions{})
+		err2 := EncodeBinary(&packed, a, BinaryOptions{Compress: true, Level: 9})
+		err3 := EncodeBinary(&single, a, BinaryOptions{Float32: true})
+		rawLen, packedLen, singleLen := raw.Len(), packed.Len(), single.Len()
+		gotPacked, err4 := DecodeBinary(&packed)
+		gotSingle, err5 := DecodeBinary(&single)
+		errLevel := EncodeBinary(&bytes.Buffer{}, a, BinaryOptions{Compress: true, Level: 42})
+		ok := err1 == nil && err2 == nil && err3 == nil && err4 == nil && err5 == nil && errLevel != nil
+		ok = ok && packedLen < rawLen/2 && singleLen == rawLen-4*600
+		for i := 3; ok && i < len(a.Data); i++ {
+			ok = gotPacked.Data[i] == a.Data[i] && gotSingle.Data[i] == float64(float32(a.Data[i]))
+		}
+		m.end(ok && math.IsInf(gotSingle.Data[1], -1))
+	}
+
+	{ // Several matrices back to back in one stream
+		m := begin(t, n, "DecodeBinary() streams")
+		n++
+		b, _ := New([]float64{1, 2, 3}, []int{3})
+		var buf bytes.Buffer
+		EncodeBinary(&buf, a, BinaryOptions{Compress: true})
+		EncodeBinary(&buf, b, BinaryOptions{})
+		EncodeBinary(&buf, a, BinaryOptions{Compress: true, Float32: true})
+		br := bufio.NewReader(&buf)
+		first, err1 := DecodeBinary(br)
+		second, err2 := DecodeBinary(br)
+		third, err3 := DecodeBinary(br)
+		_, errEOF := DecodeBinary(br)
+		m.end(err1 == nil && err2 == nil && err3 == nil && errEOF != nil &&
+			len(first.Data) == 600 && closeSlices(second.Data, b.Data, 0) && len(third.Dimensions) == 2)
+	}
+
+	{ // Corruption, truncation and foreign data are detected
+		m := begin(t, n, "DecodeBinary() integrity checks")
+		n++
+		data, _ := a.MarshalBinary()
+		var packed bytes.Buffer
+		EncodeBinary(&packed, a, BinaryOptions{Compress: true})
+		flip := func(b []byte, i int) []byte {
+			c := append([]byte(nil), b...)
+			c[i] ^= 0x10
+			return c
+		}
+		newer := append([]byte(nil), data...)
+		newer[8] = 2
+		// A bare header claiming far more elements than could ever follow
+		huge := append([]byte("GOTENMX\x00\x01\x00\x00\x00\x01\x00\x00\x00\x02\x00\x00\x00"), make([]byte, 16)...)
+		binary.LittleEndian.PutUint64(huge[20:], 1<<31-1)
+		binary.LittleEndian.PutUint64(huge[28:], 1<<28)
+		var got Matx
+		errs := []error{
+			got.UnmarshalBinary(flip(data, 100)),
+			got.UnmarshalBinary(flip(data, len(data)-1)),
+			got.UnmarshalBinary(flip(packed.Bytes(), packed.Len()/2)),
+			got.UnmarshalBinary(data[:len(data)-10]),
+			got.UnmarshalBinary([]byte("GOTENMM\x00 not this one")),
+			got.UnmarshalBinary(newer),
+			got.UnmarshalBinary(huge),
+		}
+		ok := true
+		for _, err := range errs {
+			ok = ok && err != nil
+		}
+		m.end(ok && strings.Contains(errs[0].Error(), "checksum") && strings.Contains(errs[5].Error(), "version 2"))
+	}
+
+	{ // Save/Load through the file system
+		m := begin(t, n, "Save()/Load()")
+		n++
+		dir := t.TempDir()
+		path := filepath.Join(dir, "a.bin")
+		err1 := Save(path, a)
+		got, err2 := Load(path)
+		cpath := filepath.Join(dir, "a.bin.z")
+		err3 := Save(cpath, a, BinaryOptions{Compress: true})
+		cgot, err4 := Load(cpath)
+		_, errMissing := Load(filepath.Join(dir, "missing"))
+		errNil := Save(path, nil)
+		m.end(err1 == nil && err2 == nil && err3 == nil && err4 == nil && errMissing != nil && errNil != nil &&
+			closeSlices(got.Data[3:], a.Data[3:], 0) && closeSlices(cgot.Data[3:], a.Data[3:], 0))
+	}
+}
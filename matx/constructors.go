@@ -8,7 +8,11 @@ import (
 
 // Ones returns a 2D matrix filled entirely with ones.
 // Only supports 2D shape; returns an error otherwise.
-func Ones(dimensions []int) (*Matx, error) {
+func Ones(dimensions []int) (result *Matx, err error) {
+	if span := startTraceShapes("Ones"); span != nil {
+		defer func() { span.end(0, 8*elementCount(result), err) }()
+	}
+
 	if len(dimensions) != 2 {
 		return nil, fmt.Errorf("ones matrix must be 2D, got %dD", len(dimensions))
 	}
@@ -47,7 +51,11 @@ func Zeros(dimensions []int) (*Matx, error) {
 
 // Identity returns a square identity matrix of shape N×N.
 // Expects exactly two equal dimensions; returns error for invalid or non-square input.
-func Identity(dimensions ...int) (*Matx, error) {
+func Identity(dimensions ...int) (result *Matx, err error) {
+	if span := startTraceShapes("Identity"); span != nil {
+		defer func() { span.end(0, 8*elementCount(result), err) }()
+	}
+
 	if dimensions == nil || len(dimensions) != 2 || dimensions[0] != dimensions[1] {
 		return nil, fmt.Errorf("identity matrix must be square (got: %v)", dimensions)
 	}
@@ -70,7 +78,11 @@ func Identity(dimensions ...int) (*Matx, error) {
 // Rand returns a rows×cols matrix with elements sampled from a uniform distribution.
 // Optional bounds [min, max) can be specified; defaults to [0.0, 1.0].
 // Returns an error if input shape or bounds are invalid.
-func Rand(rows, cols int, bounds ...float64) (*Matx, error) {
+func Rand(rows, cols int, bounds ...float64) (result *Matx, err error) {
+	if span := startTraceShapes("Rand"); span != nil {
+		defer func() { span.end(0, 8*elementCount(result), err) }()
+	}
+
 	if rows <= 0 || cols <= 0 {
 		return nil, fmt.Errorf("invalid matrix size: %dx%d", rows, cols)
 	}
@@ -0,0 +1,28 @@
+package matx
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProgressFunc receives progress updates from long-running ...Ctx operations.
+// `done` counts completed work units (rows, columns or iterations, depending on
+// the operation) out of `total`. It is called from the goroutine running the operation.
+type ProgressFunc func(done, total int)
+
+// checkCtx returns ctx.Err() wrapped with the operation name once `ctx` is done.
+func checkCtx(ctx context.Context, op string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// reportProgress forwards an update to every callback passed to a ...Ctx function.
+func reportProgress(progress []ProgressFunc, done, total int) {
+	for _, p := range progress {
+		if p != nil {
+			p(done, total)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package matx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	n := 1
+
+	{ // Cancelled context stops Multiply
+		m := begin(t, n, "MultiplyCtx() cancelled")
+		n++
+		a, _ := Rand(200, 200)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := MultiplyCtx(ctx, a, a)
+		m.end(errors.Is(err, context.Canceled) && strings.HasPrefix(err.Error(), "Multiply:"))
+	}
+
+	{ // Progress reaches the total
+		m := begin(t, n, "InvertCtx() progress")
+		n++
+		a, _ := Rand(150, 150)
+		last, total, monotonic := 0, 0, true
+		_, err := InvertCtx(context.Background(), a, func(done, tot int) {
+			if done < last {
+				monotonic = false
+			}
+			last, total = done, tot
+		})
+		m.end(err == nil && monotonic && total == 300 && last == total)
+	}
+
+	{ // Cancellation mid-way through Invert
+		m := begin(t, n, "InvertCtx() cancel from callback")
+		n++
+		a, _ := Rand(300, 300)
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := InvertCtx(ctx, a, func(done, total int) {
+			if done >= total/4 {
+				cancel()
+			}
+		})
+		m.end(errors.Is(err, context.Canceled) && strings.HasPrefix(err.Error(), "Invert:"))
+	}
+}
@@ -0,0 +1,249 @@
+package matx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CSVOptions configures ReadCSV and WriteCSV. Zero values select defaults.
+//   - Delimiter separates fields (default ',').
+//   - Comment, when set, marks lines to skip on read.
+//   - Header makes ReadCSV treat the first row as column names.
+//   - Columns selects file columns by 0-based index, in the given order.
+//   - ColumnNames selects file columns by header name; it requires Header.
+//   - Strict makes empty or unparseable cells an error reporting their line and
+//     column; otherwise they read as NaN, as do the cells missing from short rows.
+//   - Names is written by WriteCSV as a header row when non-empty.
+//   - Format is a PrintMatx alias ("int", "float", "f", "short", "sci", "g") or fmt
+//     verb for WriteCSV; empty writes the shortest representation that round-trips.
+type CSVOptions struct {
+	Delimiter   rune
+	Comment     rune
+	Header      bool
+	Columns     []int
+	ColumnNames []string
+	Strict      bool
+	Names       []string
+	Format      string
+}
+
+// csvMissing lists the cell texts read as NaN besides the empty string.
+var csvMissing = map[string]bool{"na": true, "n/a": true, "null": true, "none": true, "-": true}
+
+// ReadCSV reads a rows×cols matrix of numbers from CSV input. When opts.Header is
+// set the first row is returned as the column names, restricted to the selected
+// columns. Rows with more fields than the first row are an error.
+func ReadCSV(r io.Reader, opts CSVOptions) (*Matx, []string, error) {
+	d, err := newCSVDecoder(r, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := []float64{}
+	rows := 0
+	for {
+		if data, err = d.readRow(data); err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rows++
+	}
+	m, err := New(data, []int{rows, len(d.cols)})
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, d.names, nil
+}
+
+// csvDecoder turns CSV records into rows of the selected columns. It is shared by
+// ReadCSV and CSVChunkReader.
+type csvDecoder struct {
+	cr      *csv.Reader
+	opts    CSVOptions
+	width   int      // fields per row, from the first record
+	cols    []int    // selected field indices
+	names   []string // selected header names, when opts.Header is set
+	pending []string // first data record, read while fixing the width
+}
+
+// newCSVDecoder validates `opts` and reads the first record to fix the row width,
+// the column selection and, with opts.Header, the column names.
+func newCSVDecoder(r io.Reader, opts CSVOptions) (*csvDecoder, error) {
+	if len(opts.ColumnNames) > 0 && !opts.Header {
+		return nil, fmt.Errorf("csv: ColumnNames requires Header")
+	}
+	if len(opts.ColumnNames) > 0 && len(opts.Columns) > 0 {
+		return nil, fmt.Errorf("csv: Columns and ColumnNames are mutually exclusive")
+	}
+	cr := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		cr.Comma = opts.Delimiter
+	}
+	cr.Comment = opts.Comment
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	d := &csvDecoder{cr: cr, opts: opts}
+
+	first, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("csv: input is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("csv: %w", err)
+	}
+	d.width = len(first)
+
+	switch {
+	case len(opts.ColumnNames) > 0:
+		index := make(map[string]int, d.width)
+		for i, name := range first {
+			if _, dup := index[name]; !dup {
+				index[name] = i
+			}
+		}
+		for _, name := range opts.ColumnNames {
+			i, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("csv: column %q not found in header", name)
+			}
+			d.cols = append(d.cols, i)
+		}
+	case len(opts.Columns) > 0:
+		for _, i := range opts.Columns {
+			if i < 0 || i >= d.width {
+				return nil, fmt.Errorf("csv: column index %d out of range for %d columns", i, d.width)
+			}
+			d.cols = append(d.cols, i)
+		}
+	default:
+		for i := 0; i < d.width; i++ {
+			d.cols = append(d.cols, i)
+		}
+	}
+
+	if opts.Header {
+		d.names = make([]string, len(d.cols))
+		for k, i := range d.cols {
+			d.names[k] = strings.TrimSpace(first[i])
+		}
+	} else {
+		d.pending = append([]string(nil), first...)
+	}
+	return d, nil
+}
+
+// readRow appends the selected cells of the next record to data, or returns io.EOF.
+func (d *csvDecoder) readRow(data []float64) ([]float64, error) {
+	record := d.pending
+	d.pending = nil
+	if record == nil {
+		var err error
+		if record, err = d.cr.Read(); err == io.EOF {
+			return data, io.EOF
+		} else if err != nil {
+			return data, fmt.Errorf("csv: %w", err)
+		}
+	}
+
+	line, _ := d.cr.FieldPos(0)
+	if len(record) > d.width {
+		return data, fmt.Errorf("csv line %d: %d fields, want %d", line, len(record), d.width)
+	}
+	for _, i := range d.cols {
+		if i >= len(record) {
+			if d.opts.Strict {
+				return data, fmt.Errorf("csv line %d: missing column %d (row has %d fields, want %d)", line, i+1, len(record), d.width)
+			}
+			data = append(data, math.NaN())
+			continue
+		}
+		v, err := parseCSVCell(record[i])
+		if err != nil {
+			if d.opts.Strict {
+				l, _ := d.cr.FieldPos(i)
+				return data, fmt.Errorf("csv line %d, column %d: %w", l, i+1, err)
+			}
+			v = math.NaN()
+		}
+		data = append(data, v)
+	}
+	return data, nil
+}
+
+// parseCSVCell parses one cell, reporting missing values and parse failures as errors.
+func parseCSVCell(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || csvMissing[strings.ToLower(s)] {
+		return 0, fmt.Errorf("missing value %q", s)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) && errors.Is(numErr.Err, strconv.ErrRange) {
+			return v, nil // overflow reads as ±Inf, underflow as 0
+		}
+		return 0, fmt.Errorf("cannot parse %q as a number", s)
+	}
+	return v, nil
+}
+
+// WriteCSV writes a 1D matrix as a single column or a 2D matrix row by row.
+func WriteCSV(w io.Writer, m *Matx, opts CSVOptions) error {
+	if m == nil || m.Data == nil {
+		return fmt.Errorf("Matrix is nil")
+	}
+	if len(m.Dimensions) != 1 && len(m.Dimensions) != 2 {
+		return fmt.Errorf("csv: WriteCSV requires a 1D or 2D matrix, got dimensions %v", m.Dimensions)
+	}
+	rows, cols := m.Dims()
+	if len(opts.Names) > 0 && len(opts.Names) != cols {
+		return fmt.Errorf("csv: %d names for %d columns", len(opts.Names), cols)
+	}
+
+	cw := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		cw.Comma = opts.Delimiter
+	}
+	if len(opts.Names) > 0 {
+		if err := cw.Write(opts.Names); err != nil {
+			return fmt.Errorf("csv: %w", err)
+		}
+	}
+	if err := writeCSVRows(cw, m.Data, rows, cols, csvFormatter(opts)); err != nil {
+		return err
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+	return nil
+}
+
+// csvFormatter returns the cell formatter selected by opts.Format.
+func csvFormatter(opts CSVOptions) func(float64) string {
+	if opts.Format == "" {
+		return func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	}
+	verb := resolveFormat("", opts.Format)
+	return func(v float64) string { return fmt.Sprintf(verb, v) }
+}
+
+// writeCSVRows writes the first rows×cols elements of row-major `data` as records.
+func writeCSVRows(cw *csv.Writer, data []float64, rows, cols int, format func(float64) string) error {
+	record := make([]string, cols)
+	for i := 0; i < rows; i++ {
+		for j := range record {
+			record[j] = format(data[i*cols+j])
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("csv: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package matx
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	n := 1
+
+	{ // Round trip is exact with the default format
+		m := begin(t, n, "WriteCSV()/ReadCSV() round trip")
+		n++
+		a := randomSparse(5, 3, 1)
+		a.Data[0] = 1.0 / 3
+		a.Data[1] = math.Inf(1)
+		var buf bytes.Buffer
+		err := WriteCSV(&buf, a, CSVOptions{Names: []string{"x", "y", "z"}})
+		got, names, err2 := ReadCSV(&buf, CSVOptions{Header: true})
+		vec, _ := New([]float64{1, 2, 3}, []int{3})
+		var vbuf bytes.Buffer
+		err3 := WriteCSV(&vbuf, vec, CSVOptions{})
+		m.end(err == nil && err2 == nil && err3 == nil && denseEqual(got, a, 0) &&
+			strings.Join(names, ",") == "x,y,z" && vbuf.String() == "1\n2\n3\n")
+	}
+
+	{ // Delimiters, comments and column selection
+		m := begin(t, n, "ReadCSV() delimiter and column selection")
+		n++
+		input := "# exported\nid;temp;flag\n1;20.5;0\n2;-3e2;1\n"
+		byName, names, err1 := ReadCSV(strings.NewReader(input), CSVOptions{Delimiter: ';', Comment: '#', Header: true, ColumnNames: []string{"flag", "temp"}})
+		byIndex, _, err2 := ReadCSV(strings.NewReader("1\t2\t3\n4\t5\t6\n"), CSVOptions{Delimiter: '\t', Columns: []int{2, 0}})
+		_, _, errName := ReadCSV(strings.NewReader(input), CSVOptions{Delimiter: ';', Comment: '#', Header: true, ColumnNames: []string{"nope"}})
+		_, _, errIndex := ReadCSV(strings.NewReader("1,2\n"), CSVOptions{Columns: []int{2}})
+		_, _, errNoHeader := ReadCSV(strings.NewReader("1,2\n"), CSVOptions{ColumnNames: []string{"a"}})
+		wantName, _ := New([]float64{0, 20.5, 1, -300}, []int{2, 2})
+		wantIndex, _ := New([]float64{3, 1, 6, 4}, []int{2, 2})
+		m.end(err1 == nil && err2 == nil && errName != nil && errIndex != nil && errNoHeader != nil &&
+			strings.Join(names, ",") == "flag,temp" && denseEqual(byName, wantName, 0) && denseEqual(byIndex, wantIndex, 0))
+	}
+
+	{ // Missing values are NaN unless strict
+		m := begin(t, n, "ReadCSV() missing values and strict mode")
+		n++
+		input := "1,,3\n4,NA,abc\n7,8\n"
+		got, _, err := ReadCSV(strings.NewReader(input), CSVOptions{})
+		ok := err == nil && len(got.Data) == 9 && got.Data[0] == 1 && got.Data[6] == 7 && got.Data[7] == 8
+		for _, i := range []int{1, 4, 5, 8} {
+			ok = ok && math.IsNaN(got.Data[i])
+		}
+		_, _, errStrict := ReadCSV(strings.NewReader("10,20\n300,x\n"), CSVOptions{Strict: true})
+		_, _, errShort := ReadCSV(strings.NewReader("1,2\n3\n"), CSVOptions{Strict: true})
+		_, _, errLong := ReadCSV(strings.NewReader("1,2\n3,4,5\n"), CSVOptions{})
+		_, _, errEmpty := ReadCSV(strings.NewReader(""), CSVOptions{})
+		headerOnly, _, errHeaderOnly := ReadCSV(strings.NewReader("a,b\n"), CSVOptions{Header: true})
+		ok = ok && errStrict != nil && strings.Contains(errStrict.Error(), "line 2, column 2:") &&
+			errShort != nil && strings.Contains(errShort.Error(), "line 2: missing column 2") && errLong != nil && errEmpty != nil && errHeaderOnly == nil && headerOnly.Dimensions[0] == 0
+		m.end(ok)
+	}
+
+	{ // Format aliases shared with PrintMatx
+		m := begin(t, n, "WriteCSV() formats")
+		n++
+		a, _ := New([]float64{1.23456, -2, 1e6, 0.5}, []int{2, 2})
+		out := func(opts CSVOptions) string {
+			var buf bytes.Buffer
+			WriteCSV(&buf, a, opts)
+			return buf.String()
+		}
+		cube, _ := Zeros([]int{2, 2, 2})
+		m.end(out(CSVOptions{Format: "short"}) == "1.23,-2.00\n1000000.00,0.50\n" &&
+			out(CSVOptions{Format: "int", Delimiter: '|'}) == "1|-2\n1000000|0\n" &&
+			out(CSVOptions{Format: "%.1e"}) == "1.2e+00,-2.0e+00\n1.0e+06,5.0e-01\n" &&
+			WriteCSV(&bytes.Buffer{}, cube, CSVOptions{}) != nil &&
+			WriteCSV(&bytes.Buffer{}, a, CSVOptions{Names: []string{"one"}}) != nil)
+	}
+}
@@ -0,0 +1,571 @@
+package matx
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sort"
+)
+
+// Which selects the end of the spectrum computed by Lanczos and Arnoldi.
+type Which int
+
+const (
+	// LargestMagnitude selects the eigenvalues of largest |λ|.
+	LargestMagnitude Which = iota
+	// SmallestMagnitude selects the eigenvalues of smallest |λ|.
+	SmallestMagnitude
+	// LargestAlgebraic selects the largest eigenvalues (largest real part for Arnoldi).
+	LargestAlgebraic
+	// SmallestAlgebraic selects the smallest eigenvalues (smallest real part for Arnoldi).
+	SmallestAlgebraic
+)
+
+// String returns the name of the selection.
+func (w Which) String() string {
+	switch w {
+	case LargestMagnitude:
+		return "LargestMagnitude"
+	case SmallestMagnitude:
+		return "SmallestMagnitude"
+	case LargestAlgebraic:
+		return "LargestAlgebraic"
+	case SmallestAlgebraic:
+		return "SmallestAlgebraic"
+	}
+	return fmt.Sprintf("Which(%d)", int(w))
+}
+
+// EigenOptions configures Lanczos and Arnoldi. Zero values select defaults.
+//   - Which selects the wanted end of the spectrum (default LargestMagnitude).
+//   - Tol is the relative accuracy of each eigenpair: a pair converges once its
+//     residual estimate ‖A·x − λ·x‖ ≤ Tol·|λ| (default 1e-10).
+//   - MaxRestarts caps the number of implicit restarts (default 300).
+//   - NCV is the Krylov subspace size kept between restarts; it must exceed k
+//     (by 2 for Arnoldi) and not exceed n (default min(n, max(2k+1, 20))).
+//   - V0 is an optional starting vector; a fixed pseudo-random vector is used otherwise.
+type EigenOptions struct {
+	Which       Which
+	Tol         float64
+	MaxRestarts int
+	NCV         int
+	V0          []float64
+}
+
+// LanczosResult holds k eigenpairs of a symmetric operator, ordered by Which.
+//   - Vectors is n×k with the unit eigenvector of Values[i] in column i.
+//   - Residuals holds the true residual ‖A·x − λ·x‖ of each returned pair.
+//   - Restarts and MatVecs count implicit restarts and operator applications.
+//   - NConverged is the number of wanted pairs that met the tolerance.
+type LanczosResult struct {
+	Values     []float64
+	Vectors    *Matx
+	Residuals  []float64
+	Restarts   int
+	MatVecs    int
+	NConverged int
+	Converged  bool
+}
+
+// ArnoldiResult holds k eigenpairs of a general operator, ordered by Which.
+// Complex eigenvalues of a real operator appear in adjacent conjugate pairs.
+//   - Vectors[i] is the unit eigenvector of Values[i], scaled so that its largest
+//     component is real and positive.
+//   - Residuals, Restarts, MatVecs and NConverged are as in LanczosResult.
+type ArnoldiResult struct {
+	Values     []complex128
+	Vectors    [][]complex128
+	Residuals  []float64
+	Restarts   int
+	MatVecs    int
+	NConverged int
+	Converged  bool
+}
+
+// Lanczos computes k eigenpairs of a symmetric operator with the implicitly
+// restarted Lanczos method, using only products A·x. `a` may be a dense or sparse
+// matrix operator or a matrix-free OperatorFunc; its symmetry is not checked.
+// On failure the returned result holds the current approximations and err is a
+// *NotConvergedError.
+func Lanczos(a Operator, k int, opts EigenOptions) (*LanczosResult, error) {
+	return LanczosCtx(context.Background(), a, k, opts)
+}
+
+// LanczosCtx is Lanczos with cancellation checked every restart; progress counts restarts out of MaxRestarts.
+func LanczosCtx(ctx context.Context, a Operator, k int, opts EigenOptions, progress ...ProgressFunc) (result *LanczosResult, err error) {
+	if span := startTraceShapes("Lanczos", operatorShape(a)); span != nil {
+		defer func() {
+			matvecs := 0
+			if result != nil {
+				matvecs = result.MatVecs
+			}
+			span.end(eigenFlops(a, matvecs), 0, err)
+		}()
+	}
+
+	s, err := newArnoldi(a, k, opts, true)
+	if err != nil {
+		return nil, err
+	}
+	vals, ys, err := s.run(ctx, "Lanczos", progress)
+	if vals == nil {
+		return nil, err
+	}
+
+	n := s.n
+	result = &LanczosResult{Values: make([]float64, k), Residuals: make([]float64, k),
+		Restarts: s.restarts, NConverged: s.nconv, Converged: s.nconv == k}
+	data := make([]float64, n*k)
+	x, ax := make([]float64, n), make([]float64, n)
+	for c := 0; c < k; c++ {
+		lambda := real(vals[c])
+		clear(x)
+		for i, y := range ys[c] {
+			axpyVec(real(y), s.v[i], x)
+		}
+		scaleVec(x, 1/normVec(x))
+		if err := s.apply(ax, x); err != nil {
+			return nil, err
+		}
+		axpyVec(-lambda, x, ax)
+		result.Values[c] = lambda
+		result.Residuals[c] = normVec(ax)
+		for i, v := range x {
+			data[i*k+c] = v
+		}
+	}
+	result.Vectors, _ = New(data, []int{n, k})
+	result.MatVecs = s.matvecs
+	return result, err
+}
+
+// Arnoldi computes k eigenpairs of a general square operator with the implicitly
+// restarted Arnoldi method, using only products A·x. Unwanted complex Ritz values
+// are filtered with Francis double shifts, so the iteration stays in real arithmetic.
+// On failure the returned result holds the current approximations and err is a
+// *NotConvergedError.
+func Arnoldi(a Operator, k int, opts EigenOptions) (*ArnoldiResult, error) {
+	return ArnoldiCtx(context.Background(), a, k, opts)
+}
+
+// ArnoldiCtx is Arnoldi with cancellation checked every restart; progress counts restarts out of MaxRestarts.
+func ArnoldiCtx(ctx context.Context, a Operator, k int, opts EigenOptions, progress ...ProgressFunc) (result *ArnoldiResult, err error) {
+	if span := startTraceShapes("Arnoldi", operatorShape(a)); span != nil {
+		defer func() {
+			matvecs := 0
+			if result != nil {
+				matvecs = result.MatVecs
+			}
+			span.end(eigenFlops(a, matvecs), 0, err)
+		}()
+	}
+
+	s, err := newArnoldi(a, k, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	vals, ys, err := s.run(ctx, "Arnoldi", progress)
+	if vals == nil {
+		return nil, err
+	}
+
+	n := s.n
+	result = &ArnoldiResult{Values: vals[:k], Vectors: make([][]complex128, k), Residuals: make([]float64, k),
+		Restarts: s.restarts, NConverged: s.nconv, Converged: s.nconv == k}
+	re, im := make([]float64, n), make([]float64, n)
+	are, aim := make([]float64, n), make([]float64, n)
+	for c := 0; c < k; c++ {
+		clear(re)
+		clear(im)
+		for i, y := range ys[c] {
+			axpyVec(real(y), s.v[i], re)
+			axpyVec(imag(y), s.v[i], im)
+		}
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(re[i], im[i])
+		}
+		normalizeComplex(x)
+		for i, v := range x {
+			re[i], im[i] = real(v), imag(v)
+		}
+
+		// A·(re + i·im) − λ·x, with both parts applied separately
+		if err := s.apply(are, re); err != nil {
+			return nil, err
+		}
+		if err := s.apply(aim, im); err != nil {
+			return nil, err
+		}
+		lr, li := real(vals[c]), imag(vals[c])
+		res := 0.0
+		for i := range x {
+			dr := are[i] - (lr*re[i] - li*im[i])
+			di := aim[i] - (lr*im[i] + li*re[i])
+			res += dr*dr + di*di
+		}
+		result.Vectors[c] = x
+		result.Residuals[c] = math.Sqrt(res)
+	}
+	result.MatVecs = s.matvecs
+	return result, err
+}
+
+// arnoldi is an implicitly restarted Arnoldi factorization A·V = V·H + f·e_mᵀ
+// with an orthonormal basis V of m vectors. With sym set, H is kept tridiagonal
+// and the factorization is a Lanczos one.
+type arnoldi struct {
+	a           Operator
+	n, m, k     int
+	sym         bool
+	which       Which
+	tol         float64
+	maxRestarts int
+
+	v, spare [][]float64 // basis vectors, and scratch for the restart update
+	h        [][]float64 // m×m upper Hessenberg projection
+	f        []float64   // residual vector
+	rng      *rand.Rand
+
+	matvecs, restarts, nconv int
+}
+
+// newArnoldi validates the inputs, applies defaults and normalizes the start vector.
+func newArnoldi(a Operator, k int, opts EigenOptions, sym bool) (*arnoldi, error) {
+	if a == nil {
+		return nil, fmt.Errorf("operator is nil")
+	}
+	rows, cols := a.Dims()
+	if rows != cols {
+		return nil, fmt.Errorf("operator must be square, got %dx%d", rows, cols)
+	}
+	n := rows
+	extra := 1
+	if !sym {
+		extra = 2 // room for a complex conjugate pair at the boundary
+	}
+	if k < 1 || k+extra > n {
+		return nil, fmt.Errorf("number of eigenvalues %d must be between 1 and %d for order %d", k, n-extra, n)
+	}
+	if opts.Which < LargestMagnitude || opts.Which > SmallestAlgebraic {
+		return nil, fmt.Errorf("unknown eigenvalue selection %v", opts.Which)
+	}
+
+	m := opts.NCV
+	if m == 0 {
+		m = min(n, max(2*k+1, 20))
+	}
+	if m < k+extra || m > n {
+		return nil, fmt.Errorf("NCV %d must be between %d and %d", m, k+extra, n)
+	}
+	if opts.V0 != nil && len(opts.V0) != n {
+		return nil, fmt.Errorf("starting vector length %d does not match operator order %d", len(opts.V0), n)
+	}
+
+	s := &arnoldi{a: a, n: n, m: m, k: k, sym: sym, which: opts.Which, tol: opts.Tol,
+		maxRestarts: opts.MaxRestarts, rng: rand.New(rand.NewSource(1))}
+	if s.tol <= 0 {
+		s.tol = 1e-10
+	}
+	if s.maxRestarts <= 0 {
+		s.maxRestarts = 300
+	}
+	s.v = make([][]float64, m)
+	s.spare = make([][]float64, m)
+	for i := range s.v {
+		s.v[i] = make([]float64, n)
+		s.spare[i] = make([]float64, n)
+	}
+	s.h = newSquare(m)
+	s.f = make([]float64, n)
+
+	if opts.V0 != nil {
+		copy(s.v[0], opts.V0)
+	} else {
+		for i := range s.v[0] {
+			s.v[0][i] = s.rng.Float64() - 0.5
+		}
+	}
+	norm := normVec(s.v[0])
+	if norm == 0 {
+		return nil, fmt.Errorf("starting vector is zero")
+	}
+	scaleVec(s.v[0], 1/norm)
+	return s, nil
+}
+
+// apply computes dst = A·x and counts the product.
+func (s *arnoldi) apply(dst, x []float64) error {
+	s.matvecs++
+	return s.a.Apply(dst, x)
+}
+
+// orthogonalize removes from w its components along v[0..j-1] with classical
+// Gram–Schmidt, repeated once when cancellation is detected (DGKS), and accumulates
+// the coefficients into c. It returns ‖w‖, or zero after clearing w when w lies in
+// the span of the basis.
+func (s *arnoldi) orthogonalize(w []float64, j int, c []float64) float64 {
+	prev := normVec(w)
+	d := make([]float64, j)
+	for pass := 0; pass < 2; pass++ {
+		for i := 0; i < j; i++ {
+			d[i] = dotVec(s.v[i], w)
+		}
+		for i := 0; i < j; i++ {
+			axpyVec(-d[i], s.v[i], w)
+			if c != nil {
+				c[i] += d[i]
+			}
+		}
+		norm := normVec(w)
+		if norm > 0.717*prev {
+			return norm
+		}
+		prev = norm
+	}
+	clear(w)
+	return 0
+}
+
+// randomBasisVector fills v[j] with a random unit vector orthogonal to v[0..j-1],
+// used to continue after an invariant subspace has been found.
+func (s *arnoldi) randomBasisVector(j int) error {
+	for attempt := 0; attempt < 5; attempt++ {
+		for i := range s.v[j] {
+			s.v[j][i] = s.rng.Float64() - 0.5
+		}
+		if norm := s.orthogonalize(s.v[j], j, nil); norm > 0 {
+			scaleVec(s.v[j], 1/norm)
+			return nil
+		}
+	}
+	return fmt.Errorf("could not extend the Krylov basis beyond %d vectors", j)
+}
+
+// extend grows the factorization from `from` to m basis vectors.
+func (s *arnoldi) extend(from int) error {
+	c := make([]float64, s.m)
+	for j := from; j < s.m; j++ {
+		if j > 0 {
+			beta := normVec(s.f)
+			if beta == 0 {
+				if err := s.randomBasisVector(j); err != nil {
+					return err
+				}
+			} else {
+				copy(s.v[j], s.f)
+				scaleVec(s.v[j], 1/beta)
+			}
+			s.h[j][j-1] = beta
+		}
+
+		if err := s.apply(s.f, s.v[j]); err != nil {
+			return err
+		}
+		clear(c)
+		s.orthogonalize(s.f, j+1, c)
+		if s.sym {
+			s.h[j][j] = c[j]
+			if j > 0 {
+				s.h[j-1][j] = s.h[j][j-1]
+			}
+		} else {
+			for i := 0; i <= j; i++ {
+				s.h[i][j] = c[i]
+			}
+		}
+	}
+	return nil
+}
+
+// ritz returns the Ritz values of H ordered by s.which, and the coordinates in
+// the basis of the Ritz vectors of the first k of them.
+func (s *arnoldi) ritz() ([]complex128, [][]complex128, error) {
+	m := s.m
+	var vals []complex128
+	var vecs [][]float64
+	if s.sym {
+		re, y := symmetricEigen(s.h)
+		vals = make([]complex128, m)
+		for i, v := range re {
+			vals[i] = complex(v, 0)
+		}
+		vecs = y
+	} else {
+		var err error
+		if vals, err = hessenbergEigenvalues(s.h); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	order := make([]int, m)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return ritzBefore(vals[order[a]], vals[order[b]], s.which)
+	})
+	sorted := make([]complex128, m)
+	for i, o := range order {
+		sorted[i] = vals[o]
+	}
+
+	ys := make([][]complex128, s.k)
+	for c := range ys {
+		if s.sym {
+			ys[c] = make([]complex128, m)
+			for i := range ys[c] {
+				ys[c][i] = complex(vecs[i][order[c]], 0)
+			}
+		} else {
+			ys[c] = hessenbergEigenvector(s.h, sorted[c])
+		}
+	}
+	return sorted, ys, nil
+}
+
+// ritzBefore orders Ritz values by the selection, with the positive imaginary part
+// first among equal keys so that conjugate pairs stay adjacent.
+func ritzBefore(a, b complex128, which Which) bool {
+	var ka, kb float64
+	switch which {
+	case LargestMagnitude:
+		ka, kb = -cmplx.Abs(a), -cmplx.Abs(b)
+	case SmallestMagnitude:
+		ka, kb = cmplx.Abs(a), cmplx.Abs(b)
+	case LargestAlgebraic:
+		ka, kb = -real(a), -real(b)
+	case SmallestAlgebraic:
+		ka, kb = real(a), real(b)
+	}
+	if ka != kb {
+		return ka < kb
+	}
+	return imag(a) > imag(b)
+}
+
+// run iterates extend / Ritz extraction / implicit restart until the k wanted Ritz
+// pairs converge or MaxRestarts is reached. It returns the ordered Ritz values and
+// the basis coordinates of the first k Ritz vectors; vals is nil on a hard error.
+func (s *arnoldi) run(ctx context.Context, method string, progress []ProgressFunc) ([]complex128, [][]complex128, error) {
+	m, k := s.m, s.k
+	eps23 := math.Pow(eigenEps, 2.0/3.0)
+	from := 0
+
+	for {
+		if err := checkCtx(ctx, method); err != nil {
+			return nil, nil, err
+		}
+		if err := s.extend(from); err != nil {
+			return nil, nil, err
+		}
+		vals, ys, err := s.ritz()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Residual estimate of a Ritz pair: ‖A·x − θ·x‖ = ‖f‖·|e_mᵀ·y|
+		fnorm := normVec(s.f)
+		s.nconv = 0
+		worst := 0.0
+		for c := 0; c < k; c++ {
+			rel := fnorm * cmplx.Abs(ys[c][m-1]) / max(eps23, cmplx.Abs(vals[c]))
+			worst = max(worst, rel)
+			if rel <= s.tol {
+				s.nconv++
+			}
+		}
+		if s.nconv == k {
+			return vals, ys, nil
+		}
+		if s.restarts == s.maxRestarts {
+			return vals, ys, &NotConvergedError{
+				Method:     method,
+				Iterations: s.restarts,
+				Residual:   worst,
+				Tol:        s.tol,
+				Reason:     fmt.Sprintf("%d of %d eigenpairs converged", s.nconv, k),
+			}
+		}
+
+		// Keep more than k vectors as pairs converge, without splitting a conjugate pair
+		kk := k + min(s.nconv, (m-k)/2)
+		if imag(vals[kk-1]) > 0 && vals[kk] == cmplx.Conj(vals[kk-1]) {
+			if kk+1 < m {
+				kk++
+			} else {
+				kk--
+			}
+		}
+		s.restart(vals[kk:], kk)
+		s.restarts++
+		reportProgress(progress, s.restarts, s.maxRestarts)
+		from = kk
+	}
+}
+
+// restart applies the unwanted Ritz values as exact shifts to H, then compresses
+// the factorization to kk basis vectors: V ← V·Q[:, :kk] and
+// f ← V·Q[:, kk]·H[kk][kk-1] + f·Q[m-1][kk-1].
+func (s *arnoldi) restart(shifts []complex128, kk int) {
+	m, h := s.m, s.h
+	q := identitySquare(m)
+	for _, mu := range shifts {
+		switch {
+		case imag(mu) == 0:
+			qrSingleShift(h, 0, m-1, real(mu), q)
+		case imag(mu) > 0:
+			qrDoubleShift(h, 0, m-1, 2*real(mu), real(mu)*real(mu)+imag(mu)*imag(mu), q)
+		}
+	}
+
+	for j := 0; j <= kk && j < m; j++ {
+		clear(s.spare[j])
+		for i := 0; i < m; i++ {
+			if q[i][j] != 0 {
+				axpyVec(q[i][j], s.v[i], s.spare[j])
+			}
+		}
+	}
+	sigma := q[m-1][kk-1]
+	scaleVec(s.f, sigma)
+	if kk < m {
+		axpyVec(h[kk][kk-1], s.spare[kk], s.f)
+	}
+	for j := 0; j < kk; j++ {
+		s.v[j], s.spare[j] = s.spare[j], s.v[j]
+	}
+
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			if i >= kk || j >= kk || (s.sym && (j > i+1 || i > j+1)) {
+				h[i][j] = 0
+			}
+		}
+	}
+	if s.sym {
+		// Restore exact symmetry lost to rounding in the QR steps
+		for i := 1; i < kk; i++ {
+			h[i-1][i] = h[i][i-1]
+		}
+	}
+}
+
+// eigenFlops roughly estimates eigensolver work as the operator applications,
+// assuming a dense operator, plus the orthogonalization against a 20-vector basis.
+func eigenFlops(a Operator, matvecs int) int64 {
+	if a == nil {
+		return 0
+	}
+	rows, cols := a.Dims()
+	return int64(matvecs) * (2*int64(rows)*int64(cols) + 80*int64(rows))
+}
+
+// scaleVec computes x *= alpha in place.
+func scaleVec(x []float64, alpha float64) {
+	for i := range x {
+		x[i] *= alpha
+	}
+}
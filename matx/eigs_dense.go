@@ -0,0 +1,368 @@
+package matx
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Small dense eigenvalue kernels for the m×m projected matrices of Lanczos and
+// Arnoldi. Matrices are [][]float64 because m is tiny compared with the operator
+// and the QR steps index rows and columns freely.
+
+// eigenEps is the unit roundoff used by the deflation and convergence tests.
+const eigenEps = 0x1p-52
+
+// newSquare returns an m×m zero matrix.
+func newSquare(m int) [][]float64 {
+	a := make([][]float64, m)
+	for i := range a {
+		a[i] = make([]float64, m)
+	}
+	return a
+}
+
+// identitySquare returns the m×m identity.
+func identitySquare(m int) [][]float64 {
+	a := newSquare(m)
+	for i := range a {
+		a[i][i] = 1
+	}
+	return a
+}
+
+// copySquare returns a deep copy of a.
+func copySquare(a [][]float64) [][]float64 {
+	c := make([][]float64, len(a))
+	for i, row := range a {
+		c[i] = append([]float64(nil), row...)
+	}
+	return c
+}
+
+// symmetricEigen diagonalizes a small symmetric matrix with cyclic Jacobi rotations.
+// Returns the eigenvalues and the eigenvectors as the columns of vecs; `a` is not modified.
+func symmetricEigen(a [][]float64) (vals []float64, vecs [][]float64) {
+	m := len(a)
+	a = copySquare(a)
+	vecs = identitySquare(m)
+
+	for sweep := 0; sweep < 100; sweep++ {
+		off, total := 0.0, 0.0
+		for i := 0; i < m; i++ {
+			for j := 0; j < m; j++ {
+				total += a[i][j] * a[i][j]
+				if i != j {
+					off += a[i][j] * a[i][j]
+				}
+			}
+		}
+		if off <= eigenEps*eigenEps*total {
+			break
+		}
+
+		for p := 0; p < m-1; p++ {
+			for q := p + 1; q < m; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				// Rotation that zeroes a[p][q]: t = tan φ, the smaller root of t² + 2θt − 1 = 0
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < m; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p], a[k][q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := 0; k < m; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k], a[q][k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := 0; k < m; k++ {
+					vkp, vkq := vecs[k][p], vecs[k][q]
+					vecs[k][p], vecs[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+
+	vals = make([]float64, m)
+	for i := range vals {
+		vals[i] = a[i][i]
+	}
+	return vals, vecs
+}
+
+// givens returns c, s with [c s; −s c]·[x; y] = [r; 0].
+func givens(x, y float64) (float64, float64) {
+	r := math.Hypot(x, y)
+	if r == 0 {
+		return 1, 0
+	}
+	return x / r, y / r
+}
+
+// rotateRows applies [c s; −s c] to rows i and i+1 of h over columns lo..len-1.
+func rotateRows(h [][]float64, i, lo int, c, s float64) {
+	for j := lo; j < len(h[i]); j++ {
+		a, b := h[i][j], h[i+1][j]
+		h[i][j], h[i+1][j] = c*a+s*b, -s*a+c*b
+	}
+}
+
+// rotateCols applies the transposed rotation to columns j and j+1 of rows 0..hi.
+func rotateCols(h [][]float64, j, hi int, c, s float64) {
+	for i := 0; i <= hi; i++ {
+		a, b := h[i][j], h[i][j+1]
+		h[i][j], h[i][j+1] = c*a+s*b, -s*a+c*b
+	}
+}
+
+// qrSingleShift applies one implicit single-shift QR step with shift mu to the active
+// window lo..hi of the upper Hessenberg matrix h, as a bulge chase of Givens rotations.
+// The similarity is applied to the whole matrix and accumulated into q when non-nil.
+func qrSingleShift(h [][]float64, lo, hi int, mu float64, q [][]float64) {
+	x, y := h[lo][lo]-mu, h[lo+1][lo]
+	for k := lo; k < hi; k++ {
+		c, s := givens(x, y)
+		rotateRows(h, k, max(lo, k-1), c, s)
+		rotateCols(h, k, min(k+2, hi), c, s)
+		if q != nil {
+			rotateCols(q, k, len(q)-1, c, s)
+		}
+		if k < hi-1 {
+			x, y = h[k+1][k], h[k+2][k]
+		}
+	}
+}
+
+// qrDoubleShift applies one implicit Francis double-shift QR step to the window lo..hi
+// (at least 3×3) of the upper Hessenberg matrix h. The shifts are the roots of
+// x² − s·x + t, so a complex conjugate pair stays in real arithmetic.
+// The similarity is applied to the whole matrix and accumulated into q when non-nil.
+func qrDoubleShift(h [][]float64, lo, hi int, s, t float64, q [][]float64) {
+	m := len(h)
+	x := h[lo][lo]*h[lo][lo] + h[lo][lo+1]*h[lo+1][lo] - s*h[lo][lo] + t
+	y := h[lo+1][lo] * (h[lo][lo] + h[lo+1][lo+1] - s)
+	z := h[lo+1][lo] * h[lo+2][lo+1]
+
+	for k := lo; k <= hi-2; k++ {
+		// Householder reflector P = I − β·v·vᵀ mapping (x, y, z) onto the first axis
+		alpha := math.Sqrt(x*x + y*y + z*z)
+		if x > 0 {
+			alpha = -alpha
+		}
+		v := [3]float64{x - alpha, y, z}
+		vv := v[0]*v[0] + v[1]*v[1] + v[2]*v[2]
+		if vv != 0 {
+			beta := 2 / vv
+			for j := max(lo, k-1); j < m; j++ {
+				d := beta * (v[0]*h[k][j] + v[1]*h[k+1][j] + v[2]*h[k+2][j])
+				h[k][j] -= d * v[0]
+				h[k+1][j] -= d * v[1]
+				h[k+2][j] -= d * v[2]
+			}
+			for i := 0; i <= min(k+3, hi); i++ {
+				d := beta * (h[i][k]*v[0] + h[i][k+1]*v[1] + h[i][k+2]*v[2])
+				h[i][k] -= d * v[0]
+				h[i][k+1] -= d * v[1]
+				h[i][k+2] -= d * v[2]
+			}
+			if q != nil {
+				for i := range q {
+					d := beta * (q[i][k]*v[0] + q[i][k+1]*v[1] + q[i][k+2]*v[2])
+					q[i][k] -= d * v[0]
+					q[i][k+1] -= d * v[1]
+					q[i][k+2] -= d * v[2]
+				}
+			}
+		}
+
+		x, y = h[k+1][k], h[k+2][k]
+		if k < hi-2 {
+			z = h[k+3][k]
+		}
+	}
+
+	// The bulge leaves through a final 2×2 rotation
+	c, sn := givens(x, y)
+	rotateRows(h, hi-1, max(lo, hi-2), c, sn)
+	rotateCols(h, hi-1, hi, c, sn)
+	if q != nil {
+		rotateCols(q, hi-1, len(q)-1, c, sn)
+	}
+}
+
+// eigen2x2 returns the eigenvalues of [a b; c d].
+func eigen2x2(a, b, c, d float64) (complex128, complex128) {
+	half := (a + d) / 2
+	det := a*d - b*c
+	disc := half*half - det
+	if disc >= 0 {
+		r := math.Sqrt(disc)
+		// Larger root by addition, the other from the product to avoid cancellation
+		big := half + math.Copysign(r, half)
+		small := 0.0
+		if big != 0 {
+			small = det / big
+		}
+		return complex(big, 0), complex(small, 0)
+	}
+	r := math.Sqrt(-disc)
+	return complex(half, r), complex(half, -r)
+}
+
+// hessenbergEigenvalues returns the eigenvalues of a small upper Hessenberg matrix
+// with the Francis double-shift QR algorithm; `h` is not modified. Complex
+// eigenvalues come in adjacent conjugate pairs, positive imaginary part first.
+func hessenbergEigenvalues(h [][]float64) ([]complex128, error) {
+	m := len(h)
+	h = copySquare(h)
+	vals := make([]complex128, m)
+
+	norm := 0.0
+	for i := range h {
+		for _, v := range h[i] {
+			norm = max(norm, math.Abs(v))
+		}
+	}
+
+	hi, iter := m-1, 0
+	for hi >= 0 {
+		// Smallest l such that h[l..hi] is an unreduced block
+		l := hi
+		for ; l > 0; l-- {
+			scale := math.Abs(h[l-1][l-1]) + math.Abs(h[l][l])
+			if scale == 0 {
+				scale = norm
+			}
+			if math.Abs(h[l][l-1]) <= eigenEps*scale {
+				h[l][l-1] = 0
+				break
+			}
+		}
+
+		switch l {
+		case hi:
+			vals[hi] = complex(h[hi][hi], 0)
+			hi--
+			iter = 0
+			continue
+		case hi - 1:
+			vals[hi-1], vals[hi] = eigen2x2(h[hi-1][hi-1], h[hi-1][hi], h[hi][hi-1], h[hi][hi])
+			hi -= 2
+			iter = 0
+			continue
+		}
+
+		iter++
+		if iter > 30*m {
+			return nil, fmt.Errorf("QR iteration did not converge for eigenvalue %d", hi)
+		}
+		s := h[hi-1][hi-1] + h[hi][hi]
+		t := h[hi-1][hi-1]*h[hi][hi] - h[hi-1][hi]*h[hi][hi-1]
+		if iter%10 == 0 {
+			// Exceptional shift to break a cycle
+			w := math.Abs(h[hi][hi-1]) + math.Abs(h[hi-1][hi-2])
+			s, t = 1.5*w, w*w
+		}
+		qrDoubleShift(h, l, hi, s, t, nil)
+	}
+
+	// Order each conjugate pair with the positive imaginary part first
+	for i := 0; i+1 < m; i++ {
+		if imag(vals[i]) < 0 && vals[i+1] == cmplx.Conj(vals[i]) {
+			vals[i], vals[i+1] = vals[i+1], vals[i]
+		}
+	}
+	return vals, nil
+}
+
+// hessenbergEigenvector returns a unit eigenvector of h for the eigenvalue lambda by
+// two steps of inverse iteration in complex arithmetic.
+func hessenbergEigenvector(h [][]float64, lambda complex128) []complex128 {
+	m := len(h)
+	norm := 0.0
+	for i := range h {
+		for _, v := range h[i] {
+			norm = max(norm, math.Abs(v))
+		}
+	}
+	tiny := eigenEps * max(norm, 1)
+
+	// LU with partial pivoting of H − λI
+	a := make([][]complex128, m)
+	for i := range a {
+		a[i] = make([]complex128, m)
+		for j, v := range h[i] {
+			a[i][j] = complex(v, 0)
+		}
+		a[i][i] -= lambda
+	}
+	perm := make([]int, m)
+	for k := 0; k < m; k++ {
+		p := k
+		for i := k + 1; i < m; i++ {
+			if cmplx.Abs(a[i][k]) > cmplx.Abs(a[p][k]) {
+				p = i
+			}
+		}
+		a[k], a[p] = a[p], a[k]
+		perm[k] = p
+		if cmplx.Abs(a[k][k]) < tiny {
+			a[k][k] = complex(tiny, 0)
+		}
+		for i := k + 1; i < m; i++ {
+			l := a[i][k] / a[k][k]
+			a[i][k] = l
+			for j := k + 1; j < m; j++ {
+				a[i][j] -= l * a[k][j]
+			}
+		}
+	}
+
+	x := make([]complex128, m)
+	for i := range x {
+		x[i] = 1
+	}
+	for step := 0; step < 2; step++ {
+		for k, p := range perm {
+			x[k], x[p] = x[p], x[k]
+		}
+		for i := 0; i < m; i++ {
+			for j := 0; j < i; j++ {
+				x[i] -= a[i][j] * x[j]
+			}
+		}
+		for i := m - 1; i >= 0; i-- {
+			for j := i + 1; j < m; j++ {
+				x[i] -= a[i][j] * x[j]
+			}
+			x[i] /= a[i][i]
+		}
+		normalizeComplex(x)
+	}
+	return x
+}
+
+// normalizeComplex scales x to unit 2-norm with its largest component real and positive.
+func normalizeComplex(x []complex128) {
+	norm, big := 0.0, complex(0, 0)
+	for _, v := range x {
+		norm += real(v)*real(v) + imag(v)*imag(v)
+		if cmplx.Abs(v) > cmplx.Abs(big) {
+			big = v
+		}
+	}
+	if norm == 0 {
+		return
+	}
+	scale := complex(1/math.Sqrt(norm), 0) * cmplx.Conj(big) / complex(cmplx.Abs(big), 0)
+	for i := range x {
+		x[i] *= scale
+	}
+}
@@ -0,0 +1,172 @@
+package matx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/cmplx"
+	"sort"
+	"testing"
+)
+
+// pathLaplacian returns the graph Laplacian of a path with n vertices as a matrix-free
+// operator; its eigenvalues are 2 − 2·cos(pπ/n), p = 0..n-1.
+func pathLaplacian(n int) Operator {
+	return OperatorFunc(n, n, func(dst, x []float64) {
+		for i := range dst {
+			deg, sum := 0.0, 0.0
+			if i > 0 {
+				deg++
+				sum += x[i-1]
+			}
+			if i < n-1 {
+				deg++
+				sum += x[i+1]
+			}
+			dst[i] = deg*x[i] - sum
+		}
+	}, nil)
+}
+
+// quasiTriangular returns an n×n upper quasi-triangular matrix whose eigenvalues are
+// the given real values followed by the complex pairs a ± ib, one 2×2 block each.
+func quasiTriangular(reals []float64, pairs [][2]float64) (*Matx, []complex128) {
+	n := len(reals) + 2*len(pairs)
+	m, _ := Zeros([]int{n, n})
+	var want []complex128
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			m.Data[i*n+j] = 0.3 * math.Sin(float64(7*i+3*j))
+		}
+	}
+	for i, v := range reals {
+		m.Data[i*n+i] = v
+		want = append(want, complex(v, 0))
+	}
+	for p, ab := range pairs {
+		i := len(reals) + 2*p
+		m.Data[i*n+i], m.Data[i*n+i+1] = ab[0], -ab[1]
+		m.Data[(i+1)*n+i], m.Data[(i+1)*n+i+1] = ab[1], ab[0]
+		want = append(want, complex(ab[0], ab[1]), complex(ab[0], -ab[1]))
+	}
+	return m, want
+}
+
+// orthonormalColumns reports whether the columns of v are orthonormal within tol.
+func orthonormalColumns(v *Matx, tol float64) bool {
+	vt, _ := Transpose(v)
+	g, _ := Multiply(vt, v)
+	k := v.Dimensions[1]
+	id, _ := Identity(k, k)
+	return closeSlices(g.Data, id.Data, tol)
+}
+
+func TestEigs(t *testing.T) {
+	n := 1
+
+	{ // Largest eigenvalues of the 1D Laplacian match the analytic spectrum
+		m := begin(t, n, "Lanczos() largest")
+		n++
+		size := 100
+		lower, diag, upper := make([]float64, size-1), make([]float64, size), make([]float64, size-1)
+		for i := range diag {
+			diag[i] = 2
+		}
+		for i := range lower {
+			lower[i], upper[i] = -1, -1
+		}
+		tri, _ := NewTridiagonal(lower, diag, upper)
+		res, err := Lanczos(tri, 4, EigenOptions{Which: LargestAlgebraic})
+		ok := err == nil && res.Converged && res.NConverged == 4
+		for p := 0; ok && p < 4; p++ {
+			want := 2 - 2*math.Cos(float64(size-p)*math.Pi/float64(size+1))
+			ok = math.Abs(res.Values[p]-want) < 1e-9 && res.Residuals[p] < 1e-8
+		}
+		m.end(ok && orthonormalColumns(res.Vectors, 1e-8))
+	}
+
+	{ // Smallest eigenpairs of a matrix-free graph Laplacian, including the zero mode
+		m := begin(t, n, "Lanczos() smallest, matrix-free")
+		n++
+		size := 60
+		res, err := Lanczos(pathLaplacian(size), 3, EigenOptions{Which: SmallestAlgebraic, NCV: 30})
+		ok := err == nil && res.Converged && math.Abs(res.Values[0]) < 1e-10
+		for p := 1; ok && p < 3; p++ {
+			want := 2 - 2*math.Cos(float64(p)*math.Pi/float64(size))
+			ok = math.Abs(res.Values[p]-want) < 1e-9
+		}
+		// The null vector is constant
+		c0, _ := res.Vectors.At(0, 0)
+		for i := 1; ok && i < size; i++ {
+			ci, _ := res.Vectors.At(i, 0)
+			ok = math.Abs(ci-c0) < 1e-7
+		}
+		m.end(ok && res.MatVecs > 0)
+	}
+
+	{ // Nonsymmetric operator with complex pairs; non-normality limits eigenvalue accuracy
+		m := begin(t, n, "Arnoldi() largest magnitude")
+		n++
+		reals := make([]float64, 40)
+		for i := range reals {
+			reals[i] = 0.1 + 0.02*float64(i)
+		}
+		a, all := quasiTriangular(reals, [][2]float64{{1, 2}, {-3, 0.5}, {0.5, 0.4}})
+		op, _ := DenseOperator(a)
+		res, err := Arnoldi(op, 5, EigenOptions{})
+		sort.SliceStable(all, func(i, j int) bool { return ritzBefore(all[i], all[j], LargestMagnitude) })
+		ok := err == nil && res.Converged && len(res.Values) == 5 && len(res.Vectors[0]) == len(all)
+		for i := 0; ok && i < 5; i++ {
+			ok = cmplx.Abs(res.Values[i]-all[i]) < 1e-7 && res.Residuals[i] < 1e-8
+		}
+		m.end(ok && imag(res.Values[0]) > 0 && res.Values[1] == cmplx.Conj(res.Values[0]))
+	}
+
+	{ // Every selection agrees with the known spectrum of a sparse operator
+		m := begin(t, n, "Arnoldi() selections")
+		n++
+		reals := []float64{-5, -4, 0.01, 0.5, 1, 2, 3, 4, 6, 7, 8, 9, -0.02, 0.3, 1.5, 2.5}
+		a, all := quasiTriangular(reals, [][2]float64{{-6, 1}})
+		csr, _ := CSRFromMatx(a)
+		ok := true
+		for _, which := range []Which{LargestMagnitude, SmallestMagnitude, LargestAlgebraic, SmallestAlgebraic} {
+			res, err := Arnoldi(csr, 3, EigenOptions{Which: which, NCV: 12})
+			want := append([]complex128(nil), all...)
+			sort.SliceStable(want, func(i, j int) bool { return ritzBefore(want[i], want[j], which) })
+			ok = ok && err == nil && res.Converged
+			for i := 0; ok && i < 3; i++ {
+				ok = cmplx.Abs(res.Values[i]-want[i]) < 1e-8
+			}
+		}
+		m.end(ok && SmallestAlgebraic.String() == "SmallestAlgebraic")
+	}
+
+	{ // Diagnostics when the restart budget runs out
+		m := begin(t, n, "Lanczos()/Arnoldi() not converged")
+		n++
+		res, err := Lanczos(pathLaplacian(400), 6, EigenOptions{Which: SmallestAlgebraic, MaxRestarts: 2, NCV: 13})
+		var nc *NotConvergedError
+		ok := errors.As(err, &nc) && nc.Method == "Lanczos" && nc.Iterations == 2 && nc.Residual > nc.Tol
+		ok = ok && res != nil && !res.Converged && res.Restarts == 2 && len(res.Values) == 6
+		ares, aerr := Arnoldi(pathLaplacian(400), 6, EigenOptions{Which: SmallestAlgebraic, MaxRestarts: 1, NCV: 14})
+		ok = ok && errors.As(aerr, &nc) && nc.Method == "Arnoldi" && ares != nil && !ares.Converged
+		m.end(ok)
+	}
+
+	{ // Invalid inputs and cancellation
+		m := begin(t, n, "Lanczos()/Arnoldi() errors")
+		n++
+		op := pathLaplacian(10)
+		_, err1 := Lanczos(op, 10, EigenOptions{})
+		_, err2 := Arnoldi(op, 9, EigenOptions{})
+		_, err3 := Lanczos(op, 2, EigenOptions{NCV: 2})
+		_, err4 := Lanczos(op, 2, EigenOptions{V0: make([]float64, 10)})
+		_, err5 := Arnoldi(OperatorFunc(3, 4, func(dst, x []float64) {}, nil), 1, EigenOptions{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, errCtx := LanczosCtx(ctx, op, 2, EigenOptions{})
+		small, err6 := Lanczos(op, 9, EigenOptions{})
+		m.end(err1 != nil && err2 != nil && err3 != nil && err4 != nil && err5 != nil &&
+			errors.Is(errCtx, context.Canceled) && err6 == nil && small.Converged)
+	}
+}
@@ -0,0 +1,241 @@
+package matx
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // registers GIF with image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImageMode selects the channel layout of an image as a matrix.
+type ImageMode int
+
+const (
+	ImageAuto ImageMode = iota // Gray for grayscale images, RGB for opaque ones, RGBA otherwise
+	ImageGray                  // HxW luminance
+	ImageRGB                   // HxWx3, alpha dropped
+	ImageRGBA                  // HxWx4, non-premultiplied alpha
+)
+
+// ImageOptions configures conversions between images and matrices. Zero values select defaults.
+//   - Mode is the channel layout FromImage produces (default ImageAuto). Encoders infer
+//     the layout from the shape instead.
+//   - Normalize maps pixel values to 0–1 instead of 0–255.
+//   - Quality is the JPEG quality from 1 to 100 (default 90).
+type ImageOptions struct {
+	Mode      ImageMode
+	Normalize bool
+	Quality   int
+}
+
+// FromImage converts `img` to an HxW (gray) or HxWxC (RGB, RGBA) matrix. Values are
+// 0–255, or 0–1 with Normalize; 16-bit images keep their precision as fractional values.
+func FromImage(img image.Image, opts ImageOptions) (*Matx, error) {
+	if img == nil {
+		return nil, fmt.Errorf("image is nil")
+	}
+	mode := opts.Mode
+	if mode == ImageAuto {
+		switch img.(type) {
+		case *image.Gray, *image.Gray16:
+			mode = ImageGray
+		default:
+			mode = ImageRGBA
+			if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+				mode = ImageRGB
+			}
+		}
+	}
+	channels := map[ImageMode]int{ImageGray: 1, ImageRGB: 3, ImageRGBA: 4}[mode]
+	if channels == 0 {
+		return nil, fmt.Errorf("unsupported image mode %d", mode)
+	}
+
+	// Colors are read at 16 bits, so 8-bit values map back exactly
+	scale := 255.0 / 0xffff
+	if opts.Normalize {
+		scale = 1.0 / 0xffff
+	}
+	b := img.Bounds()
+	h, w := b.Dy(), b.Dx()
+	data := make([]float64, 0, h*w*channels)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.At(x, y)
+			if mode == ImageGray {
+				g := color.Gray16Model.Convert(c).(color.Gray16)
+				data = append(data, float64(g.Y)*scale)
+				continue
+			}
+			n := toNRGBA64(c)
+			data = append(data, float64(n.R)*scale, float64(n.G)*scale, float64(n.B)*scale)
+			if mode == ImageRGBA {
+				data = append(data, float64(n.A)*scale)
+			}
+		}
+	}
+	dims := []int{h, w, channels}
+	if mode == ImageGray {
+		dims = dims[:2]
+	}
+	return New(data, dims)
+}
+
+// toNRGBA64 returns `c` with straight alpha. Non-premultiplied colors are widened
+// directly, since color.NRGBA64Model goes through premultiplied values and would
+// round translucent pixels.
+func toNRGBA64(c color.Color) color.NRGBA64 {
+	switch c := c.(type) {
+	case color.NRGBA:
+		return color.NRGBA64{R: uint16(c.R) * 0x101, G: uint16(c.G) * 0x101, B: uint16(c.B) * 0x101, A: uint16(c.A) * 0x101}
+	case color.NRGBA64:
+		return c
+	}
+	return color.NRGBA64Model.Convert(c).(color.NRGBA64)
+}
+
+// ToImage converts `m` to an 8-bit image: HxW and HxWx1 give *image.Gray, HxWx3 an
+// opaque *image.RGBA and HxWx4 an *image.NRGBA. Values are read as 0–255, or 0–1 with
+// Normalize, then clipped and rounded; NaN becomes 0.
+func ToImage(m *Matx, opts ImageOptions) (image.Image, error) {
+	if m == nil || m.Data == nil || m.Dimensions == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	dims := m.Dimensions
+	channels := 1
+	switch {
+	case len(dims) == 3 && (dims[2] == 1 || dims[2] == 3 || dims[2] == 4):
+		channels = dims[2]
+	case len(dims) != 2:
+		return nil, fmt.Errorf("image shape must be HxW or HxWxC with 1, 3 or 4 channels, got %v", dims)
+	}
+	h, w := dims[0], dims[1]
+	if h*w*channels != len(m.Data) {
+		return nil, fmt.Errorf("data size %d does not match shape %v", len(m.Data), dims)
+	}
+
+	scale := 1.0
+	if opts.Normalize {
+		scale = 255
+	}
+	quantize := func(v float64) uint8 {
+		if math.IsNaN(v) {
+			return 0
+		}
+		return uint8(math.Round(math.Max(0, math.Min(255, v*scale))))
+	}
+	rect := image.Rect(0, 0, w, h)
+	switch channels {
+	case 1:
+		img := image.NewGray(rect)
+		for i, v := range m.Data {
+			img.Pix[i] = quantize(v)
+		}
+		return img, nil
+	case 3:
+		img := image.NewRGBA(rect)
+		for i := 0; i < h*w; i++ {
+			px := img.Pix[i*4 : i*4+4]
+			px[0], px[1], px[2], px[3] = quantize(m.Data[i*3]), quantize(m.Data[i*3+1]), quantize(m.Data[i*3+2]), 0xff
+		}
+		return img, nil
+	default:
+		img := image.NewNRGBA(rect)
+		for i, v := range m.Data {
+			img.Pix[i] = quantize(v)
+		}
+		return img, nil
+	}
+}
+
+// DecodeImage decodes a PNG, JPEG or GIF image from `r` with FromImage. It also returns
+// the format name reported by image.Decode.
+func DecodeImage(r io.Reader, opts ImageOptions) (*Matx, string, error) {
+	img, format, err := image.Decode(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+	m, err := FromImage(img, opts)
+	return m, format, err
+}
+
+// EncodePNG writes `m` to `w` as an 8-bit PNG, converted with ToImage.
+func EncodePNG(w io.Writer, m *Matx, opts ImageOptions) error {
+	img, err := ToImage(m, opts)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("failed to encode png: %w", err)
+	}
+	return nil
+}
+
+// EncodeJPEG writes `m` to `w` as a JPEG, converted with ToImage. JPEG has no alpha
+// channel, so RGBA matrices are written as their color channels.
+func EncodeJPEG(w io.Writer, m *Matx, opts ImageOptions) error {
+	img, err := ToImage(m, opts)
+	if err != nil {
+		return err
+	}
+	if n, ok := img.(*image.NRGBA); ok {
+		rgb := image.NewRGBA(n.Rect)
+		for i := 0; i < len(n.Pix); i += 4 {
+			copy(rgb.Pix[i:i+3], n.Pix[i:i+3])
+			rgb.Pix[i+3] = 0xff
+		}
+		img = rgb
+	}
+	quality := opts.Quality
+	if quality == 0 {
+		quality = 90
+	}
+	if quality < 1 || quality > 100 {
+		return fmt.Errorf("jpeg quality must be between 1 and 100, got %d", quality)
+	}
+	if err := jpeg.Encode(w, img, &jpeg.Options{Quality: quality}); err != nil {
+		return fmt.Errorf("failed to encode jpeg: %w", err)
+	}
+	return nil
+}
+
+// ReadImageFile opens `path` and decodes it with DecodeImage.
+func ReadImageFile(path string, opts ImageOptions) (*Matx, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image file: %w", err)
+	}
+	defer f.Close()
+	m, _, err := DecodeImage(f, opts)
+	return m, err
+}
+
+// WriteImageFile creates (or truncates) `path` and writes `m` to it as a PNG or JPEG,
+// chosen by the ".png", ".jpg" or ".jpeg" extension.
+func WriteImageFile(path string, m *Matx, opts ImageOptions) error {
+	var encode func(io.Writer, *Matx, ImageOptions) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		encode = EncodePNG
+	case ".jpg", ".jpeg":
+		encode = EncodeJPEG
+	default:
+		return fmt.Errorf("unsupported image extension %q", filepath.Ext(path))
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create image file: %w", err)
+	}
+	if err := encode(f, m, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,110 @@
+package matx
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestImage(t *testing.T) {
+	n := 1
+
+	{ // Channel layouts, normalization and 16-bit precision
+		m := begin(t, n, "FromImage()")
+		n++
+		gray := image.NewGray(image.Rect(0, 0, 3, 2))
+		copy(gray.Pix, []uint8{0, 51, 255, 10, 20, 30})
+		g, err := FromImage(gray, ImageOptions{})
+		gn, err2 := FromImage(gray, ImageOptions{Normalize: true})
+		nrgba := image.NewNRGBA(image.Rect(1, 1, 3, 2))
+		copy(nrgba.Pix, []uint8{10, 20, 30, 255, 40, 50, 60, 128})
+		rgba, err3 := FromImage(nrgba, ImageOptions{})
+		rgb, err4 := FromImage(nrgba, ImageOptions{Mode: ImageRGB})
+		deep := image.NewGray16(image.Rect(0, 0, 1, 1))
+		deep.SetGray16(0, 0, color.Gray16{Y: 0x8000})
+		d, err5 := FromImage(deep, ImageOptions{})
+		m.end(err == nil && err2 == nil && err3 == nil && err4 == nil && err5 == nil &&
+			CheckDimensionEquality(g.Dimensions, []int{2, 3}) && closeSlices(g.Data, []float64{0, 51, 255, 10, 20, 30}, 0) &&
+			closeSlices(gn.Data, []float64{0, 0.2, 1, 10.0 / 255, 20.0 / 255, 30.0 / 255}, 1e-12) &&
+			CheckDimensionEquality(rgba.Dimensions, []int{1, 2, 4}) && closeSlices(rgba.Data, []float64{10, 20, 30, 255, 40, 50, 60, 128}, 0) &&
+			CheckDimensionEquality(rgb.Dimensions, []int{1, 2, 3}) && closeSlices(rgb.Data, []float64{10, 20, 30, 40, 50, 60}, 0) &&
+			math.Abs(d.Data[0]-127.5) < 0.01)
+	}
+
+	{ // Encoders clip and round, and PNG round trips exactly
+		m := begin(t, n, "EncodePNG()/DecodeImage() round trip")
+		n++
+		a, _ := New([]float64{-5, 0.4, 99.6, 300, math.NaN(), 128}, []int{2, 3})
+		var buf bytes.Buffer
+		err := EncodePNG(&buf, a, ImageOptions{})
+		got, format, err2 := DecodeImage(&buf, ImageOptions{})
+		c, _ := New([]float64{0, 0.5, 1, 1, 0.25, 0, 0.2, 0.4, 0.6, 0.8, 1, 0.5}, []int{1, 3, 4})
+		buf.Reset()
+		err3 := EncodePNG(&buf, c, ImageOptions{Normalize: true})
+		gotC, _, err4 := DecodeImage(&buf, ImageOptions{Normalize: true})
+		ok := true
+		for i := range c.Data {
+			ok = ok && math.Abs(gotC.Data[i]-c.Data[i]) <= 0.5/255
+		}
+		m.end(ok && err == nil && err2 == nil && err3 == nil && err4 == nil && format == "png" &&
+			CheckDimensionEquality(got.Dimensions, []int{2, 3}) && closeSlices(got.Data, []float64{0, 0, 100, 255, 0, 128}, 0) &&
+			CheckDimensionEquality(gotC.Dimensions, []int{1, 3, 4}))
+	}
+
+	{ // JPEG is lossy and drops alpha; GIF decodes through the registered format
+		m := begin(t, n, "EncodeJPEG() and GIF decoding")
+		n++
+		data := make([]float64, 16*16*4)
+		for i := range data {
+			data[i] = 200
+			if i%4 == 3 {
+				data[i] = 10
+			}
+		}
+		a, _ := New(data, []int{16, 16, 4})
+		var buf bytes.Buffer
+		err := EncodeJPEG(&buf, a, ImageOptions{Quality: 95})
+		got, format, err2 := DecodeImage(&buf, ImageOptions{})
+		ok := err == nil && err2 == nil && format == "jpeg" && CheckDimensionEquality(got.Dimensions, []int{16, 16, 3})
+		for _, v := range got.Data {
+			ok = ok && math.Abs(v-200) <= 2
+		}
+		pal := image.NewPaletted(image.Rect(0, 0, 2, 1), color.Palette{color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}})
+		pal.Pix[1] = 1
+		buf.Reset()
+		gif.Encode(&buf, pal, nil)
+		gotGIF, format2, err3 := DecodeImage(&buf, ImageOptions{})
+		m.end(ok && err3 == nil && format2 == "gif" && CheckDimensionEquality(gotGIF.Dimensions, []int{1, 2, 3}) &&
+			closeSlices(gotGIF.Data, []float64{255, 0, 0, 0, 0, 255}, 0))
+	}
+
+	{ // Invalid shapes and options, and the file helpers
+		m := begin(t, n, "Validation and image files")
+		n++
+		bad, _ := New([]float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{2, 2, 2})
+		vec, _ := New([]float64{1, 2}, []int{2})
+		a := randomSparse(4, 5, 0.5)
+		_, errShape := ToImage(bad, ImageOptions{})
+		_, errRank := ToImage(vec, ImageOptions{})
+		errQuality := EncodeJPEG(&bytes.Buffer{}, a, ImageOptions{Quality: 101})
+		_, errMode := FromImage(image.NewGray(image.Rect(0, 0, 1, 1)), ImageOptions{Mode: ImageMode(9)})
+		_, _, errDecode := DecodeImage(bytes.NewReader([]byte("not an image")), ImageOptions{})
+		dir := t.TempDir()
+		errExt := WriteImageFile(filepath.Join(dir, "x.bmp"), a, ImageOptions{})
+		err1 := WriteImageFile(filepath.Join(dir, "x.png"), a, ImageOptions{Normalize: true})
+		got, err2 := ReadImageFile(filepath.Join(dir, "x.png"), ImageOptions{Normalize: true})
+		err3 := WriteImageFile(filepath.Join(dir, "x.JPG"), a, ImageOptions{Normalize: true})
+		_, err4 := ReadImageFile(filepath.Join(dir, "x.JPG"), ImageOptions{})
+		_, errMissing := ReadImageFile(filepath.Join(dir, "missing.png"), ImageOptions{})
+		ok := err1 == nil && err2 == nil && err3 == nil && err4 == nil && CheckDimensionEquality(got.Dimensions, []int{4, 5})
+		for i := range a.Data {
+			ok = ok && math.Abs(got.Data[i]-math.Max(0, math.Min(1, a.Data[i]))) <= 0.5/255
+		}
+		m.end(ok && errShape != nil && errRank != nil && errQuality != nil && errMode != nil &&
+			errDecode != nil && errExt != nil && errMissing != nil)
+	}
+}
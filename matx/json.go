@@ -0,0 +1,371 @@
+package matx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// JSONNonFinite selects how NaN and ±Inf, which JSON numbers cannot express, are encoded.
+type JSONNonFinite int
+
+const (
+	// NonFiniteString writes the strings "NaN", "Infinity" and "-Infinity".
+	NonFiniteString JSONNonFinite = iota
+	// NonFiniteNull writes null, which decodes as NaN; the sign of infinities is lost.
+	NonFiniteNull
+	// NonFiniteError makes encoding fail.
+	NonFiniteError
+)
+
+// JSONOptions configures EncodeJSON.
+//   - Flat writes {"shape": [...], "data": [...]} with the row-major Data instead of
+//     nested arrays mirroring Dimensions (the structure PrintMatx shows).
+//   - NonFinite selects the encoding of NaN and ±Inf (default NonFiniteString).
+type JSONOptions struct {
+	Flat      bool
+	NonFinite JSONNonFinite
+}
+
+// MarshalJSON implements json.Marshaler with nested arrays, e.g. [[1,2],[3,4]] for a
+// 2x2 matrix; a 0-d matrix is a bare number.
+func (m *Matx) MarshalJSON() ([]byte, error) {
+	return appendMatxJSON(nil, m, JSONOptions{})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting either encoding of EncodeJSON.
+// Nested arrays must be rectangular; null and the strings "NaN", "Infinity",
+// "-Infinity", "Inf" and "-Inf" decode as non-finite values. A bare number or an
+// empty flat shape decodes with Dimensions [1], as ReadNpy and the safetensors
+// readers load 0-d arrays.
+func (m *Matx) UnmarshalJSON(data []byte) error {
+	decoded, err := decodeMatxJSON(data)
+	if err != nil {
+		return err
+	}
+	*m = *decoded
+	return nil
+}
+
+// FlatJSON wraps a matrix so that encoding/json writes it as {"shape": ..., "data": ...},
+// for example as a struct field. It decodes either encoding.
+type FlatJSON struct {
+	*Matx
+}
+
+// MarshalJSON implements json.Marshaler with the flat encoding.
+func (f FlatJSON) MarshalJSON() ([]byte, error) {
+	return appendMatxJSON(nil, f.Matx, JSONOptions{Flat: true})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, allocating the wrapped matrix.
+func (f *FlatJSON) UnmarshalJSON(data []byte) error {
+	decoded, err := decodeMatxJSON(data)
+	if err != nil {
+		return err
+	}
+	f.Matx = decoded
+	return nil
+}
+
+// EncodeJSON writes `m` to `w` as JSON with the given options.
+func EncodeJSON(w io.Writer, m *Matx, opts JSONOptions) error {
+	data, err := appendMatxJSON(nil, m, opts)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("json matrix: write failed: %w", err)
+	}
+	return nil
+}
+
+// DecodeJSON reads one JSON value from `r` in either encoding of EncodeJSON.
+func DecodeJSON(r io.Reader) (*Matx, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("json matrix: %w", err)
+	}
+	return decodeMatxJSON(raw)
+}
+
+// appendMatxJSON appends the JSON encoding of `m` to buf.
+func appendMatxJSON(buf []byte, m *Matx, opts JSONOptions) ([]byte, error) {
+	if m == nil || m.Data == nil || m.Dimensions == nil {
+		return append(buf, "null"...), nil
+	}
+	if size, ok := shapeSize(m.Dimensions); !ok || size != len(m.Data) {
+		return nil, fmt.Errorf("json matrix: data size %d does not match shape %v", len(m.Data), m.Dimensions)
+	}
+
+	var err error
+	appendValue := func(buf []byte, v float64) []byte {
+		switch {
+		case !math.IsNaN(v) && !math.IsInf(v, 0):
+			return strconv.AppendFloat(buf, v, 'g', -1, 64)
+		case opts.NonFinite == NonFiniteNull:
+			return append(buf, "null"...)
+		case opts.NonFinite == NonFiniteError:
+			if err == nil {
+				err = fmt.Errorf("json matrix: cannot encode non-finite value %v", v)
+			}
+			return buf
+		case math.IsNaN(v):
+			return append(buf, `"NaN"`...)
+		case v > 0:
+			return append(buf, `"Infinity"`...)
+		}
+		return append(buf, `"-Infinity"`...)
+	}
+
+	if opts.Flat {
+		buf = append(buf, `{"shape":[`...)
+		for i, d := range m.Dimensions {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = strconv.AppendInt(buf, int64(d), 10)
+		}
+		buf = append(buf, `],"data":[`...)
+		for i, v := range m.Data {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendValue(buf, v)
+		}
+		buf = append(buf, "]}"...)
+		return buf, err
+	}
+
+	if len(m.Dimensions) == 0 {
+		return appendValue(buf, m.Data[0]), err
+	}
+	var nested func(buf []byte, offset, dim int) []byte
+	nested = func(buf []byte, offset, dim int) []byte {
+		stride := 1
+		for _, s := range m.Dimensions[dim+1:] {
+			stride *= s
+		}
+		buf = append(buf, '[')
+		for i := 0; i < m.Dimensions[dim]; i++ {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			if dim == len(m.Dimensions)-1 {
+				buf = appendValue(buf, m.Data[offset+i])
+			} else {
+				buf = nested(buf, offset+i*stride, dim+1)
+			}
+		}
+		return append(buf, ']')
+	}
+	return nested(buf, 0, 0), err
+}
+
+// jsonFloat decodes a number, null or one of the non-finite strings.
+type jsonFloat float64
+
+func (f *jsonFloat) UnmarshalJSON(data []byte) error {
+	v, err := parseJSONFloat(data)
+	*f = jsonFloat(v)
+	return err
+}
+
+// parseJSONFloat parses a single JSON scalar as a float64.
+func parseJSONFloat(tok []byte) (float64, error) {
+	s := string(tok)
+	switch s {
+	case "null", `"NaN"`, `"nan"`:
+		return math.NaN(), nil
+	case `"Infinity"`, `"+Infinity"`, `"Inf"`, `"+Inf"`, `"inf"`:
+		return math.Inf(1), nil
+	case `"-Infinity"`, `"-Inf"`, `"-inf"`:
+		return math.Inf(-1), nil
+	}
+	if s == "" || strings.Trim(s, "0123456789+-.eE") != "" {
+		return 0, fmt.Errorf("json matrix: %s is not a number", s)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("json matrix: %s is not a number", s)
+	}
+	return v, nil
+}
+
+// decodeMatxJSON dispatches on the first character: an object is the flat
+// encoding, anything else the nested one.
+func decodeMatxJSON(data []byte) (*Matx, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("json matrix: empty input")
+	}
+	if trimmed[0] != '{' {
+		p := &jsonNested{s: trimmed}
+		return p.parse()
+	}
+
+	var flat struct {
+		Shape []int       `json:"shape"`
+		Data  []jsonFloat `json:"data"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&flat); err != nil {
+		return nil, fmt.Errorf("json matrix: %w", err)
+	}
+	if flat.Shape == nil || flat.Data == nil {
+		return nil, fmt.Errorf(`json matrix: flat encoding needs both "shape" and "data"`)
+	}
+	size, ok := shapeSize(flat.Shape)
+	if !ok {
+		return nil, fmt.Errorf("json matrix: shape %v has a negative dimension or too many elements", flat.Shape)
+	}
+	if size != len(flat.Data) {
+		return nil, fmt.Errorf("json matrix: shape %v needs %d values, got %d", flat.Shape, size, len(flat.Data))
+	}
+	values := make([]float64, len(flat.Data))
+	for i, v := range flat.Data {
+		values[i] = float64(v)
+	}
+	if len(flat.Shape) == 0 {
+		flat.Shape = []int{1}
+	}
+	return New(values, flat.Shape)
+}
+
+// jsonMaxDepth bounds the nesting of arrays, matching the dimensions of the binary format.
+const jsonMaxDepth = binaryMaxDims
+
+// jsonNested parses nested arrays into a flat slice, fixing the length of each depth
+// at its first occurrence and rejecting ragged or mixed-depth input.
+type jsonNested struct {
+	s     []byte
+	pos   int
+	shape []int // length per depth; -1 while unknown
+	leaf  int   // depth of the scalars; -1 while unknown
+	data  []float64
+	path  []int
+}
+
+func (p *jsonNested) parse() (*Matx, error) {
+	p.leaf = -1
+	if err := p.value(0); err != nil {
+		return nil, err
+	}
+	p.skip()
+	if p.pos != len(p.s) {
+		return nil, fmt.Errorf("json matrix: unexpected data after the array at offset %d", p.pos)
+	}
+	if p.leaf < 0 {
+		// Only empty arrays: the innermost length is 0 and deeper axes are unknown
+		p.leaf = len(p.shape)
+	}
+	dims := append([]int{}, p.shape[:p.leaf]...)
+	if len(dims) == 0 {
+		dims = []int{1}
+	}
+	if p.data == nil {
+		p.data = []float64{}
+	}
+	return New(p.data, dims)
+}
+
+func (p *jsonNested) skip() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// where formats the index path of the current element for errors.
+func (p *jsonNested) where() string {
+	parts := make([]string, len(p.path))
+	for i, v := range p.path {
+		parts[i] = strconv.Itoa(v)
+	}
+	return "[" + strings.Join(parts, "][") + "]"
+}
+
+// value parses the element at `depth`. Scalars may only appear at one depth, below
+// every array, and all arrays at a depth must have the length of the first one.
+func (p *jsonNested) value(depth int) error {
+	p.skip()
+	if p.pos >= len(p.s) {
+		return fmt.Errorf("json matrix: unexpected end of input at %s", p.where())
+	}
+
+	if p.s[p.pos] != '[' {
+		if depth < len(p.shape) || (p.leaf >= 0 && p.leaf != depth) {
+			return fmt.Errorf("json matrix: ragged array: number at %s where an array was expected", p.where())
+		}
+		p.leaf = depth
+		start := p.pos
+		if p.s[p.pos] == '"' {
+			end := bytes.IndexByte(p.s[p.pos+1:], '"')
+			if end < 0 {
+				return fmt.Errorf("json matrix: unterminated string at %s", p.where())
+			}
+			p.pos += end + 2
+		} else {
+			for p.pos < len(p.s) && strings.IndexByte(",] \t\r\n", p.s[p.pos]) < 0 {
+				p.pos++
+			}
+		}
+		v, err := parseJSONFloat(p.s[start:p.pos])
+		if err != nil {
+			return fmt.Errorf("%w at %s", err, p.where())
+		}
+		p.data = append(p.data, v)
+		return nil
+	}
+
+	if p.leaf >= 0 && depth >= p.leaf {
+		return fmt.Errorf("json matrix: ragged array: array at %s where a number was expected", p.where())
+	}
+	if depth == jsonMaxDepth {
+		return fmt.Errorf("json matrix: arrays nested deeper than %d levels at offset %d", jsonMaxDepth, p.pos)
+	}
+	p.pos++
+	if depth == len(p.shape) {
+		p.shape = append(p.shape, -1)
+	}
+
+	n := 0
+	p.skip()
+	if p.pos < len(p.s) && p.s[p.pos] == ']' {
+		p.pos++
+	} else {
+		p.path = append(p.path, 0)
+		for {
+			p.path[len(p.path)-1] = n
+			if err := p.value(depth + 1); err != nil {
+				return err
+			}
+			n++
+			p.skip()
+			if p.pos >= len(p.s) {
+				return fmt.Errorf("json matrix: unexpected end of input at %s", p.where())
+			}
+			if p.s[p.pos] == ']' {
+				p.pos++
+				break
+			}
+			if p.s[p.pos] != ',' {
+				return fmt.Errorf("json matrix: expected ',' or ']' at offset %d", p.pos)
+			}
+			p.pos++
+		}
+		p.path = p.path[:len(p.path)-1]
+	}
+
+	switch {
+	case p.shape[depth] < 0:
+		p.shape[depth] = n
+	case p.shape[depth] != n:
+		return fmt.Errorf("json matrix: ragged array at %s: length %d, want %d", p.where(), n, p.shape[depth])
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package matx
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	n := 1
+
+	{ // Nested arrays mirror Dimensions and round-trip exactly
+		m := begin(t, n, "MarshalJSON()/UnmarshalJSON() nested")
+		n++
+		a, _ := New([]float64{1, 2.5, -3, 1.0 / 3, 0, 1e-300}, []int{2, 3})
+		data, err := json.Marshal(a)
+		var got Matx
+		err2 := json.Unmarshal(data, &got)
+		cube, _ := New([]float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{2, 2, 2})
+		cubeJSON, _ := json.Marshal(cube)
+		empty, _ := New([]float64{}, []int{2, 0})
+		emptyJSON, _ := json.Marshal(empty)
+		var gotEmpty Matx
+		err3 := json.Unmarshal(emptyJSON, &gotEmpty)
+		m.end(err == nil && err2 == nil && err3 == nil && denseEqual(&got, a, 0) &&
+			string(data) == "[[1,2.5,-3],[0.3333333333333333,0,1e-300]]" &&
+			string(cubeJSON) == "[[[1,2],[3,4]],[[5,6],[7,8]]]" &&
+			string(emptyJSON) == "[[],[]]" && CheckDimensionEquality(gotEmpty.Dimensions, []int{2, 0}))
+	}
+
+	{ // Flat encoding, directly and as a struct field
+		m := begin(t, n, "EncodeJSON() flat / FlatJSON")
+		n++
+		a, _ := New([]float64{1, 2, 3, 4, 5, 6}, []int{3, 2})
+		var buf bytes.Buffer
+		err := EncodeJSON(&buf, a, JSONOptions{Flat: true})
+		flat := buf.String()
+		got, err2 := DecodeJSON(&buf)
+		type payload struct {
+			Weights FlatJSON `json:"weights"`
+			Bias    *Matx    `json:"bias"`
+		}
+		bias, _ := New([]float64{0.5, -0.5}, []int{2})
+		out, err3 := json.Marshal(payload{Weights: FlatJSON{a}, Bias: bias})
+		var back payload
+		err4 := json.Unmarshal(out, &back)
+		m.end(err == nil && err2 == nil && err3 == nil && err4 == nil &&
+			flat == `{"shape":[3,2],"data":[1,2,3,4,5,6]}` && denseEqual(got, a, 0) &&
+			string(out) == `{"weights":{"shape":[3,2],"data":[1,2,3,4,5,6]},"bias":[0.5,-0.5]}` &&
+			denseEqual(back.Weights.Matx, a, 0) && closeSlices(back.Bias.Data, bias.Data, 0))
+	}
+
+	{ // NaN and infinities
+		m := begin(t, n, "EncodeJSON() non-finite values")
+		n++
+		a, _ := New([]float64{math.NaN(), math.Inf(1), math.Inf(-1), 1}, []int{4})
+		enc := func(opts JSONOptions) (string, error) {
+			var buf bytes.Buffer
+			err := EncodeJSON(&buf, a, opts)
+			return buf.String(), err
+		}
+		s, err1 := enc(JSONOptions{})
+		null, err2 := enc(JSONOptions{NonFinite: NonFiniteNull, Flat: true})
+		_, errStrict := enc(JSONOptions{NonFinite: NonFiniteError})
+		back, err3 := DecodeJSON(strings.NewReader(s))
+		backNull, err4 := DecodeJSON(strings.NewReader(null))
+		m.end(err1 == nil && err2 == nil && err3 == nil && err4 == nil && errStrict != nil &&
+			s == `["NaN","Infinity","-Infinity",1]` && null == `{"shape":[4],"data":[null,null,null,1]}` &&
+			math.IsNaN(back.Data[0]) && math.IsInf(back.Data[1], 1) && math.IsInf(back.Data[2], -1) &&
+			math.IsNaN(backNull.Data[1]) && backNull.Data[3] == 1)
+	}
+
+	{ // Ragged and malformed input is rejected with its location
+		m := begin(t, n, "UnmarshalJSON() validation")
+		n++
+		bad := map[string]string{
+			"[[1,2],[3]]":                      "at [1]: length 1, want 2",
+			"[[1,2],3]":                        "number at [1]",
+			"[1,[2]]":                          "array at [1]",
+			"[[[1]],[[1,2]]]":                  "at [1][0]: length 2, want 1",
+			"[[1,2],[3,x]]":                    "x is not a number at [1][1]",
+			`[1,"two"]`:                        `"two" is not a number`,
+			"[1,2":                             "unexpected end",
+			"[1 2]":                            "expected ','",
+			"[1],[2]":                          "unexpected data",
+			`{"shape":[2,2],"data":[1,2,3]}`:   "needs 4 values",
+			`{"shape":[2],"data":[1,2],"x":1}`: "unknown field",
+			`{"data":[1]}`:                     `needs both "shape" and "data"`,
+			`{"shape":[-1],"data":[]}`:         "negative dimension",
+			`{"shape":[4294967296,4294967296],"data":[]}`: "too many elements",
+			"": "empty input",
+			strings.Repeat("[", 65) + "1" + strings.Repeat("]", 65): "nested deeper than 64",
+			strings.Repeat("[", 1<<20):                              "nested deeper than 64",
+		}
+		ok := true
+		for input, want := range bad {
+			var got Matx
+			err := got.UnmarshalJSON([]byte(input))
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Logf("input %q: got %v, want %q", input, err, want)
+				ok = false
+			}
+		}
+		var scalar Matx
+		errScalar := json.Unmarshal([]byte(" 2.5 "), &scalar)
+		scalarJSON, _ := json.Marshal(&scalar)
+		_, errWrap := json.Marshal(&Matx{Data: []float64{}, Dimensions: []int{1 << 32, 1 << 32}})
+		var flatScalar Matx
+		errFlat := json.Unmarshal([]byte(`{"shape":[],"data":[3]}`), &flatScalar)
+		zeroD, _ := json.Marshal(&Matx{Data: []float64{2.5}, Dimensions: []int{}})
+		var deep Matx
+		errDeep := deep.UnmarshalJSON([]byte(strings.Repeat("[", 64) + "1" + strings.Repeat("]", 64)))
+		m.end(ok && errScalar == nil && CheckDimensionEquality(scalar.Dimensions, []int{1}) && string(scalarJSON) == "[2.5]" &&
+			errFlat == nil && CheckDimensionEquality(flatScalar.Dimensions, []int{1}) && flatScalar.Data[0] == 3 &&
+			string(zeroD) == "2.5" && errWrap != nil && errDeep == nil && len(deep.Dimensions) == 64)
+	}
+}
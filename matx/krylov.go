@@ -0,0 +1,511 @@
+package matx
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Preconditioner approximates the inverse of an operator A. The iterative solvers call
+// it once or twice per iteration to turn a residual into a better search direction.
+type Preconditioner interface {
+	// Precondition writes M⁻¹·r into dst; dst and r have the operator's order.
+	Precondition(dst, r []float64) error
+}
+
+// SolverOptions configures CG, GMRES and BiCGSTAB. Zero values select defaults.
+// - Tol is the relative residual target ‖b − A·x‖ ≤ Tol·‖b‖ (default 1e-8).
+// - MaxIter caps the number of iterations (default 10·n).
+// - Restart is the GMRES Krylov subspace size before restarting (default min(30, n)).
+// - Precond is an optional preconditioner; nil means none.
+// - X0 is an optional initial guess; it is not modified.
+type SolverOptions struct {
+	Tol     float64
+	MaxIter int
+	Restart int
+	Precond Preconditioner
+	X0      []float64
+}
+
+// SolveResult is the outcome of an iterative solve.
+//   - X is the final iterate (the solution when Converged is set).
+//   - Iterations counts operator applications in the main loop.
+//   - Residuals holds ‖b − A·x‖ for the initial guess and after every iteration;
+//     GMRES records its inexpensive residual estimate inside each cycle.
+type SolveResult struct {
+	X          []float64
+	Iterations int
+	Residuals  []float64
+	Converged  bool
+}
+
+// NotConvergedError is returned, together with the last SolveResult, when a solver
+// stops without reaching its tolerance, either at MaxIter or after a breakdown.
+type NotConvergedError struct {
+	Method     string
+	Iterations int
+	Residual   float64 // relative residual ‖r‖/‖b‖ at the last iterate
+	Tol        float64
+	Reason     string
+}
+
+func (e *NotConvergedError) Error() string {
+	return fmt.Sprintf("%s did not converge after %d iterations: relative residual %.3e > tolerance %.3e (%s)",
+		e.Method, e.Iterations, e.Residual, e.Tol, e.Reason)
+}
+
+// krylovSetup holds the validated inputs shared by all three solvers.
+type krylovSetup struct {
+	n       int
+	tol     float64
+	maxIter int
+	bnorm   float64
+	x, r    []float64
+	result  *SolveResult
+}
+
+// newKrylovSetup validates `a`, `b` and `opts`, applies defaults and computes the
+// initial residual r = b − A·x0.
+func newKrylovSetup(a Operator, b []float64, opts SolverOptions) (*krylovSetup, error) {
+	if a == nil {
+		return nil, fmt.Errorf("operator is nil")
+	}
+	rows, cols := a.Dims()
+	if rows != cols {
+		return nil, fmt.Errorf("operator must be square, got %dx%d", rows, cols)
+	}
+	n := rows
+	if len(b) != n {
+		return nil, fmt.Errorf("right-hand side length %d does not match operator order %d", len(b), n)
+	}
+	if opts.X0 != nil && len(opts.X0) != n {
+		return nil, fmt.Errorf("initial guess length %d does not match operator order %d", len(opts.X0), n)
+	}
+
+	s := &krylovSetup{n: n, tol: opts.Tol, maxIter: opts.MaxIter}
+	if s.tol <= 0 {
+		s.tol = 1e-8
+	}
+	if s.maxIter <= 0 {
+		s.maxIter = 10 * n
+	}
+
+	s.x = make([]float64, n)
+	if opts.X0 != nil {
+		copy(s.x, opts.X0)
+	}
+	s.r = make([]float64, n)
+	if err := residual(a, b, s.x, s.r); err != nil {
+		return nil, err
+	}
+
+	s.bnorm = normVec(b)
+	if s.bnorm == 0 {
+		s.bnorm = 1
+	}
+	s.result = &SolveResult{X: s.x, Residuals: []float64{normVec(s.r)}}
+	return s, nil
+}
+
+// converged reports whether the residual norm `rnorm` meets the tolerance.
+func (s *krylovSetup) converged(rnorm float64) bool {
+	return rnorm <= s.tol*s.bnorm
+}
+
+// fail builds the NotConvergedError for the current state.
+func (s *krylovSetup) fail(method, reason string) error {
+	last := s.result.Residuals[len(s.result.Residuals)-1]
+	return &NotConvergedError{
+		Method:     method,
+		Iterations: s.result.Iterations,
+		Residual:   last / s.bnorm,
+		Tol:        s.tol,
+		Reason:     reason,
+	}
+}
+
+// precondition applies `m` to r, or copies r when there is no preconditioner.
+func precondition(m Preconditioner, dst, r []float64) error {
+	if m == nil {
+		copy(dst, r)
+		return nil
+	}
+	return m.Precondition(dst, r)
+}
+
+// residual writes b − A·x into r.
+func residual(a Operator, b, x, r []float64) error {
+	if err := a.Apply(r, x); err != nil {
+		return err
+	}
+	for i := range r {
+		r[i] = b[i] - r[i]
+	}
+	return nil
+}
+
+// CG solves A·x = b with the (preconditioned) conjugate gradient method.
+// A and the preconditioner must be symmetric positive definite.
+// On failure the returned result holds the last iterate and err is a *NotConvergedError.
+func CG(a Operator, b []float64, opts SolverOptions) (*SolveResult, error) {
+	return CGCtx(context.Background(), a, b, opts)
+}
+
+// CGCtx is CG with cancellation checked every iteration; progress counts iterations out of MaxIter.
+func CGCtx(ctx context.Context, a Operator, b []float64, opts SolverOptions, progress ...ProgressFunc) (result *SolveResult, err error) {
+	if span := startTraceShapes("CG", operatorShape(a), []int{len(b)}); span != nil {
+		defer func() { span.end(krylovFlops(a, result), 0, err) }()
+	}
+
+	s, err := newKrylovSetup(a, b, opts)
+	if err != nil {
+		return nil, err
+	}
+	if s.converged(s.result.Residuals[0]) {
+		s.result.Converged = true
+		return s.result, nil
+	}
+
+	n := s.n
+	z := make([]float64, n)
+	p := make([]float64, n)
+	ap := make([]float64, n)
+	if err := precondition(opts.Precond, z, s.r); err != nil {
+		return nil, err
+	}
+	copy(p, z)
+	rz := dotVec(s.r, z)
+
+	for s.result.Iterations < s.maxIter {
+		if err := checkCtx(ctx, "CG"); err != nil {
+			return s.result, err
+		}
+
+		if err := a.Apply(ap, p); err != nil {
+			return nil, err
+		}
+		pap := dotVec(p, ap)
+		if pap <= 0 || math.IsNaN(pap) {
+			return s.result, s.fail("CG", "operator is not positive definite")
+		}
+
+		alpha := rz / pap
+		axpyVec(alpha, p, s.x)
+		axpyVec(-alpha, ap, s.r)
+
+		rnorm := normVec(s.r)
+		s.result.Iterations++
+		s.result.Residuals = append(s.result.Residuals, rnorm)
+		reportProgress(progress, s.result.Iterations, s.maxIter)
+		if s.converged(rnorm) {
+			s.result.Converged = true
+			return s.result, nil
+		}
+
+		if err := precondition(opts.Precond, z, s.r); err != nil {
+			return nil, err
+		}
+		rzNew := dotVec(s.r, z)
+		beta := rzNew / rz
+		rz = rzNew
+		for i := range p {
+			p[i] = z[i] + beta*p[i]
+		}
+	}
+
+	return s.result, s.fail("CG", "maximum iterations reached")
+}
+
+// GMRES solves A·x = b with restarted GMRES(m) and right preconditioning,
+// for general (nonsymmetric) square operators.
+// On failure the returned result holds the last iterate and err is a *NotConvergedError.
+func GMRES(a Operator, b []float64, opts SolverOptions) (*SolveResult, error) {
+	return GMRESCtx(context.Background(), a, b, opts)
+}
+
+// GMRESCtx is GMRES with cancellation checked every iteration; progress counts iterations out of MaxIter.
+func GMRESCtx(ctx context.Context, a Operator, b []float64, opts SolverOptions, progress ...ProgressFunc) (result *SolveResult, err error) {
+	if span := startTraceShapes("GMRES", operatorShape(a), []int{len(b)}); span != nil {
+		defer func() { span.end(krylovFlops(a, result), 0, err) }()
+	}
+
+	s, err := newKrylovSetup(a, b, opts)
+	if err != nil {
+		return nil, err
+	}
+	beta := s.result.Residuals[0]
+	if s.converged(beta) {
+		s.result.Converged = true
+		return s.result, nil
+	}
+
+	n := s.n
+	m := opts.Restart
+	if m <= 0 {
+		m = min(30, n)
+	}
+	m = max(1, min(m, n))
+
+	// Arnoldi basis, Hessenberg matrix (column-major per Arnoldi step) and Givens rotations
+	v := make([][]float64, m+1)
+	for i := range v {
+		v[i] = make([]float64, n)
+	}
+	h := make([][]float64, m)
+	for j := range h {
+		h[j] = make([]float64, m+1)
+	}
+	cs := make([]float64, m)
+	sn := make([]float64, m)
+	g := make([]float64, m+1)
+	w := make([]float64, n)
+	z := make([]float64, n)
+
+	for s.result.Iterations < s.maxIter {
+		for i := range v[0] {
+			v[0][i] = s.r[i] / beta
+		}
+		for i := range g {
+			g[i] = 0
+		}
+		g[0] = beta
+
+		k := 0
+		for k < m && s.result.Iterations < s.maxIter {
+			if err := checkCtx(ctx, "GMRES"); err != nil {
+				return s.result, err
+			}
+
+			// w = A·M⁻¹·v_k
+			if err := precondition(opts.Precond, z, v[k]); err != nil {
+				return nil, err
+			}
+			if err := a.Apply(w, z); err != nil {
+				return nil, err
+			}
+
+			// Modified Gram–Schmidt against the existing basis
+			col := h[k]
+			for i := 0; i <= k; i++ {
+				col[i] = dotVec(w, v[i])
+				axpyVec(-col[i], v[i], w)
+			}
+			col[k+1] = normVec(w)
+			subdiag := col[k+1]
+			if subdiag != 0 {
+				for i := range w {
+					v[k+1][i] = w[i] / col[k+1]
+				}
+			}
+
+			// Apply previous rotations, then zero the subdiagonal with a new one
+			for i := 0; i < k; i++ {
+				col[i], col[i+1] = cs[i]*col[i]+sn[i]*col[i+1], -sn[i]*col[i]+cs[i]*col[i+1]
+			}
+			denom := math.Hypot(col[k], col[k+1])
+			if denom == 0 {
+				cs[k], sn[k] = 1, 0
+			} else {
+				cs[k], sn[k] = col[k]/denom, col[k+1]/denom
+			}
+			col[k], col[k+1] = denom, 0
+			g[k], g[k+1] = cs[k]*g[k], -sn[k]*g[k]
+
+			k++
+			s.result.Iterations++
+			estimate := math.Abs(g[k])
+			s.result.Residuals = append(s.result.Residuals, estimate)
+			reportProgress(progress, s.result.Iterations, s.maxIter)
+			// A zero subdiagonal means the Krylov space is invariant: the update is exact
+			if s.converged(estimate) || subdiag == 0 {
+				break
+			}
+		}
+
+		// Solve the k×k upper triangular system H·y = g and update x += M⁻¹·(V·y)
+		y := make([]float64, k)
+		for i := k - 1; i >= 0; i-- {
+			sum := g[i]
+			for j := i + 1; j < k; j++ {
+				sum -= h[j][i] * y[j]
+			}
+			if h[i][i] == 0 {
+				return s.result, s.fail("GMRES", "singular Hessenberg matrix")
+			}
+			y[i] = sum / h[i][i]
+		}
+		for i := range w {
+			w[i] = 0
+		}
+		for j := 0; j < k; j++ {
+			axpyVec(y[j], v[j], w)
+		}
+		if err := precondition(opts.Precond, z, w); err != nil {
+			return nil, err
+		}
+		axpyVec(1, z, s.x)
+
+		// Restart from the true residual
+		if err := residual(a, b, s.x, s.r); err != nil {
+			return nil, err
+		}
+		beta = normVec(s.r)
+		s.result.Residuals[len(s.result.Residuals)-1] = beta
+		if s.converged(beta) {
+			s.result.Converged = true
+			return s.result, nil
+		}
+	}
+
+	return s.result, s.fail("GMRES", "maximum iterations reached")
+}
+
+// BiCGSTAB solves A·x = b with the stabilized biconjugate gradient method and
+// right preconditioning, for general (nonsymmetric) square operators.
+// On failure the returned result holds the last iterate and err is a *NotConvergedError.
+func BiCGSTAB(a Operator, b []float64, opts SolverOptions) (*SolveResult, error) {
+	return BiCGSTABCtx(context.Background(), a, b, opts)
+}
+
+// BiCGSTABCtx is BiCGSTAB with cancellation checked every iteration; progress counts
+// iterations out of MaxIter.
+func BiCGSTABCtx(ctx context.Context, a Operator, b []float64, opts SolverOptions, progress ...ProgressFunc) (result *SolveResult, err error) {
+	if span := startTraceShapes("BiCGSTAB", operatorShape(a), []int{len(b)}); span != nil {
+		defer func() { span.end(2*krylovFlops(a, result), 0, err) }()
+	}
+
+	s, err := newKrylovSetup(a, b, opts)
+	if err != nil {
+		return nil, err
+	}
+	if s.converged(s.result.Residuals[0]) {
+		s.result.Converged = true
+		return s.result, nil
+	}
+
+	n := s.n
+	rhat := append([]float64(nil), s.r...)
+	p := make([]float64, n)
+	v := make([]float64, n)
+	phat := make([]float64, n)
+	shat := make([]float64, n)
+	sv := make([]float64, n)
+	t := make([]float64, n)
+	rho, alpha, omega := 1.0, 1.0, 1.0
+
+	for s.result.Iterations < s.maxIter {
+		if err := checkCtx(ctx, "BiCGSTAB"); err != nil {
+			return s.result, err
+		}
+
+		rhoNew := dotVec(rhat, s.r)
+		if rhoNew == 0 {
+			return s.result, s.fail("BiCGSTAB", "breakdown: rho = 0")
+		}
+		if s.result.Iterations == 0 {
+			copy(p, s.r)
+		} else {
+			beta := (rhoNew / rho) * (alpha / omega)
+			for i := range p {
+				p[i] = s.r[i] + beta*(p[i]-omega*v[i])
+			}
+		}
+		rho = rhoNew
+
+		if err := precondition(opts.Precond, phat, p); err != nil {
+			return nil, err
+		}
+		if err := a.Apply(v, phat); err != nil {
+			return nil, err
+		}
+		rv := dotVec(rhat, v)
+		if rv == 0 {
+			return s.result, s.fail("BiCGSTAB", "breakdown: rhat·v = 0")
+		}
+		alpha = rho / rv
+
+		for i := range sv {
+			sv[i] = s.r[i] - alpha*v[i]
+		}
+		s.result.Iterations++
+		if snorm := normVec(sv); s.converged(snorm) {
+			axpyVec(alpha, phat, s.x)
+			copy(s.r, sv)
+			s.result.Residuals = append(s.result.Residuals, snorm)
+			s.result.Converged = true
+			reportProgress(progress, s.result.Iterations, s.maxIter)
+			return s.result, nil
+		}
+
+		if err := precondition(opts.Precond, shat, sv); err != nil {
+			return nil, err
+		}
+		if err := a.Apply(t, shat); err != nil {
+			return nil, err
+		}
+		tt := dotVec(t, t)
+		if tt == 0 {
+			return s.result, s.fail("BiCGSTAB", "breakdown: t = 0")
+		}
+		omega = dotVec(t, sv) / tt
+
+		axpyVec(alpha, phat, s.x)
+		axpyVec(omega, shat, s.x)
+		for i := range s.r {
+			s.r[i] = sv[i] - omega*t[i]
+		}
+
+		rnorm := normVec(s.r)
+		s.result.Residuals = append(s.result.Residuals, rnorm)
+		reportProgress(progress, s.result.Iterations, s.maxIter)
+		if s.converged(rnorm) {
+			s.result.Converged = true
+			return s.result, nil
+		}
+		if omega == 0 {
+			return s.result, s.fail("BiCGSTAB", "breakdown: omega = 0")
+		}
+	}
+
+	return s.result, s.fail("BiCGSTAB", "maximum iterations reached")
+}
+
+// operatorShape returns the [rows, cols] shape of `a` for trace events.
+func operatorShape(a Operator) []int {
+	if a == nil {
+		return nil
+	}
+	rows, cols := a.Dims()
+	return []int{rows, cols}
+}
+
+// krylovFlops roughly estimates solver work as two operator applications plus a
+// handful of vector updates per iteration, assuming a dense operator.
+func krylovFlops(a Operator, result *SolveResult) int64 {
+	if a == nil || result == nil {
+		return 0
+	}
+	rows, cols := a.Dims()
+	return int64(result.Iterations) * (2*int64(rows)*int64(cols) + 10*int64(rows))
+}
+
+// dotVec returns the dot product of two equal-length vectors.
+func dotVec(a, b []float64) float64 {
+	sum := 0.0
+	for i, v := range a {
+		sum += v * b[i]
+	}
+	return sum
+}
+
+// normVec returns the Euclidean norm of a vector.
+func normVec(a []float64) float64 {
+	return math.Sqrt(dotVec(a, a))
+}
+
+// axpyVec computes y += alpha·x in place.
+func axpyVec(alpha float64, x, y []float64) {
+	for i, v := range x {
+		y[i] += alpha * v
+	}
+}
@@ -0,0 +1,92 @@
+package matx
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// laplacian2D returns the 5-point finite-difference Laplacian on a k×k grid,
+// optionally with a first-order convection term that makes it nonsymmetric.
+func laplacian2D(k int, convection float64) *CSR {
+	n := k * k
+	coo := &COO{Rows: n, Cols: n}
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			row := i*k + j
+			coo.Append(row, row, 4)
+			if i > 0 {
+				coo.Append(row, row-k, -1)
+			}
+			if i < k-1 {
+				coo.Append(row, row+k, -1)
+			}
+			if j > 0 {
+				coo.Append(row, row-1, -1-convection)
+			}
+			if j < k-1 {
+				coo.Append(row, row+1, -1+convection)
+			}
+		}
+	}
+	return coo.ToCSR()
+}
+
+// relResidual returns ‖b − A·x‖/‖b‖.
+func relResidual(a Operator, b, x []float64) float64 {
+	r := make([]float64, len(b))
+	residual(a, b, x, r)
+	return normVec(r) / normVec(b)
+}
+
+func TestKrylov(t *testing.T) {
+	n := 1
+
+	spd := laplacian2D(12, 0)
+	nonsym := laplacian2D(12, 0.4)
+	b := make([]float64, spd.Rows)
+	for i := range b {
+		b[i] = math.Sin(float64(i))
+	}
+
+	{ // CG on an SPD system
+		m := begin(t, n, "CG() 2D Laplacian")
+		n++
+		res, err := CG(spd, b, SolverOptions{Tol: 1e-10})
+		m.end(err == nil && res.Converged && relResidual(spd, b, res.X) < 1e-9 &&
+			len(res.Residuals) == res.Iterations+1)
+	}
+
+	{ // GMRES with restarts on a nonsymmetric system
+		m := begin(t, n, "GMRES() restarted, nonsymmetric")
+		n++
+		res, err := GMRES(nonsym, b, SolverOptions{Tol: 1e-10, Restart: 10})
+		m.end(err == nil && res.Converged && relResidual(nonsym, b, res.X) < 1e-9)
+	}
+
+	{ // BiCGSTAB on a nonsymmetric system
+		m := begin(t, n, "BiCGSTAB() nonsymmetric")
+		n++
+		res, err := BiCGSTAB(nonsym, b, SolverOptions{Tol: 1e-10})
+		m.end(err == nil && res.Converged && relResidual(nonsym, b, res.X) < 1e-9)
+	}
+
+	{ // Dense and matrix-free operators
+		m := begin(t, n, "CG() dense and OperatorFunc")
+		n++
+		dense, _ := spd.ToMatx()
+		op, _ := DenseOperator(dense)
+		res1, err1 := CG(op, b, SolverOptions{})
+		fn := OperatorFunc(spd.Rows, spd.Cols, func(dst, x []float64) { spd.mulVecInto(dst, x) }, nil)
+		res2, err2 := CG(fn, b, SolverOptions{})
+		m.end(err1 == nil && err2 == nil && relResidual(spd, b, res1.X) < 1e-7 && relResidual(spd, b, res2.X) < 1e-7)
+	}
+
+	{ // Non-convergence is reported with a typed error
+		m := begin(t, n, "CG() NotConvergedError")
+		n++
+		res, err := CG(spd, b, SolverOptions{Tol: 1e-12, MaxIter: 3})
+		var nc *NotConvergedError
+		m.end(errors.As(err, &nc) && nc.Iterations == 3 && res != nil && !res.Converged && len(res.Residuals) == 4)
+	}
+}
@@ -1,13 +1,20 @@
 package matx
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
 )
 
 // Det computes the determinant of a square matrix using LU decomposition with pivoting.
 // Returns an error if the matrix is not square or is nil.
-func Det(m *Matx) (float64, error) {
+func Det(m *Matx) (det float64, err error) {
+	if span := startTrace("Det", m); span != nil {
+		// The factorization reports its own LUFactor event
+		defer func() { span.end(squareOrder(m), 0, err) }()
+	}
+
 	if m == nil {
 		return 0, fmt.Errorf("Nil matrix passed")
 	}
@@ -15,87 +22,74 @@ func Det(m *Matx) (float64, error) {
 		return 0, fmt.Errorf("Matrix must be square")
 	}
 
-	_, U, _, swapCount, err := LUDecomposeWithPivoting(m)
+	lu, err := LUFactor(m)
+	if errors.Is(err, errSingular) {
+		return 0, nil // exactly singular: determinant is zero
+	}
 	if err != nil {
 		return 0, err
 	}
 
-	det := 1.0
 	// Product of the diagonal elements of U gives the determinant
-	for i := 0; i < m.Dimensions[0]; i++ {
-		diag := mustGet(U, i, i)
-		if math.Abs(diag) < 1e-12 {
+	n := m.Dimensions[0]
+	for i := 0; i < n; i++ {
+		if math.Abs(lu.Factors.Data[i*n+i]) < 1e-12 {
 			return 0, nil // determinant is zero (singular matrix)
 		}
-		det *= diag
-	}
-
-	// Adjust sign based on number of row swaps
-	if swapCount%2 != 0 {
-		det = -det
 	}
 
-	return det, nil
+	return lu.Det(), nil
 }
 
 // Invert computes the inverse of a square matrix using LU decomposition.
 // Returns an error if the matrix is not square or inversion fails.
 func Invert(m *Matx) (*Matx, error) {
+	return InvertCtx(context.Background(), m)
+}
+
+// InvertCtx is Invert with cancellation. It checks `ctx` between LU panels and between
+// blocks of solved columns, and reports progress over 2n units: n factored columns
+// followed by n solved columns.
+func InvertCtx(ctx context.Context, m *Matx, progress ...ProgressFunc) (inv *Matx, err error) {
+	if span := startTrace("Invert", m); span != nil {
+		// n triangular solves of 2n² each; the factorization reports its own LUFactor event
+		defer func() { n := squareOrder(m); span.end(2*n*n*n, 8*elementCount(inv), err) }()
+	}
+
 	if m == nil {
 		return nil, fmt.Errorf("Nil matrix")
 	}
-
-	n := m.Dimensions[0]
-	if len(m.Dimensions) != 2 || n != m.Dimensions[1] {
+	if len(m.Dimensions) != 2 || m.Dimensions[0] != m.Dimensions[1] {
 		return nil, fmt.Errorf("Matrix must be square")
 	}
 
-	L, U, pivots, _, err := LUDecomposeWithPivoting(m)
+	n := m.Dimensions[0]
+	factorProgress := func(done, _ int) { reportProgress(progress, done, 2*n) }
+	lu, err := LUFactorCtx(ctx, m, factorProgress)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("Invert: %w", ctx.Err())
+		}
 		return nil, err
 	}
 
-	inv, _ := New(make([]float64, n*n), []int{n, n})
-	e := make([]float64, n)
-	y := make([]float64, n)
-	x := make([]float64, n)
-
-	// Solve A * x = e for each column e of the identity matrix
-	for col := 0; col < n; col++ {
-		for i := 0; i < n; i++ {
-			e[i], y[i], x[i] = 0, 0, 0
-		}
-		e[pivots[col]] = 1.0
-
-		// Forward substitution: L * y = e
-		for i := 0; i < n; i++ {
-			sum := 0.0
-			for j := 0; j < i; j++ {
-				sum += mustGet(L, i, j) * y[j]
-			}
-			y[i] = e[i] - sum
-		}
-
-		// Backward substitution: U * x = y
-		for i := n - 1; i >= 0; i-- {
-			sum := 0.0
-			for j := i + 1; j < n; j++ {
-				sum += mustGet(U, i, j) * x[j]
-			}
-			x[i] = (y[i] - sum) / mustGet(U, i, i)
-		}
-
-		// Store result column-wise
-		for row := 0; row < n; row++ {
-			mustSet(x[row], inv, row, col)
+	solveProgress := func(done, _ int) { reportProgress(progress, n+done, 2*n) }
+	inv, err = lu.InverseCtx(ctx, solveProgress)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("Invert: %w", ctx.Err())
 		}
+		return nil, err
 	}
-
 	return inv, nil
 }
 
 // IsInvertible checks whether a matrix is invertible by evaluating its determinant.
-func IsInvertible(m *Matx) (bool, error) {
+func IsInvertible(m *Matx) (_ bool, err error) {
+	if span := startTrace("IsInvertible", m); span != nil {
+		defer func() { span.end(0, 0, err) }()
+	}
+
 	if m == nil {
 		return false, fmt.Errorf("Nil matrix passed")
 	}
@@ -109,78 +103,22 @@ func IsInvertible(m *Matx) (bool, error) {
 
 // LUDecomposeWithPivoting performs LU decomposition with partial pivoting.
 // Returns L, U, pivot indices, number of row swaps, or error if the matrix is singular or not square.
+// Kept for compatibility; LUFactor returns the packed factors without the extra copies.
 func LUDecomposeWithPivoting(orig *Matx) (*Matx, *Matx, []int, int, error) {
-	n := orig.Dimensions[0]
-	if n != orig.Dimensions[1] {
-		return nil, nil, nil, 0, fmt.Errorf("Matrix must be square")
-	}
-
-	// Make a copy of the original matrix
-	A := &Matx{
-		Data:       append([]float64(nil), orig.Data...),
-		Dimensions: []int{n, n},
-	}
-
-	L, _ := New(make([]float64, n*n), []int{n, n})
-	U, _ := New(make([]float64, n*n), []int{n, n})
-	pivots := make([]int, n)
-	for i := range pivots {
-		pivots[i] = i
-	}
-
-	swapCount := 0
-
-	for i := 0; i < n; i++ {
-		// Find pivot row for column i
-		maxIdx := i
-		maxVal := math.Abs(mustGet(A, i, i))
-		for k := i + 1; k < n; k++ {
-			if v := math.Abs(mustGet(A, k, i)); v > maxVal {
-				maxVal = v
-				maxIdx = k
-			}
-		}
-		if maxVal == 0 {
-			return nil, nil, nil, 0, fmt.Errorf("Matrix is singular")
-		}
-
-		// Swap rows if needed
-		if maxIdx != i {
-			if err := RowSwap(A, i, maxIdx); err != nil {
-				return nil, nil, nil, 0, err
-			}
-			pivots[i], pivots[maxIdx] = pivots[maxIdx], pivots[i]
-			swapCount++
-		}
-
-		// Compute U
-		for j := i; j < n; j++ {
-			sum := 0.0
-			for k := 0; k < i; k++ {
-				sum += mustGet(L, i, k) * mustGet(U, k, j)
-			}
-			mustSet(mustGet(A, i, j)-sum, U, i, j)
-		}
-
-		// Compute L
-		for j := i; j < n; j++ {
-			if i == j {
-				mustSet(1.0, L, i, i)
-			} else {
-				sum := 0.0
-				for k := 0; k < i; k++ {
-					sum += mustGet(L, j, k) * mustGet(U, k, i)
-				}
-				mustSet((mustGet(A, j, i)-sum)/mustGet(U, i, i), L, j, i)
-			}
-		}
+	lu, err := LUFactor(orig)
+	if err != nil {
+		return nil, nil, nil, 0, err
 	}
 
-	return L, U, pivots, swapCount, nil
+	return lu.L(), lu.U(), lu.Pivots, lu.Swaps, nil
 }
 
 // IsSymmetric checks whether a 2D square matrix is symmetric.
-func IsSymmetric(m *Matx) (bool, error) {
+func IsSymmetric(m *Matx) (_ bool, err error) {
+	if span := startTrace("IsSymmetric", m); span != nil {
+		defer func() { span.end(0, 0, err) }()
+	}
+
 	if m == nil {
 		return false, fmt.Errorf("Nil matrix passed")
 	}
@@ -211,7 +149,11 @@ func IsSymmetric(m *Matx) (bool, error) {
 
 // Dot computes the dot product of two 1D vectors.
 // Returns an error if dimensions do not match or inputs are not vectors.
-func Dot(m1 *Matx, m2 *Matx) (float64, error) {
+func Dot(m1 *Matx, m2 *Matx) (dot float64, err error) {
+	if span := startTrace("Dot", m1, m2); span != nil {
+		defer func() { span.end(2*elementCount(m1), 0, err) }()
+	}
+
 	if m1 == nil || m2 == nil {
 		return 0, fmt.Errorf("One or both matrices passed are nil")
 	}
@@ -234,7 +176,11 @@ func Dot(m1 *Matx, m2 *Matx) (float64, error) {
 
 // Transpose returns the transpose of a 2D matrix.
 // Rows become columns and vice versa.
-func Transpose(m *Matx) (*Matx, error) {
+func Transpose(m *Matx) (transposed *Matx, err error) {
+	if span := startTrace("Transpose", m); span != nil {
+		defer func() { span.end(0, 8*elementCount(transposed), err) }()
+	}
+
 	if m == nil || m.Data == nil || len(m.Dimensions) != 2 {
 		return nil, fmt.Errorf("invalid matrix for transpose")
 	}
@@ -260,7 +206,11 @@ func Transpose(m *Matx) (*Matx, error) {
 
 // RowSwap swaps two rows in a 2D matrix.
 // Returns an error if input is invalid or indices are out of bounds.
-func RowSwap(m *Matx, row1, row2 int) error {
+func RowSwap(m *Matx, row1, row2 int) (err error) {
+	if span := startTrace("RowSwap", m); span != nil {
+		defer func() { span.end(0, 0, err) }()
+	}
+
 	if m == nil {
 		return fmt.Errorf("Matrix is nil")
 	}
@@ -286,6 +236,19 @@ func RowSwap(m *Matx, row1, row2 int) error {
 // Multiply performs matrix multiplication between two 2D matrices.
 // Returns the result matrix or an error if dimensions are incompatible.
 func Multiply(m1, m2 *Matx) (*Matx, error) {
+	return MultiplyCtx(context.Background(), m1, m2)
+}
+
+// multiplyCtxRows is the number of result rows computed between cancellation checks.
+const multiplyCtxRows = 64
+
+// MultiplyCtx is Multiply with cancellation. `ctx` is checked after every block of
+// result rows and progress is reported in rows of the result.
+func MultiplyCtx(ctx context.Context, m1, m2 *Matx, progress ...ProgressFunc) (result *Matx, err error) {
+	if span := startTrace("Multiply", m1, m2); span != nil {
+		defer func() { span.end(multiplyFlops(m1, m2), 8*elementCount(result), err) }()
+	}
+
 	if m1 == nil || m2 == nil {
 		return nil, fmt.Errorf("one or both input matrices are nil")
 	}
@@ -301,30 +264,141 @@ func Multiply(m1, m2 *Matx) (*Matx, error) {
 	resultCols := m2.Dimensions[1]
 	resultData := make([]float64, resultRows*resultCols)
 
-	result, err := New(resultData, []int{resultRows, resultCols})
+	result, err = New(resultData, []int{resultRows, resultCols})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create result matrix: %w", err)
 	}
 
-	// Standard matrix multiplication
-	for i := 0; i < resultRows; i++ {
-		for j := 0; j < resultCols; j++ {
-			sum := 0.0
-			for k := 0; k < m1.Dimensions[1]; k++ {
-				a := m1.Data[i*m1.Dimensions[1]+k]
-				b := m2.Data[k*m2.Dimensions[1]+j]
-				sum += a * b
+	// Standard matrix multiplication, one block of rows at a time
+	for i0 := 0; i0 < resultRows; i0 += multiplyCtxRows {
+		if err := checkCtx(ctx, "Multiply"); err != nil {
+			return nil, err
+		}
+
+		i1 := min(i0+multiplyCtxRows, resultRows)
+		for i := i0; i < i1; i++ {
+			for j := 0; j < resultCols; j++ {
+				sum := 0.0
+				for k := 0; k < m1.Dimensions[1]; k++ {
+					a := m1.Data[i*m1.Dimensions[1]+k]
+					b := m2.Data[k*m2.Dimensions[1]+j]
+					sum += a * b
+				}
+				result.Data[i*resultCols+j] = sum
 			}
-			result.Data[i*resultCols+j] = sum
 		}
+		reportProgress(progress, i1, resultRows)
 	}
 
 	return result, nil
 }
 
+// MultiplyTiled multiplies two 2D matrices by walking them in tile×tile blocks.
+// Only three tiles are hot at any time, which keeps the working set small when the
+// operands are memory-mapped (see MappedMatx) and pages are streamed from disk.
+// A non-positive `tile` selects a default of 256.
+func MultiplyTiled(m1, m2 *Matx, tile int) (result *Matx, err error) {
+	if span := startTrace("MultiplyTiled", m1, m2); span != nil {
+		// The product itself reports a MultiplyTiledInto event
+		defer func() { span.end(0, 8*elementCount(result), err) }()
+	}
+
+	if m1 == nil || m2 == nil {
+		return nil, fmt.Errorf("one or both input matrices are nil")
+	}
+	if len(m1.Dimensions) != 2 || len(m2.Dimensions) != 2 {
+		return nil, fmt.Errorf("tiled multiplication only supports 2D matrices")
+	}
+
+	result, err = Zeros([]int{m1.Dimensions[0], m2.Dimensions[1]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create result matrix: %w", err)
+	}
+	if err := MultiplyTiledInto(result, m1, m2, tile); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MultiplyTiledInto computes m1×m2 tile by tile and stores it in `out`, which must be
+// preallocated with shape rows(m1)×cols(m2). `out` may itself be a mapped matrix,
+// so the whole product can be larger than memory. Existing contents of `out` are overwritten,
+// so it must not share storage with m1 or m2.
+func MultiplyTiledInto(out, m1, m2 *Matx, tile int) error {
+	return MultiplyTiledIntoCtx(context.Background(), out, m1, m2, tile)
+}
+
+// MultiplyTiledIntoCtx is MultiplyTiledInto with cancellation, checked after every
+// row of tiles. Progress is reported in rows of `out`.
+func MultiplyTiledIntoCtx(ctx context.Context, out, m1, m2 *Matx, tile int, progress ...ProgressFunc) (err error) {
+	if span := startTrace("MultiplyTiledInto", m1, m2); span != nil {
+		defer func() { span.end(multiplyFlops(m1, m2), 0, err) }()
+	}
+
+	if out == nil || m1 == nil || m2 == nil {
+		return fmt.Errorf("one or more matrices are nil")
+	}
+	if len(m1.Dimensions) != 2 || len(m2.Dimensions) != 2 || len(out.Dimensions) != 2 {
+		return fmt.Errorf("tiled multiplication only supports 2D matrices")
+	}
+	if !CheckMultiplicationCondition(m1.Dimensions, m2.Dimensions) {
+		return fmt.Errorf(
+			"multiplication not possible: m1 columns (%d) != m2 rows (%d)",
+			m1.Dimensions[1], m2.Dimensions[0],
+		)
+	}
+
+	rows, inner, cols := m1.Dimensions[0], m1.Dimensions[1], m2.Dimensions[1]
+	if out.Dimensions[0] != rows || out.Dimensions[1] != cols {
+		return fmt.Errorf("output shape %v does not match product shape [%d %d]", out.Dimensions, rows, cols)
+	}
+	if overlaps(out.Data, m1.Data) || overlaps(out.Data, m2.Data) {
+		return fmt.Errorf("output matrix shares storage with an input")
+	}
+	if tile <= 0 {
+		tile = 256
+	}
+
+	for i := range out.Data {
+		out.Data[i] = 0
+	}
+
+	// i-k-j ordering inside each tile keeps the innermost loop on contiguous rows
+	for i0 := 0; i0 < rows; i0 += tile {
+		if err := checkCtx(ctx, "MultiplyTiled"); err != nil {
+			return err
+		}
+
+		i1 := min(i0+tile, rows)
+		for k0 := 0; k0 < inner; k0 += tile {
+			k1 := min(k0+tile, inner)
+			for j0 := 0; j0 < cols; j0 += tile {
+				j1 := min(j0+tile, cols)
+				for i := i0; i < i1; i++ {
+					outRow := out.Data[i*cols : (i+1)*cols]
+					for k := k0; k < k1; k++ {
+						a := m1.Data[i*inner+k]
+						bRow := m2.Data[k*cols : (k+1)*cols]
+						for j := j0; j < j1; j++ {
+							outRow[j] += a * bRow[j]
+						}
+					}
+				}
+			}
+		}
+		reportProgress(progress, i1, rows)
+	}
+
+	return nil
+}
+
 // Hadamard performs element wise multiplication on any 2 N-dimensional matrices
 // Returns pointer to the result matrix
-func Hadamard(m1, m2 *Matx) (*Matx, error) {
+func Hadamard(m1, m2 *Matx) (result *Matx, err error) {
+	if span := startTrace("Hadamard", m1, m2); span != nil {
+		defer func() { span.end(elementCount(result), 8*elementCount(result), err) }()
+	}
+
 	if m1 == nil || m2 == nil {
 		return nil, fmt.Errorf("one or both matrices are nil")
 	}
@@ -340,7 +414,7 @@ func Hadamard(m1, m2 *Matx) (*Matx, error) {
 		resultData[i] = m1.Data[i] * m2.Data[i]
 	}
 
-	result, err := New(resultData, m1.Dimensions)
+	result, err = New(resultData, m1.Dimensions)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create result matrix for hadamard")
@@ -351,7 +425,11 @@ func Hadamard(m1, m2 *Matx) (*Matx, error) {
 }
 
 // Flattens the matrix by changeing the dimensions attribute
-func (m *Matx) Flatten() error {
+func (m *Matx) Flatten() (err error) {
+	if span := startTrace("Flatten", m); span != nil {
+		defer func() { span.end(0, 0, err) }()
+	}
+
 	if m == nil {
 		return fmt.Errorf("nil matrix passed")
 	}
@@ -0,0 +1,279 @@
+package matx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// defaultLUBlock is the panel width used by LUFactor.
+const defaultLUBlock = 64
+
+// errSingular is returned by the LU factorizations for an exactly singular matrix.
+var errSingular = errors.New("Matrix is singular")
+
+// LU is a packed LU factorization with partial pivoting, PA = LU.
+//   - Factors holds U on and above the diagonal and the unit lower triangular L
+//     strictly below it, in the same row-major layout as any Matx.
+//   - Pivots[i] is the row of the original matrix that ended up in row i.
+//   - Swaps counts row interchanges, which fixes the sign of the determinant.
+type LU struct {
+	Factors *Matx
+	Pivots  []int
+	Swaps   int
+}
+
+// LUFactor computes the LU factorization of a square matrix without modifying it.
+// Returns an error if the matrix is nil, not square, or exactly singular.
+func LUFactor(m *Matx) (*LU, error) {
+	return LUFactorCtx(context.Background(), m)
+}
+
+// LUFactorCtx is LUFactor with cancellation, checked before every panel.
+// Progress is reported in factored columns.
+func LUFactorCtx(ctx context.Context, m *Matx, progress ...ProgressFunc) (*LU, error) {
+	if m == nil {
+		return nil, fmt.Errorf("Nil matrix passed")
+	}
+
+	work, err := Clone(m)
+	if err != nil {
+		return nil, err
+	}
+	return LUFactorInPlaceCtx(ctx, work, defaultLUBlock, progress...)
+}
+
+// LUFactorInPlace overwrites `m` with its packed LU factors using a right-looking
+// blocked algorithm: each panel of `blockSize` columns is factored unblocked, the
+// matching block row of U is found by a triangular solve, and the trailing
+// submatrix receives a single rank-`blockSize` update. A non-positive blockSize
+// selects the default. The returned LU shares m as its Factors.
+func LUFactorInPlace(m *Matx, blockSize int) (*LU, error) {
+	return LUFactorInPlaceCtx(context.Background(), m, blockSize)
+}
+
+// LUFactorInPlaceCtx is LUFactorInPlace with cancellation, checked before every panel.
+// On cancellation `m` is left partially factored. Progress is reported in factored columns.
+func LUFactorInPlaceCtx(ctx context.Context, m *Matx, blockSize int, progress ...ProgressFunc) (result *LU, err error) {
+	if span := startTrace("LUFactor", m); span != nil {
+		defer func() { span.end(luFlops(m), 8*squareOrder(m), err) }()
+	}
+
+	if m == nil {
+		return nil, fmt.Errorf("Nil matrix passed")
+	}
+	if len(m.Dimensions) != 2 || m.Dimensions[0] != m.Dimensions[1] {
+		return nil, fmt.Errorf("Matrix must be square")
+	}
+	if blockSize <= 0 {
+		blockSize = defaultLUBlock
+	}
+
+	n := m.Dimensions[0]
+	a := m.Data
+	pivots := make([]int, n)
+	for i := range pivots {
+		pivots[i] = i
+	}
+	swaps := 0
+
+	for j0 := 0; j0 < n; j0 += blockSize {
+		if err := checkCtx(ctx, "LUFactor"); err != nil {
+			return nil, err
+		}
+
+		j1 := min(j0+blockSize, n)
+
+		// Panel factorization of columns [j0, j1) over rows [j0, n)
+		for k := j0; k < j1; k++ {
+			p := k
+			maxVal := math.Abs(a[k*n+k])
+			for i := k + 1; i < n; i++ {
+				if v := math.Abs(a[i*n+k]); v > maxVal {
+					maxVal, p = v, i
+				}
+			}
+			if maxVal == 0 {
+				return nil, errSingular
+			}
+
+			if p != k {
+				rowK, rowP := a[k*n:(k+1)*n], a[p*n:(p+1)*n]
+				for j := range rowK {
+					rowK[j], rowP[j] = rowP[j], rowK[j]
+				}
+				pivots[k], pivots[p] = pivots[p], pivots[k]
+				swaps++
+			}
+
+			pivot := a[k*n+k]
+			rowK := a[k*n+k+1 : k*n+j1]
+			for i := k + 1; i < n; i++ {
+				l := a[i*n+k] / pivot
+				a[i*n+k] = l
+				rowI := a[i*n+k+1 : i*n+j1]
+				for j, u := range rowK {
+					rowI[j] -= l * u
+				}
+			}
+		}
+
+		if j1 == n {
+			reportProgress(progress, n, n)
+			break
+		}
+
+		// U12 = L11^-1 A12 (unit lower triangular solve on the block row)
+		for k := j0; k < j1; k++ {
+			rowK := a[k*n+j1 : (k+1)*n]
+			for i := k + 1; i < j1; i++ {
+				l := a[i*n+k]
+				rowI := a[i*n+j1 : (i+1)*n]
+				for j, u := range rowK {
+					rowI[j] -= l * u
+				}
+			}
+		}
+
+		// A22 -= L21 U12, split across workers by row
+		trailing := n - j1
+		workers := 1
+		if trailing*trailing*(j1-j0) >= 1<<18 {
+			workers = 0
+		}
+		parallelFor(trailing, workers, func(lo, hi int) {
+			for i := j1 + lo; i < j1+hi; i++ {
+				rowI := a[i*n+j1 : (i+1)*n]
+				for k := j0; k < j1; k++ {
+					l := a[i*n+k]
+					rowK := a[k*n+j1 : (k+1)*n]
+					for j, u := range rowK {
+						rowI[j] -= l * u
+					}
+				}
+			}
+		})
+		reportProgress(progress, j1, n)
+	}
+
+	return &LU{Factors: m, Pivots: pivots, Swaps: swaps}, nil
+}
+
+// Det returns the determinant of the factored matrix.
+func (lu *LU) Det() float64 {
+	n := lu.Factors.Dimensions[0]
+	det := 1.0
+	for i := 0; i < n; i++ {
+		det *= lu.Factors.Data[i*n+i]
+	}
+	if lu.Swaps%2 != 0 {
+		det = -det
+	}
+	return det
+}
+
+// Solve returns x such that A x = b for the factored matrix A.
+// Returns an error if len(b) does not match the matrix order.
+func (lu *LU) Solve(b []float64) ([]float64, error) {
+	n := lu.Factors.Dimensions[0]
+	if len(b) != n {
+		return nil, fmt.Errorf("right-hand side length %d does not match matrix order %d", len(b), n)
+	}
+
+	x := make([]float64, n)
+	lu.solveInto(x, b)
+	return x, nil
+}
+
+// solveInto writes the solution of A x = b into x, which must have the matrix order.
+func (lu *LU) solveInto(x, b []float64) {
+	n := lu.Factors.Dimensions[0]
+	a := lu.Factors.Data
+
+	// Forward substitution: L y = P b
+	for i := 0; i < n; i++ {
+		sum := b[lu.Pivots[i]]
+		row := a[i*n : i*n+i]
+		for j, l := range row {
+			sum -= l * x[j]
+		}
+		x[i] = sum
+	}
+
+	// Backward substitution: U x = y
+	for i := n - 1; i >= 0; i-- {
+		sum := x[i]
+		row := a[i*n+i+1 : (i+1)*n]
+		for j, u := range row {
+			sum -= u * x[i+1+j]
+		}
+		x[i] = sum / a[i*n+i]
+	}
+}
+
+// Inverse returns the inverse of the factored matrix, solving one column at a time.
+func (lu *LU) Inverse() (*Matx, error) {
+	return lu.InverseCtx(context.Background())
+}
+
+// inverseCtxCols is the number of inverse columns solved between cancellation checks.
+const inverseCtxCols = 64
+
+// InverseCtx is Inverse with cancellation, checked between blocks of solved columns.
+// Progress is reported in solved columns.
+func (lu *LU) InverseCtx(ctx context.Context, progress ...ProgressFunc) (*Matx, error) {
+	n := lu.Factors.Dimensions[0]
+	inv, err := Zeros([]int{n, n})
+	if err != nil {
+		return nil, err
+	}
+
+	workers := 1
+	if n >= 128 {
+		workers = 0
+	}
+	for c0 := 0; c0 < n; c0 += inverseCtxCols {
+		if err := checkCtx(ctx, "Inverse"); err != nil {
+			return nil, err
+		}
+
+		c1 := min(c0+inverseCtxCols, n)
+		parallelFor(c1-c0, workers, func(lo, hi int) {
+			e := make([]float64, n)
+			x := make([]float64, n)
+			for col := c0 + lo; col < c0+hi; col++ {
+				e[col] = 1
+				lu.solveInto(x, e)
+				e[col] = 0
+				for row := 0; row < n; row++ {
+					inv.Data[row*n+col] = x[row]
+				}
+			}
+		})
+		reportProgress(progress, c1, n)
+	}
+
+	return inv, nil
+}
+
+// L unpacks the unit lower triangular factor into a new matrix.
+func (lu *LU) L() *Matx {
+	n := lu.Factors.Dimensions[0]
+	l, _ := Zeros([]int{n, n})
+	for i := 0; i < n; i++ {
+		copy(l.Data[i*n:i*n+i], lu.Factors.Data[i*n:i*n+i])
+		l.Data[i*n+i] = 1
+	}
+	return l
+}
+
+// U unpacks the upper triangular factor into a new matrix.
+func (lu *LU) U() *Matx {
+	n := lu.Factors.Dimensions[0]
+	u, _ := Zeros([]int{n, n})
+	for i := 0; i < n; i++ {
+		copy(u.Data[i*n+i:(i+1)*n], lu.Factors.Data[i*n+i:(i+1)*n])
+	}
+	return u
+}
@@ -0,0 +1,92 @@
+package matx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLU(t *testing.T) {
+	n := 1
+
+	{ // Blocked factorization reproduces PA
+		m := begin(t, n, "LUFactorInPlace() PA = LU")
+		n++
+		a, _ := Rand(70, 70, -1, 1)
+		ok := true
+		for _, bs := range []int{1, 7, 64, 128} {
+			work, _ := Clone(a)
+			lu, err := LUFactorInPlace(work, bs)
+			if err != nil {
+				ok = false
+				break
+			}
+			prod, _ := Multiply(lu.L(), lu.U())
+			for i := 0; i < 70 && ok; i++ {
+				for j := 0; j < 70; j++ {
+					if math.Abs(prod.Data[i*70+j]-a.Data[lu.Pivots[i]*70+j]) > 1e-9 {
+						ok = false
+						break
+					}
+				}
+			}
+		}
+		m.end(ok)
+	}
+
+	{ // Invert with a cyclic permutation
+		m := begin(t, n, "Invert() cyclic permutation")
+		n++
+		p, _ := New([]float64{0, 1, 0, 0, 0, 1, 1, 0, 0}, []int{3, 3})
+		inv, err := Invert(p)
+		want, _ := Transpose(p)
+		ok := err == nil
+		for i := range want.Data {
+			if ok && math.Abs(inv.Data[i]-want.Data[i]) > 1e-12 {
+				ok = false
+			}
+		}
+		m.end(ok)
+	}
+
+	{ // Solve and Det
+		m := begin(t, n, "LU.Solve() and LU.Det()")
+		n++
+		a, _ := New([]float64{2, 1, 1, 4, -6, 0, -2, 7, 2}, []int{3, 3})
+		lu, err := LUFactor(a)
+		ok := err == nil
+		if ok {
+			x, err := lu.Solve([]float64{5, -2, 9})
+			ok = err == nil &&
+				math.Abs(x[0]-1) < 1e-12 && math.Abs(x[1]-1) < 1e-12 && math.Abs(x[2]-2) < 1e-12 &&
+				math.Abs(lu.Det()+16) < 1e-12
+		}
+		m.end(ok)
+	}
+
+	{ // Singular matrices have zero determinant
+		m := begin(t, n, "Det() of singular matrix")
+		n++
+		s, _ := New([]float64{1, 2, 2, 4}, []int{2, 2})
+		d, err := Det(s)
+		inv, _ := IsInvertible(s)
+		zero, _ := Zeros([]int{3, 3})
+		dz, errZero := Det(zero)
+		_, errLU := LUFactor(zero)
+		m.end(err == nil && d == 0 && !inv && errZero == nil && dz == 0 && errLU != nil && errLU.Error() == "Matrix is singular")
+	}
+
+	{ // NaN and Inf reach the factors through zero multipliers, as 0·Inf is NaN
+		m := begin(t, n, "Det()/Invert() propagate NaN and Inf")
+		n++
+		a, _ := New([]float64{1, math.Inf(1), 0, 1}, []int{2, 2})
+		d, errDet := Det(a)
+		b, _ := New([]float64{2, math.NaN(), 0, 3}, []int{2, 2})
+		inv, errInv := Invert(b)
+		ok := errDet == nil && math.IsNaN(d) && errInv == nil && math.IsNaN(inv.Data[0]) && math.IsNaN(inv.Data[3])
+		// Larger than one panel, so the block row solve and trailing update run too
+		big, _ := Identity(100, 100)
+		big.Data[99] = math.Inf(1)
+		dBig, errBig := Det(big)
+		m.end(ok && errBig == nil && math.IsNaN(dBig))
+	}
+}
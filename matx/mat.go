@@ -0,0 +1,548 @@
+package matx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// MatClass is the MATLAB class of a numeric array in a MAT-file.
+type MatClass uint8
+
+// Numeric MATLAB classes, numbered as in the MAT-file format.
+const (
+	MatDouble MatClass = 6 + iota
+	MatSingle
+	MatInt8
+	MatUint8
+	MatInt16
+	MatUint16
+	MatInt32
+	MatUint32
+	MatInt64
+	MatUint64
+)
+
+// String returns the MATLAB name of the class.
+func (c MatClass) String() string {
+	names := []string{"double", "single", "int8", "uint8", "int16", "uint16", "int32", "uint32", "int64", "uint64"}
+	if c < MatDouble || c > MatUint64 {
+		return fmt.Sprintf("MatClass(%d)", uint8(c))
+	}
+	return names[c-MatDouble]
+}
+
+// MAT-file data types (miINT8 ... miUTF32).
+const (
+	miINT8       = 1
+	miUINT8      = 2
+	miINT16      = 3
+	miUINT16     = 4
+	miINT32      = 5
+	miUINT32     = 6
+	miSINGLE     = 7
+	miDOUBLE     = 9
+	miINT64      = 12
+	miUINT64     = 13
+	miMATRIX     = 14
+	miCOMPRESSED = 15
+	miUTF8       = 16
+)
+
+// Array flag bits of a miMATRIX element.
+const (
+	matComplex = 0x08
+	matLogical = 0x02
+)
+
+// matStorage maps each class to the data type it is written with.
+var matStorage = map[MatClass]uint32{
+	MatDouble: miDOUBLE, MatSingle: miSINGLE,
+	MatInt8: miINT8, MatUint8: miUINT8, MatInt16: miINT16, MatUint16: miUINT16,
+	MatInt32: miINT32, MatUint32: miUINT32, MatInt64: miINT64, MatUint64: miUINT64,
+}
+
+// MatVariable is a named numeric array of a MAT-file.
+//   - Class is the MATLAB class; the zero value writes double.
+//   - Real holds the real part, Imag the imaginary part of complex arrays and nil otherwise;
+//     both have the shape of the MATLAB array, which has at least two dimensions.
+//   - Logical marks a logical array, stored as uint8 zeros and ones.
+type MatVariable struct {
+	Name    string
+	Class   MatClass
+	Real    *Matx
+	Imag    *Matx
+	Logical bool
+}
+
+// MatOptions configures WriteMat.
+//   - Compress stores each variable zlib-compressed, as MATLAB's save does by default.
+type MatOptions struct {
+	Compress bool
+}
+
+// ReadMat reads the numeric arrays of a MAT-file (Level 5, as written by MATLAB
+// versions 5 to 7.x and by save -v7), in file order. Elements may be compressed and
+// either byte order is accepted. Cell arrays, structs, character and sparse arrays
+// are skipped. Values are converted to float64, so 64-bit integers beyond 2^53
+// lose precision; MATLAB's column-major data becomes row-major Data.
+func ReadMat(r io.Reader) ([]MatVariable, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, 128)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("mat: failed to read header: %w", err)
+	}
+	var order binary.ByteOrder
+	switch string(header[126:128]) {
+	case "IM":
+		order = binary.LittleEndian
+	case "MI":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("mat: not a Level 5 MAT-file (endian indicator %q)", header[126:128])
+	}
+	if v := order.Uint16(header[124:]); v != 0x0100 {
+		return nil, fmt.Errorf("mat: unsupported MAT-file version %#04x", v)
+	}
+
+	p := matParser{order: order}
+	var vars []MatVariable
+	for {
+		typ, data, err := p.element(br, true)
+		if err == io.EOF {
+			return vars, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if typ == miCOMPRESSED {
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("mat: corrupt compressed element: %w", err)
+			}
+			zbr := bufio.NewReader(zr)
+			if typ, data, err = p.element(zbr, false); err != nil {
+				zr.Close()
+				return nil, err
+			}
+			// Read to the end so that the zlib checksum is verified
+			_, err = io.Copy(io.Discard, zbr)
+			zr.Close()
+			if err != nil {
+				return nil, fmt.Errorf("mat: corrupt compressed element: %w", err)
+			}
+		}
+		if typ != miMATRIX || len(data) == 0 {
+			continue
+		}
+		v, err := p.matrix(data)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			vars = append(vars, *v)
+		}
+	}
+}
+
+// matParser reads data elements in the byte order of one file.
+type matParser struct {
+	order binary.ByteOrder
+}
+
+// element reads one data element, returning its type and data. The 4-byte "small
+// element" form is expanded. Other elements are followed by padding to 8 bytes,
+// except top-level compressed ones; `top` reports a clean end of input as io.EOF.
+func (p matParser) element(r *bufio.Reader, top bool) (uint32, []byte, error) {
+	var tag [8]byte
+	if _, err := io.ReadFull(r, tag[:]); err != nil {
+		if err == io.EOF && top {
+			return 0, nil, io.EOF
+		}
+		return 0, nil, fmt.Errorf("mat: failed to read element tag: %w", noEOF(err))
+	}
+	first := p.order.Uint32(tag[:])
+	if n := first >> 16; n != 0 {
+		if n > 4 {
+			return 0, nil, fmt.Errorf("mat: small element of %d bytes", n)
+		}
+		return first & 0xffff, tag[4 : 4+n], nil
+	}
+	n := p.order.Uint32(tag[4:])
+	padded := int64(n)
+	if first != miCOMPRESSED {
+		padded = (padded + 7) &^ 7
+	}
+	data, err := readLimited(r, padded)
+	if err != nil {
+		return 0, nil, fmt.Errorf("mat: failed to read element of %d bytes: %w", n, noEOF(err))
+	}
+	return first, data[:n], nil
+}
+
+// readLimited reads exactly n bytes, growing the buffer as data arrives so that a
+// corrupt length cannot force a huge allocation up front.
+func readLimited(r io.Reader, n int64) ([]byte, error) {
+	var buf bytes.Buffer
+	got, err := io.CopyN(&buf, r, n)
+	if got < n && err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), err
+}
+
+// matrix parses the sub-elements of a miMATRIX element: array flags, dimensions,
+// name and the real and imaginary parts. Non-numeric classes return nil.
+func (p matParser) matrix(data []byte) (*MatVariable, error) {
+	r := bufio.NewReader(bytes.NewReader(data))
+	typ, flags, err := p.element(r, false)
+	if err != nil {
+		return nil, err
+	}
+	if typ != miUINT32 || len(flags) != 8 {
+		return nil, fmt.Errorf("mat: malformed array flags")
+	}
+	word := p.order.Uint32(flags)
+	class, bits := MatClass(word&0xff), byte(word>>8)
+	if _, numeric := matStorage[class]; !numeric {
+		return nil, nil
+	}
+
+	typ, raw, err := p.element(r, false)
+	if err != nil {
+		return nil, err
+	}
+	if typ != miINT32 || len(raw) < 8 || len(raw)%4 != 0 {
+		return nil, fmt.Errorf("mat: malformed dimensions")
+	}
+	dims := make([]int, len(raw)/4)
+	count := 1
+	for i := range dims {
+		d := int32(p.order.Uint32(raw[4*i:]))
+		if d < 0 || (d > 0 && count > math.MaxInt32/int(d)) {
+			return nil, fmt.Errorf("mat: invalid dimension %d", d)
+		}
+		dims[i] = int(d)
+		count *= dims[i]
+	}
+
+	typ, name, err := p.element(r, false)
+	if err != nil {
+		return nil, err
+	}
+	if typ != miINT8 && typ != miUTF8 {
+		return nil, fmt.Errorf("mat: malformed array name")
+	}
+	v := &MatVariable{Name: string(name), Class: class, Logical: bits&matLogical != 0}
+
+	part := func(which string) (*Matx, error) {
+		typ, raw, err := p.element(r, false)
+		if err != nil {
+			return nil, err
+		}
+		values, err := p.decode(typ, raw)
+		if err != nil {
+			return nil, fmt.Errorf("mat: variable %q: %w", v.Name, err)
+		}
+		if len(values) != count {
+			return nil, fmt.Errorf("mat: variable %q: %s part has %d values for dimensions %v", v.Name, which, len(values), dims)
+		}
+		return New(fortranToRowMajor(values, dims), append([]int{}, dims...))
+	}
+	if v.Real, err = part("real"); err != nil {
+		return nil, err
+	}
+	if bits&matComplex != 0 {
+		if v.Imag, err = part("imaginary"); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// decode converts numeric element data to float64. MATLAB may store an array in a
+// narrower type than its class when the values fit.
+func (p matParser) decode(typ uint32, raw []byte) ([]float64, error) {
+	var size int
+	var conv func([]byte) float64
+	o := p.order
+	switch typ {
+	case miDOUBLE:
+		size, conv = 8, func(b []byte) float64 { return math.Float64frombits(o.Uint64(b)) }
+	case miSINGLE:
+		size, conv = 4, func(b []byte) float64 { return float64(math.Float32frombits(o.Uint32(b))) }
+	case miINT8:
+		size, conv = 1, func(b []byte) float64 { return float64(int8(b[0])) }
+	case miUINT8:
+		size, conv = 1, func(b []byte) float64 { return float64(b[0]) }
+	case miINT16:
+		size, conv = 2, func(b []byte) float64 { return float64(int16(o.Uint16(b))) }
+	case miUINT16:
+		size, conv = 2, func(b []byte) float64 { return float64(o.Uint16(b)) }
+	case miINT32:
+		size, conv = 4, func(b []byte) float64 { return float64(int32(o.Uint32(b))) }
+	case miUINT32:
+		size, conv = 4, func(b []byte) float64 { return float64(o.Uint32(b)) }
+	case miINT64:
+		size, conv = 8, func(b []byte) float64 { return float64(int64(o.Uint64(b))) }
+	case miUINT64:
+		size, conv = 8, func(b []byte) float64 { return float64(o.Uint64(b)) }
+	default:
+		return nil, fmt.Errorf("unsupported numeric data type %d", typ)
+	}
+	if len(raw)%size != 0 {
+		return nil, fmt.Errorf("%d bytes of data type %d", len(raw), typ)
+	}
+	values := make([]float64, len(raw)/size)
+	for i := range values {
+		values[i] = conv(raw[i*size:])
+	}
+	return values, nil
+}
+
+// WriteMat writes the variables as a little-endian Level 5 MAT-file that MATLAB
+// loads with load. 1D matrices are written as column vectors and 0-d ones as 1x1.
+// Values are converted to each variable's class as MATLAB does: integers round to
+// nearest, saturate at the limits of the class, and NaN becomes 0.
+func WriteMat(w io.Writer, vars []MatVariable, opts MatOptions) error {
+	bw := bufio.NewWriter(w)
+	text := "MATLAB 5.0 MAT-file, written by goten-lah matx"
+	header := []byte(text + strings.Repeat(" ", 116-len(text)))
+	header = append(header, make([]byte, 8)...) // no subsystem data
+	header = binary.LittleEndian.AppendUint16(header, 0x0100)
+	header = append(header, 'I', 'M')
+	bw.Write(header)
+
+	seen := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		if !validMatName(v.Name) {
+			return fmt.Errorf("mat: invalid variable name %q", v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("mat: duplicate variable name %q", v.Name)
+		}
+		seen[v.Name] = true
+		element, err := encodeMatVariable(v)
+		if err != nil {
+			return err
+		}
+		if opts.Compress {
+			var z bytes.Buffer
+			zw := zlib.NewWriter(&z)
+			zw.Write(element)
+			zw.Close()
+			// Compressed elements are not padded
+			element = binary.LittleEndian.AppendUint32(nil, miCOMPRESSED)
+			element = binary.LittleEndian.AppendUint32(element, uint32(z.Len()))
+			element = append(element, z.Bytes()...)
+		}
+		bw.Write(element)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("mat: write failed: %w", err)
+	}
+	return nil
+}
+
+// validMatName reports whether `name` is a valid MATLAB variable name.
+func validMatName(name string) bool {
+	if name == "" || len(name) > 63 {
+		return false
+	}
+	for i, c := range name {
+		letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if !letter && (i == 0 || c != '_' && (c < '0' || c > '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeMatVariable returns the miMATRIX element of `v`.
+func encodeMatVariable(v MatVariable) ([]byte, error) {
+	if v.Real == nil || v.Real.Data == nil || v.Real.Dimensions == nil {
+		return nil, fmt.Errorf("mat: variable %q is nil", v.Name)
+	}
+	class := v.Class
+	if class == 0 {
+		class = MatDouble
+	}
+	if v.Logical {
+		class = MatUint8
+	}
+	storage, ok := matStorage[class]
+	if !ok {
+		return nil, fmt.Errorf("mat: variable %q: unsupported class %v", v.Name, class)
+	}
+	if v.Imag != nil && (v.Logical || !CheckDimensionEquality(v.Imag.Dimensions, v.Real.Dimensions) || len(v.Imag.Data) != len(v.Real.Data)) {
+		return nil, fmt.Errorf("mat: variable %q: imaginary part must be a non-logical array of shape %v", v.Name, v.Real.Dimensions)
+	}
+
+	dims := append([]int{}, v.Real.Dimensions...)
+	switch len(dims) {
+	case 0:
+		dims = []int{1, 1}
+	case 1:
+		dims = append(dims, 1)
+	}
+	count := 1
+	for _, d := range dims {
+		if d > math.MaxInt32 {
+			return nil, fmt.Errorf("mat: variable %q: dimension %d too large", v.Name, d)
+		}
+		count *= d
+	}
+	if count != len(v.Real.Data) {
+		return nil, fmt.Errorf("mat: variable %q: data size %d does not match shape %v", v.Name, len(v.Real.Data), v.Real.Dimensions)
+	}
+
+	flags := make([]byte, 8)
+	flags[0] = byte(class)
+	if v.Imag != nil {
+		flags[1] |= matComplex
+	}
+	if v.Logical {
+		flags[1] |= matLogical
+	}
+	rawDims := make([]byte, 0, 4*len(dims))
+	for _, d := range dims {
+		rawDims = binary.LittleEndian.AppendUint32(rawDims, uint32(d))
+	}
+
+	body := appendMatElement(nil, miUINT32, flags)
+	body = appendMatElement(body, miINT32, rawDims)
+	body = appendMatElement(body, miINT8, []byte(v.Name))
+	body = appendMatElement(body, storage, encodeMatValues(fortranOrder(v.Real.Data, dims), class, v.Logical))
+	if v.Imag != nil {
+		body = appendMatElement(body, storage, encodeMatValues(fortranOrder(v.Imag.Data, dims), class, false))
+	}
+	return appendMatElement(nil, miMATRIX, body), nil
+}
+
+// appendMatElement appends a tagged data element padded to 8 bytes, using the
+// small element form for up to 4 bytes of data as MATLAB does.
+func appendMatElement(buf []byte, typ uint32, data []byte) []byte {
+	if len(data) <= 4 && len(data) > 0 && typ != miMATRIX {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(data))<<16|typ)
+		buf = append(buf, data...)
+		return append(buf, make([]byte, 4-len(data))...)
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, typ)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(data)))
+	buf = append(buf, data...)
+	return append(buf, make([]byte, (8-len(data)%8)%8)...)
+}
+
+// fortranOrder reorders row-major data of shape `dims` into column-major order; it
+// is the inverse of fortranToRowMajor.
+func fortranOrder(data []float64, dims []int) []float64 {
+	out := make([]float64, len(data))
+	strides := make([]int, len(dims))
+	stride := 1
+	for k, d := range dims {
+		strides[k] = stride
+		stride *= d
+	}
+	idx := make([]int, len(dims))
+	for _, v := range data {
+		dst := 0
+		for k, i := range idx {
+			dst += i * strides[k]
+		}
+		out[dst] = v
+		for k := len(idx) - 1; k >= 0; k-- {
+			idx[k]++
+			if idx[k] < dims[k] {
+				break
+			}
+			idx[k] = 0
+		}
+	}
+	return out
+}
+
+// encodeMatValues converts values to the little-endian storage type of `class`.
+func encodeMatValues(values []float64, class MatClass, logical bool) []byte {
+	var out []byte
+	for _, v := range values {
+		if logical && v != 0 {
+			v = 1
+		}
+		switch class {
+		case MatDouble:
+			out = binary.LittleEndian.AppendUint64(out, math.Float64bits(v))
+		case MatSingle:
+			out = binary.LittleEndian.AppendUint32(out, math.Float32bits(float32(v)))
+		case MatInt8:
+			out = append(out, byte(int8(matSaturate(v, math.MinInt8, math.MaxInt8))))
+		case MatUint8:
+			out = append(out, byte(matSaturate(v, 0, math.MaxUint8)))
+		case MatInt16:
+			out = binary.LittleEndian.AppendUint16(out, uint16(int16(matSaturate(v, math.MinInt16, math.MaxInt16))))
+		case MatUint16:
+			out = binary.LittleEndian.AppendUint16(out, uint16(matSaturate(v, 0, math.MaxUint16)))
+		case MatInt32:
+			out = binary.LittleEndian.AppendUint32(out, uint32(int32(matSaturate(v, math.MinInt32, math.MaxInt32))))
+		case MatUint32:
+			out = binary.LittleEndian.AppendUint32(out, uint32(matSaturate(v, 0, math.MaxUint32)))
+		case MatInt64:
+			r, i := math.Round(v), int64(0)
+			switch {
+			case r >= math.MaxInt64:
+				i = math.MaxInt64
+			case r <= math.MinInt64:
+				i = math.MinInt64
+			case !math.IsNaN(r):
+				i = int64(r)
+			}
+			out = binary.LittleEndian.AppendUint64(out, uint64(i))
+		case MatUint64:
+			r, u := math.Round(v), uint64(0)
+			switch {
+			case r >= math.MaxUint64:
+				u = math.MaxUint64
+			case r > 0:
+				u = uint64(r)
+			}
+			out = binary.LittleEndian.AppendUint64(out, u)
+		}
+	}
+	return out
+}
+
+// matSaturate rounds `v` half away from zero and clamps it to [lo, hi]; NaN is 0.
+func matSaturate(v, lo, hi float64) int64 {
+	if math.IsNaN(v) {
+		return 0
+	}
+	return int64(math.Max(lo, math.Min(hi, math.Round(v))))
+}
+
+// ReadMatFile opens `path` and reads it with ReadMat.
+func ReadMatFile(path string) ([]MatVariable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mat file: %w", err)
+	}
+	defer f.Close()
+	return ReadMat(f)
+}
+
+// WriteMatFile creates (or truncates) `path` and writes the variables to it with WriteMat.
+func WriteMatFile(path string, vars []MatVariable, opts MatOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create mat file: %w", err)
+	}
+	if err := WriteMat(f, vars, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,170 @@
+package matx
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// orderAppender is a byte order that can both append and decode.
+type orderAppender interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// matElem assembles a data element as MATLAB writes it, using the small element
+// form for up to 4 bytes.
+func matElem(order orderAppender, typ uint32, data []byte) []byte {
+	if len(data) > 0 && len(data) <= 4 {
+		out := order.AppendUint32(nil, uint32(len(data))<<16|typ)
+		return append(append(out, data...), make([]byte, 4-len(data))...)
+	}
+	out := order.AppendUint32(order.AppendUint32(nil, typ), uint32(len(data)))
+	return append(append(out, data...), make([]byte, (8-len(data)%8)%8)...)
+}
+
+// matArray assembles a miMATRIX element from its parts.
+func matArray(order orderAppender, class, flags byte, dims []int32, name string, parts ...[]byte) []byte {
+	word := order.AppendUint32(nil, uint32(flags)<<8|uint32(class))
+	body := matElem(order, miUINT32, append(word, 0, 0, 0, 0))
+	var rawDims []byte
+	for _, d := range dims {
+		rawDims = order.AppendUint32(rawDims, uint32(d))
+	}
+	body = append(body, matElem(order, miINT32, rawDims)...)
+	body = append(body, matElem(order, miINT8, []byte(name))...)
+	for _, p := range parts {
+		body = append(body, p...)
+	}
+	return matElem(order, miMATRIX, body)
+}
+
+// matHeader returns the 128-byte file header for `order`.
+func matHeader(order orderAppender) []byte {
+	header := append([]byte(strings.Repeat(" ", 116)), make([]byte, 8)...)
+	header = order.AppendUint16(header, 0x0100)
+	return order.AppendUint16(header, 'M'<<8|'I')
+}
+
+func TestMat(t *testing.T) {
+	n := 1
+
+	{ // Round trip of each class, complex parts and the shapes MATLAB uses
+		m := begin(t, n, "WriteMat()/ReadMat() round trip")
+		n++
+		a, _ := New([]float64{1, 2.5, -3, 1.0 / 3, math.Inf(1), math.NaN()}, []int{2, 3})
+		re, _ := New([]float64{1, 2, 3, 4}, []int{2, 2})
+		im, _ := New([]float64{-1, 0.5, 0, 8}, []int{2, 2})
+		ints, _ := New([]float64{1.5, -2.5, -40000, 40000, math.NaN(), 7, 0, 1, 2, 3, 4, 5}, []int{2, 3, 2})
+		vec, _ := New([]float64{1, 2, 3}, []int{3})
+		mask, _ := New([]float64{0, 3, 1}, []int{1, 3})
+		vars := []MatVariable{
+			{Name: "a", Real: a},
+			{Name: "z", Class: MatSingle, Real: re, Imag: im},
+			{Name: "counts_3d", Class: MatInt16, Real: ints},
+			{Name: "v", Real: vec},
+			{Name: "mask", Logical: true, Real: mask},
+		}
+		ok := true
+		for _, compress := range []bool{false, true} {
+			var buf bytes.Buffer
+			err := WriteMat(&buf, vars, MatOptions{Compress: compress})
+			got, err2 := ReadMat(&buf)
+			ok = ok && err == nil && err2 == nil && len(got) == 5 &&
+				got[0].Name == "a" && got[0].Class == MatDouble && got[0].Imag == nil &&
+				denseEqual(got[0].Real, a, 0) && math.IsNaN(got[0].Real.Data[5]) &&
+				got[1].Class == MatSingle && denseEqual(got[1].Real, re, 0) && denseEqual(got[1].Imag, im, 0) &&
+				got[2].Class == MatInt16 && CheckDimensionEquality(got[2].Real.Dimensions, []int{2, 3, 2}) &&
+				closeSlices(got[2].Real.Data, []float64{2, -3, -32768, 32767, 0, 7, 0, 1, 2, 3, 4, 5}, 0) &&
+				CheckDimensionEquality(got[3].Real.Dimensions, []int{3, 1}) && closeSlices(got[3].Real.Data, vec.Data, 0) &&
+				got[4].Logical && got[4].Class == MatUint8 && closeSlices(got[4].Real.Data, []float64{0, 1, 1}, 0)
+		}
+		m.end(ok && MatInt64.String() == "int64" && MatClass(2).String() == "MatClass(2)")
+	}
+
+	{ // Column-major data, compact storage types and big-endian files as MATLAB writes them
+		m := begin(t, n, "ReadMat() MATLAB layouts")
+		n++
+		be := binary.BigEndian
+		file := matHeader(be)
+		// A 2x3 double stored as miUINT8 because its values fit, column by column
+		file = append(file, matArray(be, byte(MatDouble), 0, []int32{2, 3}, "A",
+			matElem(be, miUINT8, []byte{1, 4, 2, 5, 3, 6}))...)
+		// A char array is skipped
+		file = append(file, matArray(be, 4, 0, []int32{1, 2}, "s", matElem(be, miUTF8, []byte("hi")))...)
+		// A complex int32 2x1 column with int8 and int16 parts
+		file = append(file, matArray(be, byte(MatInt32), matComplex, []int32{2, 1}, "zz",
+			matElem(be, miINT8, []byte{0xff, 2}), matElem(be, miINT16, be.AppendUint16(be.AppendUint16(nil, 3), 0xfffc)))...)
+		got, err := ReadMat(bytes.NewReader(file))
+
+		le := binary.LittleEndian
+		inner := matArray(le, byte(MatSingle), 0, []int32{1, 1}, "x", matElem(le, miSINGLE, le.AppendUint32(nil, math.Float32bits(0.5))))
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(inner)
+		zw.Close()
+		packed := append(matHeader(le), le.AppendUint32(le.AppendUint32(nil, miCOMPRESSED), uint32(z.Len()))...)
+		packed = append(packed, z.Bytes()...)
+		gotPacked, err2 := ReadMat(bytes.NewReader(packed))
+
+		want, _ := New([]float64{1, 2, 3, 4, 5, 6}, []int{2, 3})
+		m.end(err == nil && err2 == nil && len(got) == 2 && got[0].Name == "A" && denseEqual(got[0].Real, want, 0) &&
+			got[1].Name == "zz" && got[1].Class == MatInt32 && closeSlices(got[1].Real.Data, []float64{-1, 2}, 0) &&
+			closeSlices(got[1].Imag.Data, []float64{3, -4}, 0) &&
+			len(gotPacked) == 1 && gotPacked[0].Real.Data[0] == 0.5 && gotPacked[0].Class == MatSingle)
+	}
+
+	{ // Invalid variables and damaged files are rejected
+		m := begin(t, n, "WriteMat()/ReadMat() validation")
+		n++
+		a, _ := New([]float64{1, 2, 3, 4}, []int{2, 2})
+		wrong, _ := New([]float64{1, 2, 3, 4}, []int{4, 1})
+		bad := [][]MatVariable{
+			{{Name: "1abc", Real: a}},
+			{{Name: "has space", Real: a}},
+			{{Name: strings.Repeat("x", 64), Real: a}},
+			{{Name: "a", Real: a}, {Name: "a", Real: a}},
+			{{Name: "a", Real: a, Imag: wrong}},
+			{{Name: "a"}},
+			{{Name: "a", Class: MatClass(4), Real: a}},
+		}
+		ok := true
+		for i, vars := range bad {
+			if err := WriteMat(&bytes.Buffer{}, vars, MatOptions{}); err == nil {
+				t.Logf("case %d: accepted", i)
+				ok = false
+			}
+		}
+		var plain, packed bytes.Buffer
+		big := randomSparse(20, 20, 0.1)
+		WriteMat(&plain, []MatVariable{{Name: "big", Real: big}}, MatOptions{})
+		WriteMat(&packed, []MatVariable{{Name: "big", Real: big}}, MatOptions{Compress: true})
+		corrupt := append([]byte(nil), packed.Bytes()...)
+		corrupt[len(corrupt)-2] ^= 0xff
+		notMat := append([]byte(nil), plain.Bytes()...)
+		notMat[126] = 'X'
+		damaged := [][]byte{plain.Bytes()[:plain.Len()-9], corrupt, notMat, plain.Bytes()[:100]}
+		for i, file := range damaged {
+			if _, err := ReadMat(bytes.NewReader(file)); err == nil {
+				t.Logf("damaged file %d: accepted", i)
+				ok = false
+			}
+		}
+		m.end(ok && packed.Len() < plain.Len()/2)
+	}
+
+	{ // File helpers
+		m := begin(t, n, "WriteMatFile()/ReadMatFile()")
+		n++
+		path := filepath.Join(t.TempDir(), "data.mat")
+		a := randomSparse(5, 4, 0.5)
+		err1 := WriteMatFile(path, []MatVariable{{Name: "X", Real: a}}, MatOptions{Compress: true})
+		got, err2 := ReadMatFile(path)
+		_, errMissing := ReadMatFile(filepath.Join(t.TempDir(), "missing.mat"))
+		m.end(err1 == nil && err2 == nil && errMissing != nil && len(got) == 1 && denseEqual(got[0].Real, a, 0))
+	}
+}
@@ -8,7 +8,11 @@ import (
 // Add returns a new matrix that is the element-wise sum of matrices `m1` and `m2`.
 // Both input matrices must be non-nil and have identical dimensions and sizes.
 // Returns an error if validation fails.
-func Add(m1, m2 *Matx) (*Matx, error) {
+func Add(m1, m2 *Matx) (result *Matx, err error) {
+	if span := startTrace("Add", m1, m2); span != nil {
+		defer func() { span.end(elementCount(result), 8*elementCount(result), err) }()
+	}
+
 	if m1 == nil || m2 == nil {
 		return nil, fmt.Errorf("one or both the matrices are nil")
 	}
@@ -41,7 +45,11 @@ func Add(m1, m2 *Matx) (*Matx, error) {
 }
 
 // Negate performs an in-place negation of all elements in the matrix.
-func (m *Matx) Negate() error {
+func (m *Matx) Negate() (err error) {
+	if span := startTrace("Negate", m); span != nil {
+		defer func() { span.end(elementCount(m), 0, err) }()
+	}
+
 	if m == nil || m.Data == nil {
 		return fmt.Errorf("cannot negate: matrix is nil or uninitialized")
 	}
@@ -53,7 +61,11 @@ func (m *Matx) Negate() error {
 }
 
 // Scale multiplies all elements of the matrix by scalar `n`.
-func (m *Matx) Scale(n int) error {
+func (m *Matx) Scale(n int) (err error) {
+	if span := startTrace("Scale", m); span != nil {
+		defer func() { span.end(elementCount(m), 0, err) }()
+	}
+
 	if m == nil || m.Data == nil {
 		return fmt.Errorf("nil matrix given")
 	}
@@ -65,7 +77,11 @@ func (m *Matx) Scale(n int) error {
 }
 
 // Raise raises each element of the matrix to the specified `power`.
-func (m *Matx) Raise(power float64) error {
+func (m *Matx) Raise(power float64) (err error) {
+	if span := startTrace("Raise", m); span != nil {
+		defer func() { span.end(elementCount(m), 0, err) }()
+	}
+
 	if m == nil || m.Data == nil {
 		return fmt.Errorf("cannot raise: matrix is nil or uninitialized")
 	}
@@ -77,7 +93,11 @@ func (m *Matx) Raise(power float64) error {
 }
 
 // Reciprocal transforms each element of the matrix to its multiplicative inverse (1/x).
-func (m *Matx) Reciprocal() error {
+func (m *Matx) Reciprocal() (err error) {
+	if span := startTrace("Reciprocal", m); span != nil {
+		defer func() { span.end(elementCount(m), 0, err) }()
+	}
+
 	if m == nil || m.Data == nil {
 		return fmt.Errorf("cannot reciprocate: matrix or matrix data is nil")
 	}
@@ -0,0 +1,297 @@
+package matx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Matrix is the read-only view shared by every 2D matrix representation in the
+// package: dense Matx, the structured Diagonal, Triangular and Symmetric types,
+// band storage and the sparse formats. MatrixMultiply, MatrixDet, MatrixInvert and
+// MatrixSolve dispatch on the concrete type to exploit its structure.
+type Matrix interface {
+	// Dims returns the number of rows and columns.
+	Dims() (rows, cols int)
+	// At returns the entry at row i, column j, or an error if it is out of bounds.
+	At(i, j int) (float64, error)
+}
+
+// Dims returns the shape of a 2D matrix, making *Matx a Matrix.
+// A 1D vector is treated as a single column; other ranks and nil report 0×0.
+func (m *Matx) Dims() (int, int) {
+	if m == nil {
+		return 0, 0
+	}
+	switch len(m.Dimensions) {
+	case 1:
+		return m.Dimensions[0], 1
+	case 2:
+		return m.Dimensions[0], m.Dimensions[1]
+	}
+	return 0, 0
+}
+
+// At returns the entry at row i, column j of a 2D matrix, or element i of a 1D
+// vector when j is 0.
+func (m *Matx) At(i, j int) (float64, error) {
+	if m == nil {
+		return 0, fmt.Errorf("Matrix is nil")
+	}
+	rows, cols := m.Dims()
+	if rows == 0 && cols == 0 {
+		return 0, fmt.Errorf("At requires a 1D or 2D matrix, got dimensions %v", m.Dimensions)
+	}
+	if i < 0 || i >= rows || j < 0 || j >= cols {
+		return 0, fmt.Errorf("index (%d, %d) out of bounds for %dx%d matrix", i, j, rows, cols)
+	}
+	return m.Data[i*cols+j], nil
+}
+
+// isNilMatrix reports whether `a` is nil or holds a nil pointer to one of the
+// package's matrix types, whose methods would otherwise panic.
+func isNilMatrix(a Matrix) bool {
+	switch v := a.(type) {
+	case nil:
+		return true
+	case *Matx:
+		return v == nil
+	case *Diagonal:
+		return v == nil
+	case *Triangular:
+		return v == nil
+	case *Symmetric:
+		return v == nil
+	case *Tridiagonal:
+		return v == nil
+	case *Band:
+		return v == nil
+	case *COO:
+		return v == nil
+	case *CSR:
+		return v == nil
+	case *CSC:
+		return v == nil
+	}
+	return false
+}
+
+// ToDense returns a dense copy of any Matrix.
+func ToDense(a Matrix) (*Matx, error) {
+	if isNilMatrix(a) {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	switch v := a.(type) {
+	case *Matx:
+		if len(v.Dimensions) != 2 {
+			rows, cols := v.Dims()
+			return New(append([]float64(nil), v.Data...), []int{rows, cols})
+		}
+		return Clone(v)
+	case interface{ ToMatx() (*Matx, error) }:
+		return v.ToMatx()
+	}
+
+	rows, cols := a.Dims()
+	m, err := Zeros([]int{rows, cols})
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if m.Data[i*cols+j], err = a.At(i, j); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// MatrixMultiply returns a·b, keeping the structure where the product has one:
+// diagonal times diagonal is O(n) and stays Diagonal, a diagonal factor scales rows
+// or columns in O(n²), and triangular matrices of the same orientation multiply into
+// a Triangular. Everything else falls back to the dense Multiply.
+func MatrixMultiply(a, b Matrix) (Matrix, error) {
+	if isNilMatrix(a) || isNilMatrix(b) {
+		return nil, fmt.Errorf("One or both matrices passed are nil")
+	}
+	ar, ac := a.Dims()
+	br, bc := b.Dims()
+	if ac != br {
+		return nil, fmt.Errorf("cannot multiply %dx%d by %dx%d matrix", ar, ac, br, bc)
+	}
+
+	da, aDiag := a.(*Diagonal)
+	db, bDiag := b.(*Diagonal)
+	switch {
+	case aDiag && bDiag:
+		out := make([]float64, len(da.Data))
+		for i, v := range da.Data {
+			out[i] = v * db.Data[i]
+		}
+		return &Diagonal{Data: out}, nil
+	case aDiag:
+		m, err := ToDense(b)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range da.Data {
+			row := m.Data[i*bc : (i+1)*bc]
+			for j := range row {
+				row[j] *= v
+			}
+		}
+		return m, nil
+	case bDiag:
+		m, err := ToDense(a)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < ar; i++ {
+			row := m.Data[i*ac : (i+1)*ac]
+			for j, v := range db.Data {
+				row[j] *= v
+			}
+		}
+		return m, nil
+	}
+
+	if ta, ok := a.(*Triangular); ok {
+		if tb, ok := b.(*Triangular); ok && ta.Upper == tb.Upper {
+			return ta.multiplyTriangular(tb), nil
+		}
+	}
+
+	ma, err := ToDense(a)
+	if err != nil {
+		return nil, err
+	}
+	mb, err := ToDense(b)
+	if err != nil {
+		return nil, err
+	}
+	return asMatrix(Multiply(ma, mb))
+}
+
+// asMatrix converts a typed result to Matrix, keeping a nil pointer from turning
+// into a non-nil interface when err is set.
+func asMatrix[T Matrix](m T, err error) (Matrix, error) {
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MatrixDet returns the determinant of a square Matrix: O(n) for Diagonal and
+// Triangular, O(n·bw²) for band storage, a packed Cholesky for Symmetric, and the
+// dense LU for everything else.
+func MatrixDet(a Matrix) (float64, error) {
+	if isNilMatrix(a) {
+		return 0, fmt.Errorf("Nil matrix")
+	}
+	if rows, cols := a.Dims(); rows != cols {
+		return 0, fmt.Errorf("Matrix must be square")
+	}
+
+	switch v := a.(type) {
+	case *Diagonal:
+		return v.Det(), nil
+	case *Triangular:
+		return v.Det(), nil
+	case *Symmetric:
+		return v.Det()
+	case *Tridiagonal:
+		return MatrixDet(v.ToBand())
+	case *Band:
+		lu, err := BandLUFactor(v)
+		if errors.Is(err, errSingular) {
+			return 0, nil // exactly singular: determinant is zero
+		}
+		if err != nil {
+			return 0, err
+		}
+		return lu.Det(), nil
+	case *Matx:
+		return Det(v)
+	}
+
+	m, err := ToDense(a)
+	if err != nil {
+		return 0, err
+	}
+	return Det(m)
+}
+
+// MatrixInvert returns the inverse of a square Matrix. Diagonal inverts in O(n) and
+// Triangular in O(n³/6) without pivoting, both keeping their type; Symmetric returns a
+// Symmetric. Everything else is inverted densely with Invert.
+func MatrixInvert(a Matrix) (Matrix, error) {
+	if isNilMatrix(a) {
+		return nil, fmt.Errorf("Nil matrix")
+	}
+	if rows, cols := a.Dims(); rows != cols {
+		return nil, fmt.Errorf("Matrix must be square")
+	}
+
+	switch v := a.(type) {
+	case *Diagonal:
+		return asMatrix(v.Inverse())
+	case *Triangular:
+		return asMatrix(v.Inverse())
+	case *Symmetric:
+		return asMatrix(v.Inverse())
+	}
+
+	m, err := ToDense(a)
+	if err != nil {
+		return nil, err
+	}
+	return asMatrix(Invert(m))
+}
+
+// MatrixSolve returns x such that A x = b, choosing the solver from the type of A:
+// O(n) for Diagonal and Tridiagonal, O(n²) substitution for Triangular, band LU,
+// Cholesky with an LU fallback for Symmetric, sparse LU with AMD ordering for CSR
+// and CSC, and the dense LU for everything else.
+func MatrixSolve(a Matrix, b []float64) ([]float64, error) {
+	if isNilMatrix(a) {
+		return nil, fmt.Errorf("Nil matrix")
+	}
+	if rows, cols := a.Dims(); rows != cols {
+		return nil, fmt.Errorf("Matrix must be square")
+	}
+
+	switch v := a.(type) {
+	case *Diagonal:
+		return v.Solve(b)
+	case *Triangular:
+		return v.Solve(b)
+	case *Symmetric:
+		return v.Solve(b)
+	case *Tridiagonal:
+		return v.Solve(b)
+	case *Band:
+		lu, err := BandLUFactor(v)
+		if err != nil {
+			return nil, err
+		}
+		return lu.Solve(b)
+	case *CSR:
+		lu, err := SparseLUFactor(v, AMDOrdering)
+		if err != nil {
+			return nil, err
+		}
+		return lu.Solve(b)
+	case *CSC:
+		return MatrixSolve(v.ToCSR(), b)
+	}
+
+	m, err := ToDense(a)
+	if err != nil {
+		return nil, err
+	}
+	lu, err := LUFactor(m)
+	if err != nil {
+		return nil, err
+	}
+	return lu.Solve(b)
+}
@@ -55,3 +55,21 @@ func BenchmarkDot1000(b *testing.B) {
 		_, _ = Dot(a, bb)
 	}
 }
+
+func BenchmarkLUFactor500x500(b *testing.B) {
+	mat, _ := Rand(500, 500)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = LUFactor(mat)
+	}
+}
+
+func BenchmarkInvert200x200(b *testing.B) {
+	mat, _ := Rand(200, 200)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Invert(mat)
+	}
+}
@@ -0,0 +1,226 @@
+package matx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"unsafe"
+)
+
+// mappedMagic identifies files created by CreateMapped.
+var mappedMagic = [8]byte{'G', 'O', 'T', 'E', 'N', 'M', 'M', 0}
+
+// mappedVersion is the current on-disk header version.
+const mappedVersion = 1
+
+// MappedMatx is a matrix whose Data lives in a memory-mapped file instead of the Go heap.
+// The embedded *Matx can be passed to any function in this package (Sum, GetRow, Multiply, ...);
+// pages are loaded lazily by the operating system as they are touched.
+//
+// File layout (little endian):
+// - 8 byte magic "GOTENMM\x00"
+// - uint32 version, uint32 number of dimensions
+// - one uint64 per dimension
+// - the float64 elements in row-major order
+//
+// Writing to Data of a read-only mapping faults; use OpenMapped with writable set to modify.
+type MappedMatx struct {
+	*Matx
+	file     *os.File
+	mapping  []byte
+	writable bool
+}
+
+// mappedHeaderSize returns the byte length of the header for a matrix with `ndims` dimensions.
+// It is always a multiple of 8, so the data section stays aligned for float64 access.
+func mappedHeaderSize(ndims int) int {
+	return 16 + 8*ndims
+}
+
+// CreateMapped creates (or truncates) the file at `path`, sizes it for a matrix with
+// the given `dims`, and maps it read-write. All elements start at zero.
+func CreateMapped(path string, dims []int) (*MappedMatx, error) {
+	if len(dims) == 0 {
+		return nil, fmt.Errorf("mapped matrix creation failed: dimensions cannot be empty")
+	}
+	if !hostLittleEndian() {
+		return nil, fmt.Errorf("mapped matrices require a little-endian host")
+	}
+
+	size := 1
+	for _, d := range dims {
+		if d < 0 {
+			return nil, fmt.Errorf("invalid dimension %d in %v", d, dims)
+		}
+		size *= d
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mapped matrix: %w", err)
+	}
+
+	headerSize := mappedHeaderSize(len(dims))
+	total := int64(headerSize) + int64(size)*8
+	if err := f.Truncate(total); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to size mapped matrix file: %w", err)
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, mappedMagic[:])
+	binary.LittleEndian.PutUint32(header[8:], mappedVersion)
+	binary.LittleEndian.PutUint32(header[12:], uint32(len(dims)))
+	for i, d := range dims {
+		binary.LittleEndian.PutUint64(header[16+8*i:], uint64(d))
+	}
+	if _, err := f.WriteAt(header, 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write mapped matrix header: %w", err)
+	}
+
+	return mapMatx(f, int(total), headerSize, dims, true)
+}
+
+// OpenMapped maps an existing file created by CreateMapped.
+// With `writable` false the mapping is read-only and shared with other readers.
+func OpenMapped(path string, writable bool) (*MappedMatx, error) {
+	if !hostLittleEndian() {
+		return nil, fmt.Errorf("mapped matrices require a little-endian host")
+	}
+
+	flag := os.O_RDONLY
+	if writable {
+		flag = os.O_RDWR
+	}
+	f, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mapped matrix: %w", err)
+	}
+
+	dims, headerSize, err := readMappedHeader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat mapped matrix: %w", err)
+	}
+
+	size, ok := shapeSize(dims)
+	if !ok || int64(size) > (math.MaxInt64-int64(headerSize))/8 {
+		f.Close()
+		return nil, fmt.Errorf("mapped matrix shape %v is too large", dims)
+	}
+	want := int64(headerSize) + int64(size)*8
+	if want < int64(headerSize) || info.Size() < want {
+		f.Close()
+		return nil, fmt.Errorf("mapped matrix file truncated: need %d bytes for shape %v, have %d", want, dims, info.Size())
+	}
+
+	return mapMatx(f, int(want), headerSize, dims, writable)
+}
+
+// readMappedHeader parses and validates the header at the start of `f`.
+func readMappedHeader(f *os.File) ([]int, int, error) {
+	fixed := make([]byte, 16)
+	if _, err := f.ReadAt(fixed, 0); err != nil {
+		return nil, 0, fmt.Errorf("failed to read mapped matrix header: %w", err)
+	}
+	if [8]byte(fixed[:8]) != mappedMagic {
+		return nil, 0, fmt.Errorf("not a mapped matrix file: bad magic %q", fixed[:8])
+	}
+	if v := binary.LittleEndian.Uint32(fixed[8:]); v != mappedVersion {
+		return nil, 0, fmt.Errorf("unsupported mapped matrix version %d", v)
+	}
+
+	ndims := int(binary.LittleEndian.Uint32(fixed[12:]))
+	if ndims == 0 || ndims > 64 {
+		return nil, 0, fmt.Errorf("invalid number of dimensions in mapped matrix header: %d", ndims)
+	}
+
+	raw := make([]byte, 8*ndims)
+	if _, err := f.ReadAt(raw, 16); err != nil {
+		return nil, 0, fmt.Errorf("failed to read mapped matrix dimensions: %w", err)
+	}
+	dims := make([]int, ndims)
+	for i := range dims {
+		d := binary.LittleEndian.Uint64(raw[8*i:])
+		if d > math.MaxInt64 {
+			return nil, 0, fmt.Errorf("invalid mapped matrix dimension %d at axis %d", d, i)
+		}
+		dims[i] = int(d)
+	}
+
+	return dims, mappedHeaderSize(ndims), nil
+}
+
+// mapMatx maps `length` bytes of `f` and wires the data section into a Matx.
+func mapMatx(f *os.File, length, headerSize int, dims []int, writable bool) (*MappedMatx, error) {
+	mapping, err := mapFile(f, length, writable)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to map matrix file: %w", err)
+	}
+
+	count := (length - headerSize) / 8
+	data := []float64{}
+	if count > 0 {
+		data = unsafe.Slice((*float64)(unsafe.Pointer(&mapping[headerSize])), count)
+	}
+
+	return &MappedMatx{
+		Matx:     &Matx{Data: data, Dimensions: dims},
+		file:     f,
+		mapping:  mapping,
+		writable: writable,
+	}, nil
+}
+
+// Sync flushes modified pages of a writable mapping back to the file.
+func (mm *MappedMatx) Sync() error {
+	if mm == nil || mm.mapping == nil {
+		return fmt.Errorf("mapped matrix is closed")
+	}
+	if !mm.writable {
+		return nil
+	}
+	if err := syncFile(mm.file, mm.mapping); err != nil {
+		return fmt.Errorf("failed to sync mapped matrix: %w", err)
+	}
+	return nil
+}
+
+// Close flushes a writable mapping, unmaps it and closes the file.
+// The embedded Matx must not be used afterwards.
+func (mm *MappedMatx) Close() error {
+	if mm == nil || mm.mapping == nil {
+		return fmt.Errorf("mapped matrix is closed")
+	}
+
+	var firstErr error
+	if mm.writable {
+		firstErr = mm.Sync()
+	}
+	if err := unmapFile(mm.file, mm.mapping, mm.writable); err != nil && firstErr == nil {
+		firstErr = fmt.Errorf("failed to unmap matrix: %w", err)
+	}
+	if err := mm.file.Close(); err != nil && firstErr == nil {
+		firstErr = fmt.Errorf("failed to close mapped matrix file: %w", err)
+	}
+
+	mm.mapping = nil
+	mm.Matx = nil
+	return firstErr
+}
+
+// hostLittleEndian reports whether the running machine stores integers little endian,
+// which the mapped layout relies on to view raw bytes as float64 values.
+func hostLittleEndian() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}
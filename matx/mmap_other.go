@@ -0,0 +1,31 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package matx
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile emulates a mapping on platforms without mmap by reading the file into memory.
+// Changes only reach the file on Sync or Close.
+func mapFile(f *os.File, length int, _ bool) ([]byte, error) {
+	buf := make([]byte, length)
+	if _, err := f.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// syncFile writes the in-memory copy back to the file.
+func syncFile(f *os.File, mapping []byte) error {
+	if _, err := f.WriteAt(mapping, 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// unmapFile has nothing to release for the in-memory emulation.
+func unmapFile(_ *os.File, _ []byte, _ bool) error {
+	return nil
+}
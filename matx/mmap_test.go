@@ -0,0 +1,92 @@
+package matx
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMapped(t *testing.T) {
+	n := 1
+	dir := t.TempDir()
+
+	{ // Create, write, reopen read-only
+		m := begin(t, n, "CreateMapped()/OpenMapped()")
+		n++
+		path := filepath.Join(dir, "a.gmm")
+		mm, err := CreateMapped(path, []int{2, 3})
+		ok := err == nil
+		if ok {
+			copy(mm.Data, []float64{1, 2, 3, 4, 5, 6})
+			ok = mm.Close() == nil
+		}
+		if ok {
+			ro, err := OpenMapped(path, false)
+			ok = err == nil && reflect.DeepEqual(ro.Dimensions, []int{2, 3})
+			if ok {
+				row, _ := GetRow(ro.Matx, 1)
+				sum, _ := Sum(ro.Matx, 0)
+				ok = reflect.DeepEqual(row, []float64{4, 5, 6}) &&
+					reflect.DeepEqual(sum, []float64{5, 7, 9})
+				ok = ro.Close() == nil && ok
+			}
+		}
+		m.end(ok)
+	}
+
+	{ // Tiled multiply into a mapped output
+		m := begin(t, n, "MultiplyTiledInto() mapped")
+		n++
+		a, _ := Rand(37, 23)
+		b, _ := Rand(23, 41)
+		want, _ := Multiply(a, b)
+
+		out, err := CreateMapped(filepath.Join(dir, "c.gmm"), []int{37, 41})
+		ok := err == nil
+		if ok {
+			ok = MultiplyTiledInto(out.Matx, a, b, 8) == nil
+			for i := range want.Data {
+				if math.Abs(want.Data[i]-out.Data[i]) > 1e-9 {
+					ok = false
+					break
+				}
+			}
+			ok = out.Close() == nil && ok
+		}
+		// 0·Inf contributes NaN, as in Multiply
+		z, _ := New([]float64{0, 1}, []int{1, 2})
+		inf, _ := New([]float64{math.Inf(1), 2}, []int{2, 1})
+		nan, err2 := MultiplyTiled(z, inf, 1)
+		// An output aliasing an input is rejected before it is zeroed
+		sq, _ := New([]float64{1, 2, 3, 4}, []int{2, 2})
+		id, _ := Identity(2, 2)
+		row, _ := New([]float64{1, 1}, []int{1, 2})
+		view := &Matx{Data: sq.Data[2:], Dimensions: []int{1, 2}}
+		errAlias := MultiplyTiledInto(sq, sq, id, 0)
+		errView := MultiplyTiledInto(view, row, sq, 0)
+		m.end(ok && err2 == nil && math.IsNaN(nan.Data[0]) && errAlias != nil && errView != nil && sq.Data[3] == 4)
+	}
+
+	{ // Bad magic and corrupt dimensions
+		m := begin(t, n, "OpenMapped() rejects bad file")
+		n++
+		// Corrupt the header after closing, so that Close cannot write it back
+		corrupt := func(name string, offset int64, patch []byte) error {
+			path := filepath.Join(dir, name)
+			mm, _ := CreateMapped(path, []int{2, 8})
+			mm.Close()
+			f, _ := os.OpenFile(path, os.O_RDWR, 0)
+			f.WriteAt(patch, offset)
+			f.Close()
+			_, err := OpenMapped(path, false)
+			return err
+		}
+		errMagic := corrupt("bad.gmm", 0, []byte("NOTMATX!"))
+		errWrap := corrupt("wrap.gmm", 16, binary.LittleEndian.AppendUint64(nil, 1<<61))
+		errNeg := corrupt("neg.gmm", 16, binary.LittleEndian.AppendUint64(nil, math.MaxUint64))
+		m.end(errMagic != nil && errWrap != nil && errNeg != nil)
+	}
+}
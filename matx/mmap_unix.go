@@ -0,0 +1,33 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package matx
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// mapFile maps the first `length` bytes of `f` into memory, shared with the file.
+func mapFile(f *os.File, length int, writable bool) ([]byte, error) {
+	prot := syscall.PROT_READ
+	if writable {
+		prot |= syscall.PROT_WRITE
+	}
+	return syscall.Mmap(int(f.Fd()), 0, length, prot, syscall.MAP_SHARED)
+}
+
+// syncFile flushes dirty pages of `mapping` to disk.
+func syncFile(_ *os.File, mapping []byte) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC,
+		uintptr(unsafe.Pointer(&mapping[0])), uintptr(len(mapping)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// unmapFile releases `mapping`.
+func unmapFile(_ *os.File, mapping []byte, _ bool) error {
+	return syscall.Munmap(mapping)
+}
@@ -0,0 +1,548 @@
+package matx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MatrixMarketHeader describes the banner and size line of a Matrix Market file.
+//   - Format is "coordinate" (sparse triplets) or "array" (dense, column-major).
+//   - Field is "real", "integer" or "pattern" (coordinate only; every value is 1).
+//   - Symmetry is "general", "symmetric" or "skew-symmetric"; the last two store
+//     only the lower triangle (strictly lower for skew-symmetric).
+//   - NNZ is the number of stored entries, before symmetric expansion.
+//   - Comments holds the comment lines without their leading '%'.
+type MatrixMarketHeader struct {
+	Format   string
+	Field    string
+	Symmetry string
+	Rows     int
+	Cols     int
+	NNZ      int
+	Comments []string
+}
+
+// MatrixMarketOptions configures WriteMatrixMarket. Empty fields select defaults:
+// coordinate format for CSR, CSC and COO and array format otherwise, the real
+// field and general symmetry.
+type MatrixMarketOptions struct {
+	Format   string
+	Field    string
+	Symmetry string
+	Comments []string
+}
+
+// mmBanner starts every Matrix Market file.
+const mmBanner = "%%MatrixMarket"
+
+// mmReader tracks line numbers for error messages while scanning a file.
+type mmReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// next returns the next line with surrounding space trimmed, or io.EOF.
+func (r *mmReader) next() (string, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", fmt.Errorf("matrix market: read failed: %w", err)
+		}
+		return "", io.EOF
+	}
+	r.line++
+	return strings.TrimSpace(r.scanner.Text()), nil
+}
+
+// errorf prefixes a message with the current line number.
+func (r *mmReader) errorf(format string, args ...any) error {
+	return fmt.Errorf("matrix market line %d: %s", r.line, fmt.Sprintf(format, args...))
+}
+
+// readMatrixMarketHeader parses the banner, the comment block and the size line.
+func readMatrixMarketHeader(r *mmReader) (*MatrixMarketHeader, error) {
+	banner, err := r.next()
+	if err == io.EOF {
+		return nil, fmt.Errorf("matrix market: empty input")
+	}
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(banner)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], mmBanner) {
+		return nil, r.errorf("missing %q banner", mmBanner)
+	}
+	if len(fields) != 5 {
+		return nil, r.errorf("banner must be %q, got %q", mmBanner+" matrix <format> <field> <symmetry>", banner)
+	}
+	if object := strings.ToLower(fields[1]); object != "matrix" {
+		return nil, r.errorf("unsupported object %q, only \"matrix\" is supported", fields[1])
+	}
+
+	h := &MatrixMarketHeader{
+		Format:   strings.ToLower(fields[2]),
+		Field:    strings.ToLower(fields[3]),
+		Symmetry: strings.ToLower(fields[4]),
+	}
+	if h.Field == "double" {
+		h.Field = "real"
+	}
+	if err := checkMatrixMarketHeader(h); err != nil {
+		return nil, r.errorf("%v", err)
+	}
+
+	// Comments, then the size line; blank lines are allowed in between
+	var size string
+	for {
+		line, err := r.next()
+		if err == io.EOF {
+			return nil, r.errorf("missing size line")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(line, "%") {
+			h.Comments = append(h.Comments, strings.TrimPrefix(line, "%"))
+			continue
+		}
+		if line != "" {
+			size = line
+			break
+		}
+	}
+
+	want := 3
+	if h.Format == "array" {
+		want = 2
+	}
+	parts := strings.Fields(size)
+	if len(parts) != want {
+		return nil, r.errorf("size line must have %d integers for %s format, got %q", want, h.Format, size)
+	}
+	dims := make([]int, want)
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return nil, r.errorf("invalid size %q", p)
+		}
+		// Bounding each size keeps Rows+1 and Rows*Cols from overflowing downstream
+		if i < 2 && v > math.MaxInt32 {
+			return nil, r.errorf("size %d exceeds the maximum of %d", v, math.MaxInt32)
+		}
+		dims[i] = v
+	}
+	h.Rows, h.Cols = dims[0], dims[1]
+
+	if h.Symmetry != "general" && h.Rows != h.Cols {
+		return nil, r.errorf("%s matrix must be square, got %dx%d", h.Symmetry, h.Rows, h.Cols)
+	}
+	if h.Format == "coordinate" {
+		h.NNZ = dims[2]
+	} else {
+		h.NNZ = h.Rows * h.Cols
+		switch h.Symmetry {
+		case "symmetric":
+			h.NNZ = h.Rows * (h.Rows + 1) / 2
+		case "skew-symmetric":
+			h.NNZ = h.Rows * (h.Rows - 1) / 2
+		}
+	}
+	return h, nil
+}
+
+// checkMatrixMarketHeader validates the format, field and symmetry keywords.
+func checkMatrixMarketHeader(h *MatrixMarketHeader) error {
+	switch h.Format {
+	case "coordinate", "array":
+	default:
+		return fmt.Errorf("unknown format %q, want \"coordinate\" or \"array\"", h.Format)
+	}
+	switch h.Field {
+	case "real", "integer":
+	case "pattern":
+		if h.Format == "array" {
+			return fmt.Errorf("pattern field requires coordinate format")
+		}
+	case "complex":
+		return fmt.Errorf("complex matrices are not supported")
+	default:
+		return fmt.Errorf("unknown field %q, want \"real\", \"integer\" or \"pattern\"", h.Field)
+	}
+	switch h.Symmetry {
+	case "general", "symmetric", "skew-symmetric":
+	case "hermitian":
+		return fmt.Errorf("hermitian symmetry requires complex matrices, which are not supported")
+	default:
+		return fmt.Errorf("unknown symmetry %q, want \"general\", \"symmetric\" or \"skew-symmetric\"", h.Symmetry)
+	}
+	return nil
+}
+
+// parseMatrixMarketValue parses a single value for the header's field.
+func parseMatrixMarketValue(r *mmReader, h *MatrixMarketHeader, s string) (float64, error) {
+	if h.Field == "integer" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, r.errorf("invalid integer %q", s)
+		}
+		return float64(v), nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, r.errorf("invalid real %q", s)
+	}
+	return v, nil
+}
+
+// ReadMatrixMarketCOO reads a Matrix Market file of either format into triplets,
+// expanding symmetric and skew-symmetric storage to the full matrix.
+// Array files keep only their nonzero entries.
+func ReadMatrixMarketCOO(rd io.Reader) (*COO, *MatrixMarketHeader, error) {
+	r := &mmReader{scanner: bufio.NewScanner(rd)}
+	r.scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	h, err := readMatrixMarketHeader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coo := &COO{Rows: h.Rows, Cols: h.Cols}
+	add := func(i, j int, v float64) {
+		if v == 0 {
+			return
+		}
+		coo.Append(i, j, v)
+		switch {
+		case i == j:
+		case h.Symmetry == "symmetric":
+			coo.Append(j, i, v)
+		case h.Symmetry == "skew-symmetric":
+			coo.Append(j, i, -v)
+		}
+	}
+
+	if h.Format == "array" {
+		err = readMatrixMarketArray(r, h, add)
+	} else {
+		err = readMatrixMarketCoordinate(r, h, add)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return coo, h, nil
+}
+
+// readMatrixMarketCoordinate reads h.NNZ "i j [value]" lines with 1-based indices.
+func readMatrixMarketCoordinate(r *mmReader, h *MatrixMarketHeader, add func(i, j int, v float64)) error {
+	want := 3
+	if h.Field == "pattern" {
+		want = 2
+	}
+	count := 0
+	for {
+		line, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if line == "" || strings.HasPrefix(line, "%") {
+			continue
+		}
+		if count == h.NNZ {
+			return r.errorf("more than the %d entries declared in the size line", h.NNZ)
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) != want {
+			return r.errorf("entry must have %d fields for %s field, got %q", want, h.Field, line)
+		}
+		i, err1 := strconv.Atoi(parts[0])
+		j, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil {
+			return r.errorf("invalid indices in %q", line)
+		}
+		if i < 1 || i > h.Rows || j < 1 || j > h.Cols {
+			return r.errorf("index (%d, %d) out of bounds for %dx%d matrix", i, j, h.Rows, h.Cols)
+		}
+		if h.Symmetry == "skew-symmetric" && i == j {
+			return r.errorf("skew-symmetric matrix has a diagonal entry at (%d, %d)", i, j)
+		}
+		v := 1.0
+		if h.Field != "pattern" {
+			if v, err = parseMatrixMarketValue(r, h, parts[2]); err != nil {
+				return err
+			}
+		}
+		add(i-1, j-1, v)
+		count++
+	}
+	if count < h.NNZ {
+		return fmt.Errorf("matrix market: expected %d entries, got %d", h.NNZ, count)
+	}
+	return nil
+}
+
+// readMatrixMarketArray reads values in column-major order: the full matrix for
+// general symmetry, the lower triangle for symmetric and the strictly lower
+// triangle for skew-symmetric.
+func readMatrixMarketArray(r *mmReader, h *MatrixMarketHeader, add func(i, j int, v float64)) error {
+	i, j, count := 0, 0, 0
+	first := func() int {
+		switch h.Symmetry {
+		case "symmetric":
+			return j
+		case "skew-symmetric":
+			return j + 1
+		}
+		return 0
+	}
+	// Skip columns with nothing stored (the last column of a skew-symmetric matrix)
+	i = first()
+	for j < h.Cols && i >= h.Rows {
+		j++
+		i = first()
+	}
+
+	for {
+		line, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if line == "" || strings.HasPrefix(line, "%") {
+			continue
+		}
+		for _, s := range strings.Fields(line) {
+			if count == h.NNZ {
+				return r.errorf("more than the %d values expected for a %dx%d %s array", h.NNZ, h.Rows, h.Cols, h.Symmetry)
+			}
+			v, err := parseMatrixMarketValue(r, h, s)
+			if err != nil {
+				return err
+			}
+			add(i, j, v)
+			count++
+			i++
+			for j < h.Cols && i >= h.Rows {
+				j++
+				i = first()
+			}
+		}
+	}
+	if count < h.NNZ {
+		return fmt.Errorf("matrix market: expected %d values, got %d", h.NNZ, count)
+	}
+	return nil
+}
+
+// ReadMatrixMarket reads a Matrix Market file, returning a dense *Matx for the
+// array format and a *CSR for the coordinate format.
+func ReadMatrixMarket(r io.Reader) (Matrix, *MatrixMarketHeader, error) {
+	coo, h, err := ReadMatrixMarketCOO(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if h.Format == "array" {
+		m, err := coo.ToMatx()
+		if err != nil {
+			return nil, nil, err
+		}
+		return m, h, nil
+	}
+	return coo.ToCSR(), h, nil
+}
+
+// ReadMatrixMarketDense reads a Matrix Market file of either format into a dense matrix.
+func ReadMatrixMarketDense(r io.Reader) (*Matx, *MatrixMarketHeader, error) {
+	coo, h, err := ReadMatrixMarketCOO(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if size, ok := shapeSize([]int{h.Rows, h.Cols}); !ok || size > math.MaxInt/8 {
+		return nil, nil, fmt.Errorf("matrix market: %dx%d is too large for a dense matrix", h.Rows, h.Cols)
+	}
+	m, err := coo.ToMatx()
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, h, nil
+}
+
+// ReadMatrixMarketFile opens `path` and reads it with ReadMatrixMarket.
+func ReadMatrixMarketFile(path string) (Matrix, *MatrixMarketHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open matrix market file: %w", err)
+	}
+	defer f.Close()
+	return ReadMatrixMarket(f)
+}
+
+// WriteMatrixMarket writes any Matrix in Matrix Market format. Values are written
+// with the shortest representation that round-trips exactly. The integer field
+// requires integral values; symmetric and skew-symmetric storage require the
+// matrix to have that symmetry exactly, and only the lower triangle is written.
+func WriteMatrixMarket(w io.Writer, a Matrix, opts MatrixMarketOptions) error {
+	if isNilMatrix(a) {
+		return fmt.Errorf("Matrix is nil")
+	}
+	h := &MatrixMarketHeader{Format: opts.Format, Field: opts.Field, Symmetry: opts.Symmetry}
+	if h.Format == "" {
+		h.Format = "array"
+		switch a.(type) {
+		case *CSR, *CSC, *COO:
+			h.Format = "coordinate"
+		}
+	}
+	if h.Field == "" {
+		h.Field = "real"
+	}
+	if h.Symmetry == "" {
+		h.Symmetry = "general"
+	}
+	if err := checkMatrixMarketHeader(h); err != nil {
+		return fmt.Errorf("matrix market: %w", err)
+	}
+	h.Rows, h.Cols = a.Dims()
+	if h.Symmetry != "general" && h.Rows != h.Cols {
+		return fmt.Errorf("matrix market: %s storage requires a square matrix, got %dx%d", h.Symmetry, h.Rows, h.Cols)
+	}
+
+	csr, err := matrixToCSR(a)
+	if err != nil {
+		return err
+	}
+	if err := checkMatrixMarketValues(csr, h); err != nil {
+		return err
+	}
+
+	// keep reports whether (i, j) is in the stored triangle
+	keep := func(i, j int) bool {
+		switch h.Symmetry {
+		case "symmetric":
+			return i >= j
+		case "skew-symmetric":
+			return i > j
+		}
+		return true
+	}
+	format := func(v float64) string {
+		if h.Field == "integer" {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s matrix %s %s %s\n", mmBanner, h.Format, h.Field, h.Symmetry)
+	for _, c := range opts.Comments {
+		for _, line := range strings.Split(c, "\n") {
+			fmt.Fprintf(bw, "%%%s\n", line)
+		}
+	}
+
+	if h.Format == "coordinate" {
+		nnz := 0
+		for i := 0; i < csr.Rows; i++ {
+			for k := csr.RowPtr[i]; k < csr.RowPtr[i+1]; k++ {
+				if keep(i, csr.ColIdx[k]) {
+					nnz++
+				}
+			}
+		}
+		fmt.Fprintf(bw, "%d %d %d\n", h.Rows, h.Cols, nnz)
+		for i := 0; i < csr.Rows; i++ {
+			for k := csr.RowPtr[i]; k < csr.RowPtr[i+1]; k++ {
+				j := csr.ColIdx[k]
+				if !keep(i, j) {
+					continue
+				}
+				if h.Field == "pattern" {
+					fmt.Fprintf(bw, "%d %d\n", i+1, j+1)
+				} else {
+					fmt.Fprintf(bw, "%d %d %s\n", i+1, j+1, format(csr.Values[k]))
+				}
+			}
+		}
+	} else {
+		fmt.Fprintf(bw, "%d %d\n", h.Rows, h.Cols)
+		csc := csr.ToCSC()
+		col := make([]float64, h.Rows)
+		for j := 0; j < h.Cols; j++ {
+			clear(col)
+			for k := csc.ColPtr[j]; k < csc.ColPtr[j+1]; k++ {
+				col[csc.RowIdx[k]] = csc.Values[k]
+			}
+			for i, v := range col {
+				if keep(i, j) {
+					fmt.Fprintln(bw, format(v))
+				}
+			}
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("matrix market: write failed: %w", err)
+	}
+	return nil
+}
+
+// WriteMatrixMarketFile creates (or truncates) `path` and writes `a` to it with WriteMatrixMarket.
+func WriteMatrixMarketFile(path string, a Matrix, opts MatrixMarketOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create matrix market file: %w", err)
+	}
+	if err := WriteMatrixMarket(f, a, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// matrixToCSR converts any Matrix to CSR with sorted columns and no duplicates.
+func matrixToCSR(a Matrix) (*CSR, error) {
+	switch v := a.(type) {
+	case *CSR:
+		return v.ToCOO().ToCSR(), nil
+	case *CSC:
+		return v.ToCSR(), nil
+	case *COO:
+		return v.ToCSR(), nil
+	}
+	m, err := ToDense(a)
+	if err != nil {
+		return nil, err
+	}
+	return CSRFromMatx(m)
+}
+
+// checkMatrixMarketValues verifies that the entries of `a` can be stored with the
+// header's field and symmetry.
+func checkMatrixMarketValues(a *CSR, h *MatrixMarketHeader) error {
+	for i := 0; i < a.Rows; i++ {
+		for k := a.RowPtr[i]; k < a.RowPtr[i+1]; k++ {
+			j, v := a.ColIdx[k], a.Values[k]
+			if h.Field == "integer" && (v != math.Trunc(v) || math.Abs(v) > 1<<53) {
+				return fmt.Errorf("matrix market: value %g at (%d, %d) is not an integer", v, i, j)
+			}
+			switch h.Symmetry {
+			case "symmetric":
+				if t, _ := a.At(j, i); t != v {
+					return fmt.Errorf("matrix market: matrix is not symmetric at (%d, %d)", i, j)
+				}
+			case "skew-symmetric":
+				if t, _ := a.At(j, i); t != -v || i == j {
+					return fmt.Errorf("matrix market: matrix is not skew-symmetric at (%d, %d)", i, j)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package matx
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatrixMarket(t *testing.T) {
+	n := 1
+
+	{ // Sparse and dense round trips are exact
+		m := begin(t, n, "WriteMatrixMarket()/ReadMatrixMarket() round trip")
+		n++
+		dense := randomSparse(6, 4, 0.5)
+		dense.Data[0] = 1.0 / 3
+		csr, _ := CSRFromMatx(dense)
+		ok := true
+		for _, a := range []Matrix{csr, dense} {
+			var buf bytes.Buffer
+			err := WriteMatrixMarket(&buf, a, MatrixMarketOptions{Comments: []string{"written by a test"}})
+			got, h, err2 := ReadMatrixMarket(&buf)
+			ok = ok && err == nil && err2 == nil && denseEqual(got, dense, 0) && h.Comments[0] == "written by a test"
+			_, isCSR := got.(*CSR)
+			_, isDense := got.(*Matx)
+			ok = ok && (isCSR == (h.Format == "coordinate")) && (isDense == (h.Format == "array"))
+		}
+		m.end(ok)
+	}
+
+	{ // Symmetric storage keeps one triangle on disk and expands on read
+		m := begin(t, n, "WriteMatrixMarket() symmetric and skew-symmetric")
+		n++
+		InitExamples()
+		sym, _ := GiveMatx("matxSymmetric3x3")
+		skew, _ := New([]float64{0, 2, -1, -2, 0, 4, 1, -4, 0}, []int{3, 3})
+		ok := true
+		for _, c := range []struct {
+			a        *Matx
+			format   string
+			symmetry string
+			lines    int
+		}{
+			{sym, "array", "symmetric", 2 + 6},
+			{skew, "array", "skew-symmetric", 2 + 3},
+			{skew, "coordinate", "skew-symmetric", 2 + 3},
+		} {
+			var buf bytes.Buffer
+			err := WriteMatrixMarket(&buf, c.a, MatrixMarketOptions{Format: c.format, Symmetry: c.symmetry})
+			lines := strings.Count(buf.String(), "\n")
+			got, h, err2 := ReadMatrixMarketDense(&buf)
+			ok = ok && err == nil && err2 == nil && lines == c.lines && h.Symmetry == c.symmetry && denseEqual(got, c.a, 0)
+		}
+		errSym := WriteMatrixMarket(&bytes.Buffer{}, skew, MatrixMarketOptions{Symmetry: "symmetric"})
+		errInt := WriteMatrixMarket(&bytes.Buffer{}, sym, MatrixMarketOptions{Field: "integer", Format: "coordinate"})
+		m.end(ok && errSym != nil && errInt == nil)
+	}
+
+	{ // Files written by other tools: comments, blank lines, pattern and integer fields
+		m := begin(t, n, "ReadMatrixMarket() fields and qualifiers")
+		n++
+		pattern := "%%MatrixMarket matrix coordinate pattern symmetric\n% a graph\n%\n\n3 3 2\n2 1\n3 3\n"
+		integer := "%%MATRIXMARKET Matrix Coordinate Integer General\n2 3 2\n1 3 -7\n2 1 4\n"
+		array := "%%MatrixMarket matrix array real general\n2 2\n1 3\n2.5e0 -4\n"
+		p, ph, err1 := ReadMatrixMarketDense(strings.NewReader(pattern))
+		i, _, err2 := ReadMatrixMarketDense(strings.NewReader(integer))
+		a, _, err3 := ReadMatrixMarketDense(strings.NewReader(array))
+		wantP, _ := New([]float64{0, 1, 0, 1, 0, 0, 0, 0, 1}, []int{3, 3})
+		wantI, _ := New([]float64{0, 0, -7, 4, 0, 0}, []int{2, 3})
+		wantA, _ := New([]float64{1, 2.5, 3, -4}, []int{2, 2})
+		m.end(err1 == nil && err2 == nil && err3 == nil && len(ph.Comments) == 2 && ph.NNZ == 2 &&
+			denseEqual(p, wantP, 0) && denseEqual(i, wantI, 0) && denseEqual(a, wantA, 0))
+	}
+
+	{ // Malformed input is reported with the offending line
+		m := begin(t, n, "ReadMatrixMarket() malformed input")
+		n++
+		bad := map[string]string{
+			"":               "empty input",
+			"3 3 1\n1 1 1\n": "banner",
+			"%%MatrixMarket matrix coordinate real\n":                                  "banner must be",
+			"%%MatrixMarket vector coordinate real general\n":                          "unsupported object",
+			"%%MatrixMarket matrix coordinate complex general\n1 1 0\n":                "complex",
+			"%%MatrixMarket matrix array pattern general\n1 1\n":                       "pattern field requires",
+			"%%MatrixMarket matrix coordinate real hermitian\n1 1 0\n":                 "hermitian",
+			"%%MatrixMarket matrix coordinate real general\n% only comments\n":         "missing size line",
+			"%%MatrixMarket matrix coordinate real general\n2 2\n":                     "size line",
+			"%%MatrixMarket matrix coordinate real symmetric\n2 3 0\n":                 "must be square",
+			"%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n":            "line 3: index (3, 1) out of bounds",
+			"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n":            "expected 2 entries, got 1",
+			"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1 1\n2 2 2\n":     "more than the 1 entries",
+			"%%MatrixMarket matrix coordinate integer general\n2 2 1\n1 1 1.5\n":       "invalid integer",
+			"%%MatrixMarket matrix array real general\n2 1\n1\nx\n":                    "line 4: invalid real",
+			"%%MatrixMarket matrix array real general\n2 1\n1 2 3\n":                   "more than the 2 values",
+			"%%MatrixMarket matrix coordinate real skew-symmetric\n2 2 1\n1 1 1\n":     "diagonal entry",
+			"%%MatrixMarket matrix coordinate real general\n9223372036854775807 1 0\n": "line 2: size 9223372036854775807 exceeds",
+		}
+		ok := true
+		for input, want := range bad {
+			_, _, err := ReadMatrixMarket(strings.NewReader(input))
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Logf("input %q: got %v, want %q", input, err, want)
+				ok = false
+			}
+		}
+		_, _, errDense := ReadMatrixMarketDense(strings.NewReader("%%MatrixMarket matrix coordinate real general\n4294967296 4294967296 0\n"))
+		_, _, errHuge := ReadMatrixMarketDense(strings.NewReader("%%MatrixMarket matrix coordinate real general\n2147483647 2147483647 0\n"))
+		m.end(ok && errDense != nil && errHuge != nil && strings.Contains(errHuge.Error(), "too large"))
+	}
+
+	{ // File helpers
+		m := begin(t, n, "WriteMatrixMarketFile()/ReadMatrixMarketFile()")
+		n++
+		path := filepath.Join(t.TempDir(), "lap.mtx")
+		lap := laplacian2D(4, 0)
+		err := WriteMatrixMarketFile(path, lap, MatrixMarketOptions{Symmetry: "symmetric", Field: "integer"})
+		got, h, err2 := ReadMatrixMarketFile(path)
+		_, _, errMissing := ReadMatrixMarketFile(filepath.Join(t.TempDir(), "missing.mtx"))
+		m.end(err == nil && err2 == nil && errMissing != nil && h.Field == "integer" &&
+			h.NNZ == (lap.NNZ()+16)/2 && denseEqual(got, lap, 0))
+	}
+}
@@ -0,0 +1,460 @@
+package matx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// npyMagic starts every .npy file.
+const npyMagic = "\x93NUMPY"
+
+// npyHeader holds the fields of the Python dict literal in a .npy header.
+type npyHeader struct {
+	descr   string
+	fortran bool
+	shape   []int
+}
+
+// ReadNpy reads a NumPy .npy array (format versions 1.0, 2.0 and 3.0) into a Matx.
+// Supported dtypes are float32/float64, signed and unsigned integers of 1 to 8 bytes
+// and bool, in either byte order; values are converted to float64, so integers beyond
+// 2^53 lose precision. Fortran-ordered arrays are transposed into row-major Data,
+// and a 0-d array is read as a one-element vector.
+func ReadNpy(r io.Reader) (*Matx, error) {
+	prefix := make([]byte, len(npyMagic)+2)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, fmt.Errorf("npy: failed to read magic: %w", err)
+	}
+	if string(prefix[:len(npyMagic)]) != npyMagic {
+		return nil, fmt.Errorf("npy: missing magic string, not a .npy file")
+	}
+
+	major := prefix[len(npyMagic)]
+	var headerLen int
+	switch major {
+	case 1:
+		var n uint16
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, fmt.Errorf("npy: failed to read header length: %w", err)
+		}
+		headerLen = int(n)
+	case 2, 3:
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, fmt.Errorf("npy: failed to read header length: %w", err)
+		}
+		headerLen = int(n)
+	default:
+		return nil, fmt.Errorf("npy: unsupported format version %d.%d", major, prefix[len(npyMagic)+1])
+	}
+
+	raw := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		return nil, fmt.Errorf("npy: failed to read header: %w", err)
+	}
+	h, err := parseNpyHeader(string(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	order, size, decode, err := npyDecoder(h.descr)
+	if err != nil {
+		return nil, err
+	}
+	count, ok := shapeSize(h.shape)
+	if !ok || count > math.MaxInt/size {
+		return nil, fmt.Errorf("npy: shape %v is too large", h.shape)
+	}
+	buf, err := readLimited(r, int64(count*size))
+	if err != nil {
+		return nil, fmt.Errorf("npy: failed to read %d elements of %s: %w", count, h.descr, err)
+	}
+	data := make([]float64, count)
+	for i := range data {
+		data[i] = decode(order, buf[i*size:(i+1)*size])
+	}
+
+	dims := h.shape
+	if len(dims) == 0 {
+		dims = []int{1}
+	}
+	if h.fortran {
+		data = fortranToRowMajor(data, dims)
+	}
+	return New(data, dims)
+}
+
+// parseNpyHeader parses the dict literal {'descr': ..., 'fortran_order': ..., 'shape': (...)}.
+func parseNpyHeader(s string) (*npyHeader, error) {
+	p := &pyLiteral{s: s}
+	fail := func(msg string) (*npyHeader, error) {
+		return nil, fmt.Errorf("npy: malformed header %q: %s", strings.TrimSpace(s), msg)
+	}
+
+	if !p.consume('{') {
+		return fail("expected '{'")
+	}
+	h := &npyHeader{}
+	seen := map[string]bool{}
+	for !p.consume('}') {
+		key, ok := p.str()
+		if !ok || !p.consume(':') {
+			return fail("expected a quoted key followed by ':'")
+		}
+		switch key {
+		case "descr":
+			if h.descr, ok = p.str(); !ok {
+				return fail("descr must be a string")
+			}
+		case "fortran_order":
+			switch p.ident() {
+			case "True":
+				h.fortran = true
+			case "False":
+				h.fortran = false
+			default:
+				return fail("fortran_order must be True or False")
+			}
+		case "shape":
+			if h.shape, ok = p.tuple(); !ok {
+				return fail("shape must be a tuple of non-negative integers")
+			}
+		default:
+			return fail(fmt.Sprintf("unexpected key %q", key))
+		}
+		seen[key] = true
+		if !p.consume(',') {
+			if !p.consume('}') {
+				return fail("expected ',' or '}'")
+			}
+			break
+		}
+	}
+	for _, key := range []string{"descr", "fortran_order", "shape"} {
+		if !seen[key] {
+			return fail(fmt.Sprintf("missing key %q", key))
+		}
+	}
+	return h, nil
+}
+
+// pyLiteral is a cursor over the small subset of Python literals used in .npy headers.
+type pyLiteral struct {
+	s   string
+	pos int
+}
+
+// skip advances past whitespace.
+func (p *pyLiteral) skip() {
+	for p.pos < len(p.s) && strings.ContainsRune(" \t\r\n", rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// consume advances past `c` if it is the next non-space character.
+func (p *pyLiteral) consume(c byte) bool {
+	p.skip()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// str reads a single- or double-quoted string without escapes.
+func (p *pyLiteral) str() (string, bool) {
+	p.skip()
+	if p.pos >= len(p.s) || (p.s[p.pos] != '\'' && p.s[p.pos] != '"') {
+		return "", false
+	}
+	quote := p.s[p.pos]
+	end := strings.IndexByte(p.s[p.pos+1:], quote)
+	if end < 0 {
+		return "", false
+	}
+	v := p.s[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return v, true
+}
+
+// ident reads an identifier or integer token.
+func (p *pyLiteral) ident() string {
+	p.skip()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if !(c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// tuple reads a tuple of integers such as (), (3,) or (2, 3); Python 2 "L" suffixes are accepted.
+func (p *pyLiteral) tuple() ([]int, bool) {
+	if !p.consume('(') {
+		return nil, false
+	}
+	dims := []int{}
+	for !p.consume(')') {
+		v, err := strconv.Atoi(strings.TrimSuffix(p.ident(), "L"))
+		if err != nil || v < 0 {
+			return nil, false
+		}
+		dims = append(dims, v)
+		if !p.consume(',') {
+			if !p.consume(')') {
+				return nil, false
+			}
+			break
+		}
+	}
+	return dims, true
+}
+
+// npyDecoder returns the byte order, item size and element decoder for a dtype
+// descriptor such as "<f8", ">i4" or "|u1".
+func npyDecoder(descr string) (binary.ByteOrder, int, func(binary.ByteOrder, []byte) float64, error) {
+	if len(descr) < 3 {
+		return nil, 0, nil, fmt.Errorf("npy: unsupported dtype %q", descr)
+	}
+	var order binary.ByteOrder
+	switch descr[0] {
+	case '<', '|':
+		order = binary.LittleEndian
+	case '>':
+		order = binary.BigEndian
+	case '=':
+		order = binary.BigEndian
+		if hostLittleEndian() {
+			order = binary.LittleEndian
+		}
+	default:
+		return nil, 0, nil, fmt.Errorf("npy: unsupported byte order in dtype %q", descr)
+	}
+
+	size, err := strconv.Atoi(descr[2:])
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("npy: unsupported dtype %q", descr)
+	}
+	var decode func(binary.ByteOrder, []byte) float64
+	switch descr[1:] {
+	case "f4":
+		decode = func(o binary.ByteOrder, b []byte) float64 { return float64(math.Float32frombits(o.Uint32(b))) }
+	case "f8":
+		decode = func(o binary.ByteOrder, b []byte) float64 { return math.Float64frombits(o.Uint64(b)) }
+	case "i1":
+		decode = func(_ binary.ByteOrder, b []byte) float64 { return float64(int8(b[0])) }
+	case "i2":
+		decode = func(o binary.ByteOrder, b []byte) float64 { return float64(int16(o.Uint16(b))) }
+	case "i4":
+		decode = func(o binary.ByteOrder, b []byte) float64 { return float64(int32(o.Uint32(b))) }
+	case "i8":
+		decode = func(o binary.ByteOrder, b []byte) float64 { return float64(int64(o.Uint64(b))) }
+	case "u1", "b1":
+		decode = func(_ binary.ByteOrder, b []byte) float64 { return float64(b[0]) }
+	case "u2":
+		decode = func(o binary.ByteOrder, b []byte) float64 { return float64(o.Uint16(b)) }
+	case "u4":
+		decode = func(o binary.ByteOrder, b []byte) float64 { return float64(o.Uint32(b)) }
+	case "u8":
+		decode = func(o binary.ByteOrder, b []byte) float64 { return float64(o.Uint64(b)) }
+	default:
+		return nil, 0, nil, fmt.Errorf("npy: unsupported dtype %q (want float32/float64, integer or bool)", descr)
+	}
+	return order, size, decode, nil
+}
+
+// fortranToRowMajor reorders column-major data of shape `dims` into row-major order.
+func fortranToRowMajor(data []float64, dims []int) []float64 {
+	out := make([]float64, len(data))
+	// Fortran strides: the first axis varies fastest
+	strides := make([]int, len(dims))
+	stride := 1
+	for k, d := range dims {
+		strides[k] = stride
+		stride *= d
+	}
+	idx := make([]int, len(dims))
+	for i := range out {
+		src := 0
+		for k, v := range idx {
+			src += v * strides[k]
+		}
+		out[i] = data[src]
+		// Advance the row-major multi-index
+		for k := len(idx) - 1; k >= 0; k-- {
+			idx[k]++
+			if idx[k] < dims[k] {
+				break
+			}
+			idx[k] = 0
+		}
+	}
+	return out
+}
+
+// WriteNpy writes `m` as a little-endian float64 C-ordered .npy array, using
+// format version 1.0 unless the header needs the 4-byte length of version 2.0.
+func WriteNpy(w io.Writer, m *Matx) error {
+	if m == nil || m.Data == nil || m.Dimensions == nil {
+		return fmt.Errorf("npy: matrix is nil or empty")
+	}
+
+	shape := make([]string, len(m.Dimensions))
+	for i, d := range m.Dimensions {
+		shape[i] = strconv.Itoa(d)
+	}
+	tuple := "(" + strings.Join(shape, ", ") + ")"
+	if len(shape) == 1 {
+		tuple = "(" + shape[0] + ",)"
+	}
+	dict := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': %s, }", tuple)
+
+	// Pad with spaces and a newline so the data starts on a 64-byte boundary
+	major, lenBytes := byte(1), 2
+	if len(npyMagic)+2+lenBytes+len(dict)+1 > math.MaxUint16 {
+		major, lenBytes = 2, 4
+	}
+	total := len(npyMagic) + 2 + lenBytes + len(dict) + 1
+	header := dict + strings.Repeat(" ", (64-total%64)%64) + "\n"
+
+	var buf bytes.Buffer
+	buf.WriteString(npyMagic)
+	buf.WriteByte(major)
+	buf.WriteByte(0)
+	if major == 1 {
+		binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	} else {
+		binary.Write(&buf, binary.LittleEndian, uint32(len(header)))
+	}
+	buf.WriteString(header)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("npy: failed to write header: %w", err)
+	}
+
+	data := make([]byte, 8*len(m.Data))
+	for i, v := range m.Data {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("npy: failed to write data: %w", err)
+	}
+	return nil
+}
+
+// ReadNpyFile opens `path` and reads it with ReadNpy.
+func ReadNpyFile(path string) (*Matx, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open npy file: %w", err)
+	}
+	defer f.Close()
+	return ReadNpy(f)
+}
+
+// WriteNpyFile creates (or truncates) `path` and writes `m` to it with WriteNpy.
+func WriteNpyFile(path string, m *Matx) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create npy file: %w", err)
+	}
+	if err := WriteNpy(f, m); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadNpz reads every array of a NumPy .npz archive, as written by numpy.savez or
+// numpy.savez_compressed, keyed by name without the ".npy" suffix.
+// Entries that are not .npy files are ignored.
+func ReadNpz(r io.ReaderAt, size int64) (map[string]*Matx, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("npz: %w", err)
+	}
+	arrays := make(map[string]*Matx, len(zr.File))
+	for _, f := range zr.File {
+		name, ok := strings.CutSuffix(f.Name, ".npy")
+		if !ok {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("npz: failed to open %q: %w", f.Name, err)
+		}
+		m, err := ReadNpy(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("npz: array %q: %w", name, err)
+		}
+		arrays[name] = m
+	}
+	return arrays, nil
+}
+
+// WriteNpz writes named arrays as a .npz archive in name order, deflated when
+// `compressed` is set (like numpy.savez_compressed) and stored otherwise.
+func WriteNpz(w io.Writer, arrays map[string]*Matx, compressed bool) error {
+	names := make([]string, 0, len(arrays))
+	for name := range arrays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	method := zip.Store
+	if compressed {
+		method = zip.Deflate
+	}
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: method})
+		if err != nil {
+			return fmt.Errorf("npz: failed to add %q: %w", name, err)
+		}
+		if err := WriteNpy(fw, arrays[name]); err != nil {
+			return fmt.Errorf("npz: array %q: %w", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("npz: %w", err)
+	}
+	return nil
+}
+
+// ReadNpzFile opens `path` and reads it with ReadNpz.
+func ReadNpzFile(path string) (map[string]*Matx, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open npz file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat npz file: %w", err)
+	}
+	return ReadNpz(f, info.Size())
+}
+
+// WriteNpzFile creates (or truncates) `path` and writes the arrays to it with WriteNpz.
+func WriteNpzFile(path string, arrays map[string]*Matx, compressed bool) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create npz file: %w", err)
+	}
+	if err := WriteNpz(f, arrays, compressed); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,137 @@
+package matx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// npyBytes assembles a .npy file by hand, as NumPy would write it.
+func npyBytes(major byte, dict string, data any) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(npyMagic)
+	buf.Write([]byte{major, 0})
+	header := dict + "\n"
+	if major == 1 {
+		binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	} else {
+		binary.Write(&buf, binary.LittleEndian, uint32(len(header)))
+	}
+	buf.WriteString(header)
+	order := binary.ByteOrder(binary.LittleEndian)
+	if strings.Contains(dict, "'>") {
+		order = binary.BigEndian
+	}
+	binary.Write(&buf, order, data)
+	return buf.Bytes()
+}
+
+func TestNpy(t *testing.T) {
+	n := 1
+
+	{ // Round trip keeps shape and every bit
+		m := begin(t, n, "WriteNpy()/ReadNpy() round trip")
+		n++
+		ok := true
+		for _, dims := range [][]int{{5}, {3, 4}, {2, 3, 2}} {
+			size := 1
+			for _, d := range dims {
+				size *= d
+			}
+			data := make([]float64, size)
+			for i := range data {
+				data[i] = math.Sin(float64(i)) / 3
+			}
+			data[0] = math.Inf(-1)
+			a, _ := New(data, dims)
+			var buf bytes.Buffer
+			err := WriteNpy(&buf, a)
+			aligned := (bytes.IndexByte(buf.Bytes(), '\n')+1)%64 == 0
+			got, err2 := ReadNpy(&buf)
+			ok = ok && err == nil && err2 == nil && aligned &&
+				CheckDimensionEquality(got.Dimensions, dims) && closeSlices(got.Data, data, 0)
+		}
+		var buf bytes.Buffer
+		vec, _ := New([]float64{1, 2}, []int{2})
+		WriteNpy(&buf, vec)
+		m.end(ok && strings.Contains(buf.String(), "'shape': (2,)"))
+	}
+
+	{ // Headers from other NumPy versions, dtypes and byte orders
+		m := begin(t, n, "ReadNpy() dtypes, versions and Fortran order")
+		n++
+		want, _ := New([]float64{1, 2, 3, 4, 5, 6}, []int{2, 3})
+		cases := [][]byte{
+			npyBytes(1, "{'descr': '<f4', 'fortran_order': False, 'shape': (2, 3), }", []float32{1, 2, 3, 4, 5, 6}),
+			npyBytes(2, "{'descr': '>f8', 'fortran_order': False, 'shape': (2, 3), }", []float64{1, 2, 3, 4, 5, 6}),
+			npyBytes(3, "{'descr': '<i8', 'fortran_order': False, 'shape': (2, 3)}", []int64{1, 2, 3, 4, 5, 6}),
+			npyBytes(1, "{'descr': '>i2', 'fortran_order': False, 'shape': (2L, 3L), }", []int16{1, 2, 3, 4, 5, 6}),
+			npyBytes(1, "{'descr': '|u1', 'fortran_order': False, 'shape': (2, 3), }", []uint8{1, 2, 3, 4, 5, 6}),
+			npyBytes(1, "{'shape': (2, 3), 'fortran_order': True, 'descr': '<f8'}", []float64{1, 4, 2, 5, 3, 6}),
+		}
+		ok := true
+		for _, c := range cases {
+			got, err := ReadNpy(bytes.NewReader(c))
+			ok = ok && err == nil && denseEqual(got, want, 0)
+		}
+		neg, err1 := ReadNpy(bytes.NewReader(npyBytes(1, "{'descr': '<i4', 'fortran_order': False, 'shape': (2,), }", []int32{-7, 1 << 30})))
+		scalar, err2 := ReadNpy(bytes.NewReader(npyBytes(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (), }", []float64{2.5})))
+		cube, err3 := ReadNpy(bytes.NewReader(npyBytes(1, "{'descr': '<f8', 'fortran_order': True, 'shape': (2, 2, 2), }",
+			[]float64{0, 4, 2, 6, 1, 5, 3, 7})))
+		ok = ok && err1 == nil && err2 == nil && err3 == nil && neg.Data[0] == -7 && neg.Data[1] == 1<<30 &&
+			len(scalar.Data) == 1 && scalar.Data[0] == 2.5
+		for i, v := range cube.Data {
+			ok = ok && v == float64(i)
+		}
+		m.end(ok)
+	}
+
+	{ // Malformed input
+		m := begin(t, n, "ReadNpy() errors")
+		n++
+		bad := [][]byte{
+			[]byte("not numpy at all"),
+			npyBytes(4, "{}", []byte{}),
+			npyBytes(1, "{'descr': '<c16', 'fortran_order': False, 'shape': (1,), }", []float64{1, 0}),
+			npyBytes(1, "{'descr': '<f8', 'shape': (1,), }", []float64{1}),
+			npyBytes(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (-1,), }", []float64{1}),
+			npyBytes(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (4,), }", []float64{1, 2}),
+			npyBytes(1, "{'descr': '<f8', 'fortran_order': maybe, 'shape': (1,), }", []float64{1}),
+			npyBytes(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (2305843009213693952,), }", []float64{1}),
+			npyBytes(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (4294967296, 4294967296), }", []float64{1}),
+		}
+		ok := true
+		for _, b := range bad {
+			_, err := ReadNpy(bytes.NewReader(b))
+			ok = ok && err != nil
+		}
+		m.end(ok && WriteNpy(&bytes.Buffer{}, nil) != nil)
+	}
+
+	{ // Archives of named arrays, stored and compressed
+		m := begin(t, n, "WriteNpz()/ReadNpz()")
+		n++
+		a := randomSparse(4, 3, 1)
+		b, _ := New([]float64{1, 2, 3}, []int{3})
+		ok := true
+		for _, compressed := range []bool{false, true} {
+			var buf bytes.Buffer
+			err := WriteNpz(&buf, map[string]*Matx{"weights": a, "bias": b}, compressed)
+			got, err2 := ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			ok = ok && err == nil && err2 == nil && len(got) == 2 &&
+				denseEqual(got["weights"], a, 0) && closeSlices(got["bias"].Data, b.Data, 0)
+		}
+		path := filepath.Join(t.TempDir(), "arrays.npz")
+		err := WriteNpzFile(path, map[string]*Matx{"x": b}, true)
+		got, err2 := ReadNpzFile(path)
+		single := filepath.Join(t.TempDir(), "a.npy")
+		err3 := WriteNpyFile(single, a)
+		one, err4 := ReadNpyFile(single)
+		_, errZip := ReadNpz(bytes.NewReader([]byte("nope")), 4)
+		m.end(ok && err == nil && err2 == nil && err3 == nil && err4 == nil && errZip != nil &&
+			closeSlices(got["x"].Data, b.Data, 0) && denseEqual(one, a, 0))
+	}
+}
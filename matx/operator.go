@@ -0,0 +1,440 @@
+package matx
+
+import "fmt"
+
+// Operator is the minimal contract the iterative solvers need from a linear map:
+// its shape and the ability to compute dst = A·x. Dense matrices (via DenseOperator),
+// sparse CSR matrices and plain functions (via OperatorFunc) all satisfy it, so A
+// never has to be stored explicitly.
+type Operator interface {
+	// Dims returns the number of rows and columns of the operator.
+	Dims() (rows, cols int)
+	// Apply writes A·x into dst; len(x) must equal cols and len(dst) rows.
+	Apply(dst, x []float64) error
+}
+
+// LinearOperator is an Operator that can also apply its transpose. It is the
+// matrix-free form of a linear map: dense and sparse matrices, the structured and
+// band types, Go closures (OperatorFunc) and compositions of any of these
+// (SumOperator, ProductOperator, ScaledOperator, TransposeOperator) all satisfy it.
+type LinearOperator interface {
+	Operator
+	// ApplyTranspose writes Aᵀ·x into dst; len(x) must equal rows and len(dst) cols.
+	ApplyTranspose(dst, x []float64) error
+}
+
+// checkApply validates vector lengths for an Apply call on a rows×cols operator.
+func checkApply(rows, cols int, dst, x []float64) error {
+	if len(x) != cols {
+		return fmt.Errorf("vector length %d does not match %d columns", len(x), cols)
+	}
+	if len(dst) != rows {
+		return fmt.Errorf("destination length %d does not match %d rows", len(dst), rows)
+	}
+	return nil
+}
+
+// denseOperator adapts a 2D Matx to LinearOperator.
+type denseOperator struct {
+	m *Matx
+}
+
+// DenseOperator wraps a 2D dense matrix as a LinearOperator without copying it.
+func DenseOperator(m *Matx) (LinearOperator, error) {
+	if m == nil || len(m.Dimensions) != 2 {
+		return nil, fmt.Errorf("dense operator requires a 2D matrix")
+	}
+	return denseOperator{m: m}, nil
+}
+
+func (d denseOperator) Dims() (int, int) {
+	return d.m.Dimensions[0], d.m.Dimensions[1]
+}
+
+func (d denseOperator) Apply(dst, x []float64) error {
+	rows, cols := d.Dims()
+	if err := checkApply(rows, cols, dst, x); err != nil {
+		return err
+	}
+	for i := 0; i < rows; i++ {
+		row := d.m.Data[i*cols : (i+1)*cols]
+		sum := 0.0
+		for j, v := range row {
+			sum += v * x[j]
+		}
+		dst[i] = sum
+	}
+	return nil
+}
+
+func (d denseOperator) ApplyTranspose(dst, x []float64) error {
+	rows, cols := d.Dims()
+	if err := checkApply(cols, rows, dst, x); err != nil {
+		return err
+	}
+	for j := range dst {
+		dst[j] = 0
+	}
+	for i := 0; i < rows; i++ {
+		xi := x[i]
+		row := d.m.Data[i*cols : (i+1)*cols]
+		for j, v := range row {
+			dst[j] += v * xi
+		}
+	}
+	return nil
+}
+
+// Apply writes A·x into dst, making *CSR an Operator.
+func (s *CSR) Apply(dst, x []float64) error {
+	if err := checkApply(s.Rows, s.Cols, dst, x); err != nil {
+		return err
+	}
+	s.mulVecInto(dst, x)
+	return nil
+}
+
+// ApplyTranspose writes Aᵀ·x into dst, making *CSR a LinearOperator.
+func (s *CSR) ApplyTranspose(dst, x []float64) error {
+	if err := checkApply(s.Cols, s.Rows, dst, x); err != nil {
+		return err
+	}
+	s.mulVecTransInto(dst, x)
+	return nil
+}
+
+// Apply writes A·x into dst by scattering each column, making *CSC an Operator.
+func (s *CSC) Apply(dst, x []float64) error {
+	if err := checkApply(s.Rows, s.Cols, dst, x); err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = 0
+	}
+	for j := 0; j < s.Cols; j++ {
+		xj := x[j]
+		for k := s.ColPtr[j]; k < s.ColPtr[j+1]; k++ {
+			dst[s.RowIdx[k]] += s.Values[k] * xj
+		}
+	}
+	return nil
+}
+
+// ApplyTranspose writes Aᵀ·x into dst with one dot product per column,
+// making *CSC a LinearOperator.
+func (s *CSC) ApplyTranspose(dst, x []float64) error {
+	if err := checkApply(s.Cols, s.Rows, dst, x); err != nil {
+		return err
+	}
+	for j := 0; j < s.Cols; j++ {
+		sum := 0.0
+		for k := s.ColPtr[j]; k < s.ColPtr[j+1]; k++ {
+			sum += s.Values[k] * x[s.RowIdx[k]]
+		}
+		dst[j] = sum
+	}
+	return nil
+}
+
+// funcOperator adapts a pair of Go functions to LinearOperator.
+type funcOperator struct {
+	rows, cols int
+	apply      func(dst, x []float64)
+	applyT     func(dst, x []float64)
+}
+
+// OperatorFunc wraps `apply`, which must write A·x into dst, and `applyT`, which
+// must write Aᵀ·x into dst, as a rows×cols LinearOperator. `applyT` may be nil for
+// operators whose transpose is unknown, such as those only passed to CG or GMRES;
+// ApplyTranspose then returns an error. Vector lengths are checked before either
+// function is called.
+func OperatorFunc(rows, cols int, apply, applyT func(dst, x []float64)) LinearOperator {
+	return funcOperator{rows: rows, cols: cols, apply: apply, applyT: applyT}
+}
+
+func (f funcOperator) Dims() (int, int) {
+	return f.rows, f.cols
+}
+
+func (f funcOperator) Apply(dst, x []float64) error {
+	if err := checkApply(f.rows, f.cols, dst, x); err != nil {
+		return err
+	}
+	f.apply(dst, x)
+	return nil
+}
+
+func (f funcOperator) ApplyTranspose(dst, x []float64) error {
+	if f.applyT == nil {
+		return fmt.Errorf("operator has no transpose")
+	}
+	if err := checkApply(f.cols, f.rows, dst, x); err != nil {
+		return err
+	}
+	f.applyT(dst, x)
+	return nil
+}
+
+// NewLinearOperator adapts any Matrix to a LinearOperator. Types that already apply
+// themselves (Matx, the sparse, band and structured types) are used in place; any
+// other Matrix is expanded with ToDense first.
+func NewLinearOperator(a Matrix) (LinearOperator, error) {
+	if isNilMatrix(a) {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	switch v := a.(type) {
+	case *Matx:
+		if len(v.Dimensions) == 1 {
+			rows, cols := v.Dims()
+			return denseOperator{m: &Matx{Data: v.Data, Dimensions: []int{rows, cols}}}, nil
+		}
+		return DenseOperator(v)
+	case *COO:
+		return v.ToCSR(), nil
+	case LinearOperator:
+		return v, nil
+	}
+
+	m, err := ToDense(a)
+	if err != nil {
+		return nil, err
+	}
+	return DenseOperator(m)
+}
+
+// sumOperator is A₁ + A₂ + … applied term by term.
+type sumOperator struct {
+	ops []LinearOperator
+}
+
+// SumOperator returns the operator A₁ + A₂ + … without forming the sum.
+// Returns an error if the operators do not all have the same shape.
+func SumOperator(ops ...LinearOperator) (LinearOperator, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("sum of no operators")
+	}
+	rows, cols := ops[0].Dims()
+	for _, op := range ops[1:] {
+		if r, c := op.Dims(); r != rows || c != cols {
+			return nil, fmt.Errorf("cannot add %dx%d and %dx%d operators", rows, cols, r, c)
+		}
+	}
+	return sumOperator{ops: ops}, nil
+}
+
+func (s sumOperator) Dims() (int, int) {
+	return s.ops[0].Dims()
+}
+
+func (s sumOperator) Apply(dst, x []float64) error {
+	return s.accumulate(dst, x, LinearOperator.Apply)
+}
+
+func (s sumOperator) ApplyTranspose(dst, x []float64) error {
+	return s.accumulate(dst, x, LinearOperator.ApplyTranspose)
+}
+
+// accumulate writes the sum of apply(op, ·, x) over all terms into dst.
+func (s sumOperator) accumulate(dst, x []float64, apply func(LinearOperator, []float64, []float64) error) error {
+	if err := apply(s.ops[0], dst, x); err != nil {
+		return err
+	}
+	tmp := make([]float64, len(dst))
+	for _, op := range s.ops[1:] {
+		if err := apply(op, tmp, x); err != nil {
+			return err
+		}
+		axpyVec(1, tmp, dst)
+	}
+	return nil
+}
+
+// productOperator is A₁·A₂·…·Aₖ applied right to left.
+type productOperator struct {
+	ops []LinearOperator
+}
+
+// ProductOperator returns the operator A₁·A₂·…·Aₖ without forming the product;
+// applying it costs one application of every factor. Returns an error if the inner
+// dimensions do not match.
+func ProductOperator(ops ...LinearOperator) (LinearOperator, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("product of no operators")
+	}
+	for k := 0; k+1 < len(ops); k++ {
+		r1, c1 := ops[k].Dims()
+		r2, c2 := ops[k+1].Dims()
+		if c1 != r2 {
+			return nil, fmt.Errorf("cannot multiply %dx%d and %dx%d operators", r1, c1, r2, c2)
+		}
+	}
+	return productOperator{ops: ops}, nil
+}
+
+func (p productOperator) Dims() (int, int) {
+	rows, _ := p.ops[0].Dims()
+	_, cols := p.ops[len(p.ops)-1].Dims()
+	return rows, cols
+}
+
+func (p productOperator) Apply(dst, x []float64) error {
+	rows, cols := p.Dims()
+	if err := checkApply(rows, cols, dst, x); err != nil {
+		return err
+	}
+	cur := x
+	for k := len(p.ops) - 1; k > 0; k-- {
+		r, _ := p.ops[k].Dims()
+		next := make([]float64, r)
+		if err := p.ops[k].Apply(next, cur); err != nil {
+			return err
+		}
+		cur = next
+	}
+	return p.ops[0].Apply(dst, cur)
+}
+
+func (p productOperator) ApplyTranspose(dst, x []float64) error {
+	rows, cols := p.Dims()
+	if err := checkApply(cols, rows, dst, x); err != nil {
+		return err
+	}
+
+	// (A₁·…·Aₖ)ᵀ = Aₖᵀ·…·A₁ᵀ, so the first factor is applied first
+	cur := x
+	for k := 0; k < len(p.ops)-1; k++ {
+		_, c := p.ops[k].Dims()
+		next := make([]float64, c)
+		if err := p.ops[k].ApplyTranspose(next, cur); err != nil {
+			return err
+		}
+		cur = next
+	}
+	return p.ops[len(p.ops)-1].ApplyTranspose(dst, cur)
+}
+
+// scaledOperator is α·A.
+type scaledOperator struct {
+	alpha float64
+	op    LinearOperator
+}
+
+// ScaledOperator returns the operator α·A.
+func ScaledOperator(alpha float64, op LinearOperator) LinearOperator {
+	return scaledOperator{alpha: alpha, op: op}
+}
+
+func (s scaledOperator) Dims() (int, int) {
+	return s.op.Dims()
+}
+
+func (s scaledOperator) Apply(dst, x []float64) error {
+	if err := s.op.Apply(dst, x); err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] *= s.alpha
+	}
+	return nil
+}
+
+func (s scaledOperator) ApplyTranspose(dst, x []float64) error {
+	if err := s.op.ApplyTranspose(dst, x); err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] *= s.alpha
+	}
+	return nil
+}
+
+// transposeOperator is Aᵀ.
+type transposeOperator struct {
+	op LinearOperator
+}
+
+// TransposeOperator returns the operator Aᵀ, swapping Apply and ApplyTranspose.
+// Transposing it again returns the original operator.
+func TransposeOperator(op LinearOperator) LinearOperator {
+	if t, ok := op.(transposeOperator); ok {
+		return t.op
+	}
+	return transposeOperator{op: op}
+}
+
+func (t transposeOperator) Dims() (int, int) {
+	rows, cols := t.op.Dims()
+	return cols, rows
+}
+
+func (t transposeOperator) Apply(dst, x []float64) error {
+	return t.op.ApplyTranspose(dst, x)
+}
+
+func (t transposeOperator) ApplyTranspose(dst, x []float64) error {
+	return t.op.Apply(dst, x)
+}
+
+// ApplyMatx returns A·X for a 2D matrix X, applying the operator to each column,
+// or A·x as a 1D Matx when X is a vector. Dense operators use Multiply directly.
+func ApplyMatx(op Operator, x *Matx) (*Matx, error) {
+	if d, ok := op.(denseOperator); ok && x != nil && len(x.Dimensions) == 2 {
+		return Multiply(d.m, x)
+	}
+	return applyColumns(op, x, false)
+}
+
+// ApplyTransposeMatx returns Aᵀ·X for a 2D matrix X, or Aᵀ·x for a vector.
+func ApplyTransposeMatx(op LinearOperator, x *Matx) (*Matx, error) {
+	return applyColumns(op, x, true)
+}
+
+// applyColumns applies the operator, or its transpose, to every column of x.
+func applyColumns(op Operator, x *Matx, transpose bool) (*Matx, error) {
+	if op == nil || x == nil {
+		return nil, fmt.Errorf("operator or matrix is nil")
+	}
+	if len(x.Dimensions) != 1 && len(x.Dimensions) != 2 {
+		return nil, fmt.Errorf("operator can only be applied to 1D or 2D matrices, got %v", x.Dimensions)
+	}
+
+	outRows, inRows := op.Dims()
+	apply := op.Apply
+	if transpose {
+		lop, ok := op.(LinearOperator)
+		if !ok {
+			return nil, fmt.Errorf("operator has no transpose")
+		}
+		outRows, inRows = inRows, outRows
+		apply = lop.ApplyTranspose
+	}
+
+	k, cols := x.Dims()
+	if k != inRows {
+		return nil, fmt.Errorf("matrix has %d rows, operator expects %d", k, inRows)
+	}
+
+	dims := []int{outRows, cols}
+	if len(x.Dimensions) == 1 {
+		dims = []int{outRows}
+	}
+	out, err := Zeros(dims)
+	if err != nil {
+		return nil, err
+	}
+
+	in := make([]float64, inRows)
+	res := make([]float64, outRows)
+	for j := 0; j < cols; j++ {
+		for i := range in {
+			in[i] = x.Data[i*cols+j]
+		}
+		if err := apply(res, in); err != nil {
+			return nil, err
+		}
+		for i, v := range res {
+			out.Data[i*cols+j] = v
+		}
+	}
+	return out, nil
+}
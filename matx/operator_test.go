@@ -0,0 +1,157 @@
+package matx
+
+import (
+	"math"
+	"testing"
+)
+
+// operatorDense materializes an operator column by column, for comparisons.
+func operatorDense(op Operator) *Matx {
+	rows, cols := op.Dims()
+	id, _ := Identity(cols, cols)
+	m, _ := ApplyMatx(op, id)
+	if m == nil {
+		m, _ = Zeros([]int{rows, cols})
+	}
+	return m
+}
+
+// adjointGap returns |⟨A·x, y⟩ − ⟨x, Aᵀ·y⟩| for fixed test vectors.
+func adjointGap(op LinearOperator) float64 {
+	rows, cols := op.Dims()
+	x, y := make([]float64, cols), make([]float64, rows)
+	for i := range x {
+		x[i] = math.Sin(float64(i) + 0.5)
+	}
+	for i := range y {
+		y[i] = math.Cos(float64(2*i) + 1)
+	}
+	ax, aty := make([]float64, rows), make([]float64, cols)
+	if op.Apply(ax, x) != nil || op.ApplyTranspose(aty, y) != nil {
+		return math.Inf(1)
+	}
+	return math.Abs(dotVec(ax, y) - dotVec(x, aty))
+}
+
+func TestLinearOperator(t *testing.T) {
+	n := 1
+
+	rect := randomSparse(7, 5, 0.5)
+	square := randomSparse(5, 5, 0.6)
+
+	{ // Every adapter has a consistent transpose
+		m := begin(t, n, "NewLinearOperator() adjoint identity")
+		n++
+		csr, _ := CSRFromMatx(rect)
+		band := randomBand(6, 1, 2, 0)
+		upper, _ := TriangularFromMatx(keepTriangle(square, true), true)
+		sym, _ := NewSymmetric(4)
+		for i := range sym.Data {
+			sym.Data[i] = float64(i) - 3
+		}
+		tri, _ := NewTridiagonal([]float64{1, 2}, []float64{3, 4, 5}, []float64{6, 7})
+		diag, _ := NewDiagonal([]float64{2, -1, 3})
+		ok := true
+		for _, a := range []Matrix{rect, csr, csr.ToCSC(), csr.ToCOO(), band, upper, sym, tri, diag} {
+			op, err := NewLinearOperator(a)
+			ok = ok && err == nil && adjointGap(op) < 1e-12 && denseEqual(operatorDense(op), a, 1e-12)
+		}
+		vec, _ := New([]float64{1, 2, 3}, []int{3})
+		vop, err := NewLinearOperator(vec)
+		r, c := vop.Dims()
+		m.end(ok && err == nil && r == 3 && c == 1)
+	}
+
+	{ // Closures, with and without a transpose
+		m := begin(t, n, "OperatorFunc()")
+		n++
+		// Periodic forward difference and its adjoint
+		k := 6
+		diff := OperatorFunc(k, k, func(dst, x []float64) {
+			for i := range dst {
+				dst[i] = x[(i+1)%k] - x[i]
+			}
+		}, func(dst, x []float64) {
+			for i := range dst {
+				dst[i] = x[(i+k-1)%k] - x[i]
+			}
+		})
+		noT := OperatorFunc(k, k, func(dst, x []float64) { copy(dst, x) }, nil)
+		err := noT.ApplyTranspose(make([]float64, k), make([]float64, k))
+		errLen := diff.Apply(make([]float64, k), make([]float64, k-1))
+		m.end(adjointGap(diff) < 1e-12 && err != nil && errLen != nil)
+	}
+
+	{ // Composition matches the dense algebra
+		m := begin(t, n, "Sum/Product/Scaled/TransposeOperator()")
+		n++
+		a, _ := DenseOperator(rect)
+		b, _ := DenseOperator(square)
+		csr, _ := CSRFromMatx(square)
+
+		sum, err1 := SumOperator(b, csr, ScaledOperator(-0.5, b))
+		prod, err2 := ProductOperator(a, b, csr)
+		at := TransposeOperator(a)
+		_, errSum := SumOperator(a, b)
+		_, errProd := ProductOperator(b, a)
+
+		wantSum, _ := Clone(square)
+		for i := range wantSum.Data {
+			wantSum.Data[i] *= 1.5
+		}
+		wantProd, _ := Multiply(rect, square)
+		wantProd, _ = Multiply(wantProd, square)
+		wantT, _ := Transpose(rect)
+		ok := err1 == nil && err2 == nil && errSum != nil && errProd != nil
+		m.end(ok && denseEqual(operatorDense(sum), wantSum, 1e-12) &&
+			denseEqual(operatorDense(prod), wantProd, 1e-12) &&
+			denseEqual(operatorDense(at), wantT, 1e-12) &&
+			adjointGap(sum) < 1e-12 && adjointGap(prod) < 1e-10 &&
+			TransposeOperator(at) == a)
+	}
+
+	{ // Application to matrices and vectors
+		m := begin(t, n, "ApplyMatx()/ApplyTransposeMatx()")
+		n++
+		csr, _ := CSRFromMatx(rect)
+		x := randomSparse(5, 3, 1)
+		y := randomSparse(7, 2, 1)
+		got, err1 := ApplyMatx(csr, x)
+		want, _ := Multiply(rect, x)
+		gotT, err2 := ApplyTransposeMatx(csr, y)
+		rt, _ := Transpose(rect)
+		wantT, _ := Multiply(rt, y)
+		v, _ := New([]float64{1, 0, -1, 0, 2}, []int{5})
+		gv, err3 := ApplyMatx(csr, v)
+		_, errShape := ApplyMatx(csr, y)
+		m.end(err1 == nil && err2 == nil && err3 == nil && errShape != nil &&
+			denseEqual(got, want, 1e-12) && denseEqual(gotT, wantT, 1e-12) && len(gv.Dimensions) == 1 && len(gv.Data) == 7)
+	}
+
+	{ // Solvers consume composed operators: CG on the normal equations (AᵀA + I) x = Aᵀb
+		m := begin(t, n, "CG() on a composed operator")
+		n++
+		a, _ := CSRFromMatx(rect)
+		id, _ := NewDiagonal([]float64{1, 1, 1, 1, 1})
+		ata, _ := ProductOperator(TransposeOperator(a), a)
+		normal, _ := SumOperator(ata, id)
+		b := make([]float64, 5)
+		a.ApplyTranspose(b, []float64{1, 2, 3, 4, 5, 6, 7})
+		res, err := CG(normal, b, SolverOptions{Tol: 1e-12})
+		m.end(err == nil && relResidual(normal, b, res.X) < 1e-10)
+	}
+}
+
+// keepTriangle returns a copy of a square matrix with the lower (upper=true) or upper triangle zeroed.
+func keepTriangle(m *Matx, upper bool) *Matx {
+	c, _ := Clone(m)
+	k := m.Dimensions[0]
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			if (upper && j < i) || (!upper && j > i) {
+				c.Data[i*k+j] = 0
+			}
+		}
+	}
+	return c
+}
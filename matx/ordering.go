@@ -0,0 +1,338 @@
+package matx
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Ordering selects a fill-reducing permutation for the sparse direct solvers.
+type Ordering int
+
+const (
+	// NaturalOrdering keeps the rows and columns in their original order.
+	NaturalOrdering Ordering = iota
+	// AMDOrdering is an approximate minimum degree ordering; usually the least fill.
+	AMDOrdering
+	// RCMOrdering is reverse Cuthill–McKee, which minimizes bandwidth and profile.
+	RCMOrdering
+)
+
+// String returns the ordering name.
+func (o Ordering) String() string {
+	switch o {
+	case NaturalOrdering:
+		return "natural"
+	case AMDOrdering:
+		return "AMD"
+	case RCMOrdering:
+		return "RCM"
+	default:
+		return fmt.Sprintf("Ordering(%d)", int(o))
+	}
+}
+
+// computeOrdering returns the permutation selected by `o` for the pattern of `a`.
+func computeOrdering(a *CSR, o Ordering) ([]int, error) {
+	switch o {
+	case NaturalOrdering:
+		if a == nil || a.Rows != a.Cols {
+			return nil, fmt.Errorf("ordering requires a square matrix")
+		}
+		perm := make([]int, a.Rows)
+		for i := range perm {
+			perm[i] = i
+		}
+		return perm, nil
+	case AMDOrdering:
+		return AMDOrder(a)
+	case RCMOrdering:
+		return RCMOrder(a)
+	default:
+		return nil, fmt.Errorf("unknown ordering %v", o)
+	}
+}
+
+// symmetricPattern returns the adjacency lists of the graph of A + Aᵀ without
+// self loops, each list sorted and free of duplicates.
+func symmetricPattern(a *CSR) ([][]int, error) {
+	if a == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	if a.Rows != a.Cols {
+		return nil, fmt.Errorf("ordering requires a square matrix, got %dx%d", a.Rows, a.Cols)
+	}
+
+	n := a.Rows
+	adj := make([][]int, n)
+	for i := 0; i < n; i++ {
+		for k := a.RowPtr[i]; k < a.RowPtr[i+1]; k++ {
+			if j := a.ColIdx[k]; j != i {
+				adj[i] = append(adj[i], j)
+				adj[j] = append(adj[j], i)
+			}
+		}
+	}
+	for i := range adj {
+		sort.Ints(adj[i])
+		out := adj[i][:0]
+		for k, j := range adj[i] {
+			if k == 0 || j != adj[i][k-1] {
+				out = append(out, j)
+			}
+		}
+		adj[i] = out
+	}
+	return adj, nil
+}
+
+// invertPermutation returns inv with inv[perm[k]] = k.
+func invertPermutation(perm []int) []int {
+	inv := make([]int, len(perm))
+	for k, p := range perm {
+		inv[p] = k
+	}
+	return inv
+}
+
+// RCMOrder computes a reverse Cuthill–McKee ordering of the pattern of A + Aᵀ.
+// perm[k] is the original index placed at position k. Each connected component
+// starts from a pseudo-peripheral node found by repeated breadth-first searches.
+func RCMOrder(a *CSR) ([]int, error) {
+	adj, err := symmetricPattern(a)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(adj)
+	perm := make([]int, 0, n)
+	visited := make([]bool, n)
+	level := make([]int, n)
+
+	// bfs labels the component of `root` by distance and returns its last level
+	bfs := func(root int) []int {
+		for i := range level {
+			level[i] = -1
+		}
+		level[root] = 0
+		frontier := []int{root}
+		for {
+			var next []int
+			for _, u := range frontier {
+				for _, v := range adj[u] {
+					if level[v] < 0 && !visited[v] {
+						level[v] = level[u] + 1
+						next = append(next, v)
+					}
+				}
+			}
+			if len(next) == 0 {
+				return frontier
+			}
+			frontier = next
+		}
+	}
+
+	for {
+		// Lowest-degree unvisited node seeds the next component
+		start := -1
+		for i := 0; i < n; i++ {
+			if !visited[i] && (start < 0 || len(adj[i]) < len(adj[start])) {
+				start = i
+			}
+		}
+		if start < 0 {
+			break
+		}
+
+		// Walk to a pseudo-peripheral node: repeat while eccentricity grows
+		last := bfs(start)
+		for {
+			cand := last[0]
+			for _, v := range last {
+				if len(adj[v]) < len(adj[cand]) {
+					cand = v
+				}
+			}
+			depth := level[last[0]]
+			next := bfs(cand)
+			if level[next[0]] <= depth {
+				bfs(start)
+				break
+			}
+			start, last = cand, next
+		}
+
+		// Cuthill–McKee: BFS visiting neighbours by increasing degree
+		visited[start] = true
+		queue := []int{start}
+		for head := 0; head < len(queue); head++ {
+			u := queue[head]
+			perm = append(perm, u)
+			var nbrs []int
+			for _, v := range adj[u] {
+				if !visited[v] {
+					visited[v] = true
+					nbrs = append(nbrs, v)
+				}
+			}
+			sort.SliceStable(nbrs, func(x, y int) bool { return len(adj[nbrs[x]]) < len(adj[nbrs[y]]) })
+			queue = append(queue, nbrs...)
+		}
+	}
+
+	for i, j := 0, len(perm)-1; i < j; i, j = i+1, j-1 {
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm, nil
+}
+
+// AMDOrder computes an approximate minimum degree ordering of the pattern of A + Aᵀ.
+// perm[k] is the original index placed at position k.
+//
+// It eliminates nodes on a quotient graph, where eliminated nodes become elements
+// standing for the cliques they create, and ranks candidates by the approximate
+// external degree bound of Amestoy, Davis and Duff instead of the exact degree.
+// Supervariable detection and aggressive absorption are not performed.
+func AMDOrder(a *CSR) ([]int, error) {
+	adj, err := symmetricPattern(a)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(adj)
+	vars := make([][]int, n)  // A_i: uneliminated neighbours
+	elems := make([][]int, n) // E_i: adjacent elements
+	members := make([][]int, n)
+	eliminated := make([]bool, n)
+	degree := make([]int, n)
+	for i := range adj {
+		vars[i] = append([]int(nil), adj[i]...)
+		degree[i] = len(adj[i])
+	}
+
+	// Degree buckets as doubly linked lists for O(1) minimum lookup and updates
+	head := make([]int, n+1)
+	next := make([]int, n)
+	prev := make([]int, n)
+	for d := range head {
+		head[d] = -1
+	}
+	insert := func(i int) {
+		d := degree[i]
+		prev[i], next[i] = -1, head[d]
+		if head[d] >= 0 {
+			prev[head[d]] = i
+		}
+		head[d] = i
+	}
+	remove := func(i int) {
+		if prev[i] >= 0 {
+			next[prev[i]] = next[i]
+		} else {
+			head[degree[i]] = next[i]
+		}
+		if next[i] >= 0 {
+			prev[next[i]] = prev[i]
+		}
+	}
+	for i := 0; i < n; i++ {
+		insert(i)
+	}
+
+	mark := make([]int, n)
+	for i := range mark {
+		mark[i] = -1
+	}
+	external := make([]int, n) // |L_e \ L_p| scratch, valid when stamp matches
+	stamp := make([]int, n)
+	for i := range stamp {
+		stamp[i] = -1
+	}
+	absorbed := make([]bool, n)
+
+	perm := make([]int, 0, n)
+	minDeg := 0
+	for k := 0; k < n; k++ {
+		for head[minDeg] < 0 {
+			minDeg++
+		}
+		p := head[minDeg]
+		remove(p)
+		eliminated[p] = true
+		perm = append(perm, p)
+
+		// L_p = (A_p ∪ ⋃_{e∈E_p} L_e) \ {p}; elements of E_p are absorbed into p
+		mark[p] = k
+		var lp []int
+		for _, v := range vars[p] {
+			if !eliminated[v] && mark[v] != k {
+				mark[v] = k
+				lp = append(lp, v)
+			}
+		}
+		for _, e := range elems[p] {
+			if absorbed[e] {
+				continue
+			}
+			for _, v := range members[e] {
+				if !eliminated[v] && mark[v] != k {
+					mark[v] = k
+					lp = append(lp, v)
+				}
+			}
+			absorbed[e] = true
+			members[e] = nil
+		}
+		members[p] = lp
+		vars[p], elems[p] = nil, nil
+
+		// |L_e \ L_p| for every element touching L_p
+		for _, i := range lp {
+			for _, e := range elems[i] {
+				if absorbed[e] {
+					continue
+				}
+				if stamp[e] != k {
+					stamp[e] = k
+					external[e] = len(members[e])
+				}
+				external[e]--
+			}
+		}
+
+		// Prune the quotient graph and update approximate degrees of L_p
+		for _, i := range lp {
+			keptElems := elems[i][:0]
+			for _, e := range elems[i] {
+				if !absorbed[e] {
+					keptElems = append(keptElems, e)
+				}
+			}
+			elems[i] = append(keptElems, p)
+
+			keptVars := vars[i][:0]
+			for _, v := range vars[i] {
+				if !eliminated[v] && mark[v] != k {
+					keptVars = append(keptVars, v)
+				}
+			}
+			vars[i] = keptVars
+
+			bound := len(vars[i]) + len(lp) - 1
+			for _, e := range elems[i] {
+				if e != p {
+					bound += external[e]
+				}
+			}
+			bound = min(bound, degree[i]+len(lp)-1, n-k-2)
+			bound = max(bound, 0)
+
+			remove(i)
+			degree[i] = bound
+			insert(i)
+			minDeg = min(minDeg, bound)
+		}
+	}
+
+	return perm, nil
+}
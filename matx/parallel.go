@@ -0,0 +1,50 @@
+package matx
+
+import (
+	"runtime"
+	"sync"
+)
+
+// resolveWorkers turns a requested worker count into a usable one.
+// Non-positive values fall back to GOMAXPROCS.
+func resolveWorkers(workers int) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// parallelFor splits the half-open range [0, n) into at most `workers` contiguous
+// spans and runs `fn(lo, hi)` for each span on its own goroutine.
+// Runs inline when a single worker (or a single span) is enough.
+func parallelFor(n, workers int, fn func(lo, hi int)) {
+	if n <= 0 {
+		return
+	}
+	workers = resolveWorkers(workers)
+	if workers > n {
+		workers = n
+	}
+	if workers == 1 {
+		fn(0, n)
+		return
+	}
+
+	span := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := 0; lo < n; lo += span {
+		hi := lo + span
+		if hi > n {
+			hi = n
+		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			fn(lo, hi)
+		}(lo, hi)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,322 @@
+package matx
+
+import (
+	"fmt"
+	"math"
+)
+
+// Jacobi is the diagonal preconditioner M = diag(A).
+type Jacobi struct {
+	invDiag []float64
+}
+
+// SSOR is the symmetric successive over-relaxation preconditioner
+// M = ω/(2−ω) · (D/ω + L) · (D/ω)⁻¹ · (D/ω + U), where A = L + D + U.
+type SSOR struct {
+	a     *CSR
+	diag  []int // position of the diagonal entry in each row
+	omega float64
+}
+
+// ILU0 is the incomplete LU factorization with zero fill-in: L and U keep exactly
+// the sparsity pattern of A. Factors are packed like LU.Factors, unit L below the diagonal.
+type ILU0 struct {
+	lu   *CSR
+	diag []int
+}
+
+// IC0 is the incomplete Cholesky factorization with zero fill-in, A ≈ L·Lᵀ,
+// where L keeps the pattern of the lower triangle of A. A must be symmetric.
+type IC0 struct {
+	l    *CSR // lower triangle, diagonal stored last in each row
+	diag []int
+}
+
+// NewJacobi builds a Jacobi preconditioner from a square sparse matrix.
+// Returns an error if any diagonal entry is zero.
+func NewJacobi(a *CSR) (*Jacobi, error) {
+	diag, err := diagonalPositions(a)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := make([]float64, a.Rows)
+	for i, k := range diag {
+		inv[i] = 1 / a.Values[k]
+	}
+	return &Jacobi{invDiag: inv}, nil
+}
+
+// NewJacobiDense builds a Jacobi preconditioner from a square dense matrix.
+func NewJacobiDense(m *Matx) (*Jacobi, error) {
+	a, err := CSRFromMatx(m)
+	if err != nil {
+		return nil, err
+	}
+	return NewJacobi(a)
+}
+
+// Precondition writes diag(A)⁻¹·r into dst.
+func (p *Jacobi) Precondition(dst, r []float64) error {
+	if err := checkApply(len(p.invDiag), len(p.invDiag), dst, r); err != nil {
+		return err
+	}
+	for i, d := range p.invDiag {
+		dst[i] = d * r[i]
+	}
+	return nil
+}
+
+// NewSSOR builds an SSOR preconditioner with relaxation factor ω in (0, 2).
+// ω = 1 gives symmetric Gauss–Seidel. The matrix is referenced, not copied.
+func NewSSOR(a *CSR, omega float64) (*SSOR, error) {
+	if omega <= 0 || omega >= 2 {
+		return nil, fmt.Errorf("SSOR relaxation factor must be in (0, 2), got %v", omega)
+	}
+	diag, err := diagonalPositions(a)
+	if err != nil {
+		return nil, err
+	}
+	return &SSOR{a: a, diag: diag, omega: omega}, nil
+}
+
+// NewSSORDense builds an SSOR preconditioner from a square dense matrix.
+func NewSSORDense(m *Matx, omega float64) (*SSOR, error) {
+	a, err := CSRFromMatx(m)
+	if err != nil {
+		return nil, err
+	}
+	return NewSSOR(a, omega)
+}
+
+// Precondition writes M⁻¹·r into dst with one forward and one backward sweep.
+func (p *SSOR) Precondition(dst, r []float64) error {
+	a := p.a
+	if err := checkApply(a.Rows, a.Rows, dst, r); err != nil {
+		return err
+	}
+	w := p.omega
+
+	// Forward sweep: (D/ω + L)·y = r
+	for i := 0; i < a.Rows; i++ {
+		sum := r[i]
+		for k := a.RowPtr[i]; k < p.diag[i]; k++ {
+			sum -= a.Values[k] * dst[a.ColIdx[k]]
+		}
+		dst[i] = sum * w / a.Values[p.diag[i]]
+	}
+
+	// Scale by D/ω, then backward sweep: (D/ω + U)·z = (D/ω)·y
+	for i := a.Rows - 1; i >= 0; i-- {
+		d := a.Values[p.diag[i]] / w
+		sum := d * dst[i]
+		for k := p.diag[i] + 1; k < a.RowPtr[i+1]; k++ {
+			sum -= a.Values[k] * dst[a.ColIdx[k]]
+		}
+		dst[i] = sum / d
+	}
+
+	scale := (2 - w) / w
+	for i := range dst {
+		dst[i] *= scale
+	}
+	return nil
+}
+
+// NewILU0 computes the ILU(0) factorization of a square sparse matrix.
+// Returns an error if a zero pivot is met; the input is not modified.
+func NewILU0(a *CSR) (*ILU0, error) {
+	diag, err := diagonalPositions(a)
+	if err != nil {
+		return nil, err
+	}
+
+	lu := &CSR{
+		Rows:   a.Rows,
+		Cols:   a.Cols,
+		RowPtr: a.RowPtr,
+		ColIdx: a.ColIdx,
+		Values: append([]float64(nil), a.Values...),
+	}
+	pos := make([]int, a.Cols)
+	for j := range pos {
+		pos[j] = -1
+	}
+
+	// IKJ variant restricted to the existing pattern
+	for i := 0; i < a.Rows; i++ {
+		for k := a.RowPtr[i]; k < a.RowPtr[i+1]; k++ {
+			pos[a.ColIdx[k]] = k
+		}
+		for k := a.RowPtr[i]; k < diag[i]; k++ {
+			col := a.ColIdx[k]
+			pivot := lu.Values[diag[col]]
+			if pivot == 0 {
+				return nil, fmt.Errorf("ILU(0) zero pivot in row %d", col)
+			}
+			l := lu.Values[k] / pivot
+			lu.Values[k] = l
+			for p := diag[col] + 1; p < a.RowPtr[col+1]; p++ {
+				if q := pos[a.ColIdx[p]]; q >= 0 {
+					lu.Values[q] -= l * lu.Values[p]
+				}
+			}
+		}
+		for k := a.RowPtr[i]; k < a.RowPtr[i+1]; k++ {
+			pos[a.ColIdx[k]] = -1
+		}
+		if lu.Values[diag[i]] == 0 {
+			return nil, fmt.Errorf("ILU(0) zero pivot in row %d", i)
+		}
+	}
+
+	return &ILU0{lu: lu, diag: diag}, nil
+}
+
+// NewILU0Dense computes ILU(0) over the nonzero pattern of a square dense matrix.
+func NewILU0Dense(m *Matx) (*ILU0, error) {
+	a, err := CSRFromMatx(m)
+	if err != nil {
+		return nil, err
+	}
+	return NewILU0(a)
+}
+
+// Precondition writes (L·U)⁻¹·r into dst.
+func (p *ILU0) Precondition(dst, r []float64) error {
+	lu := p.lu
+	if err := checkApply(lu.Rows, lu.Rows, dst, r); err != nil {
+		return err
+	}
+
+	for i := 0; i < lu.Rows; i++ {
+		sum := r[i]
+		for k := lu.RowPtr[i]; k < p.diag[i]; k++ {
+			sum -= lu.Values[k] * dst[lu.ColIdx[k]]
+		}
+		dst[i] = sum
+	}
+	for i := lu.Rows - 1; i >= 0; i-- {
+		sum := dst[i]
+		for k := p.diag[i] + 1; k < lu.RowPtr[i+1]; k++ {
+			sum -= lu.Values[k] * dst[lu.ColIdx[k]]
+		}
+		dst[i] = sum / lu.Values[p.diag[i]]
+	}
+	return nil
+}
+
+// NewIC0 computes the IC(0) factorization of a symmetric positive definite sparse matrix.
+// Only the lower triangle of `a` is read. Returns an error if a non-positive pivot
+// appears, which happens for indefinite matrices and some badly conditioned SPD ones.
+func NewIC0(a *CSR) (*IC0, error) {
+	diag, err := diagonalPositions(a)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy the lower triangle; the diagonal is the last entry of each row
+	l := &CSR{Rows: a.Rows, Cols: a.Cols, RowPtr: make([]int, a.Rows+1)}
+	for i := 0; i < a.Rows; i++ {
+		l.ColIdx = append(l.ColIdx, a.ColIdx[a.RowPtr[i]:diag[i]+1]...)
+		l.Values = append(l.Values, a.Values[a.RowPtr[i]:diag[i]+1]...)
+		l.RowPtr[i+1] = len(l.ColIdx)
+	}
+
+	ldiag := make([]int, a.Rows)
+	for i := 0; i < a.Rows; i++ {
+		start, end := l.RowPtr[i], l.RowPtr[i+1]-1
+		for k := start; k < end; k++ {
+			col := l.ColIdx[k]
+			// l_ik = (a_ik − Σ_{j<col} l_ij·l_col,j) / l_col,col over the shared pattern
+			sum := l.Values[k]
+			p, q := start, l.RowPtr[col]
+			for p < k && q < ldiag[col] {
+				switch {
+				case l.ColIdx[p] < l.ColIdx[q]:
+					p++
+				case l.ColIdx[p] > l.ColIdx[q]:
+					q++
+				default:
+					sum -= l.Values[p] * l.Values[q]
+					p++
+					q++
+				}
+			}
+			l.Values[k] = sum / l.Values[ldiag[col]]
+		}
+
+		d := l.Values[end]
+		for k := start; k < end; k++ {
+			d -= l.Values[k] * l.Values[k]
+		}
+		if d <= 0 || math.IsNaN(d) {
+			return nil, fmt.Errorf("IC(0) breakdown: non-positive pivot in row %d", i)
+		}
+		l.Values[end] = math.Sqrt(d)
+		ldiag[i] = end
+	}
+
+	return &IC0{l: l, diag: ldiag}, nil
+}
+
+// NewIC0Dense computes IC(0) over the nonzero pattern of a symmetric dense matrix.
+func NewIC0Dense(m *Matx) (*IC0, error) {
+	a, err := CSRFromMatx(m)
+	if err != nil {
+		return nil, err
+	}
+	return NewIC0(a)
+}
+
+// Precondition writes (L·Lᵀ)⁻¹·r into dst.
+func (p *IC0) Precondition(dst, r []float64) error {
+	l := p.l
+	if err := checkApply(l.Rows, l.Rows, dst, r); err != nil {
+		return err
+	}
+
+	// L·y = r
+	for i := 0; i < l.Rows; i++ {
+		sum := r[i]
+		for k := l.RowPtr[i]; k < p.diag[i]; k++ {
+			sum -= l.Values[k] * dst[l.ColIdx[k]]
+		}
+		dst[i] = sum / l.Values[p.diag[i]]
+	}
+
+	// Lᵀ·z = y, scattering each solved component into the rows above
+	for i := l.Rows - 1; i >= 0; i-- {
+		dst[i] /= l.Values[p.diag[i]]
+		for k := l.RowPtr[i]; k < p.diag[i]; k++ {
+			dst[l.ColIdx[k]] -= l.Values[k] * dst[i]
+		}
+	}
+	return nil
+}
+
+// diagonalPositions locates the diagonal entry of every row of a square CSR matrix.
+// Returns an error if the matrix is not square or a diagonal entry is missing or zero.
+func diagonalPositions(a *CSR) ([]int, error) {
+	if a == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	if a.Rows != a.Cols {
+		return nil, fmt.Errorf("preconditioner requires a square matrix, got %dx%d", a.Rows, a.Cols)
+	}
+
+	diag := make([]int, a.Rows)
+	for i := 0; i < a.Rows; i++ {
+		diag[i] = -1
+		for k := a.RowPtr[i]; k < a.RowPtr[i+1]; k++ {
+			if a.ColIdx[k] == i {
+				diag[i] = k
+				break
+			}
+		}
+		if diag[i] < 0 || a.Values[diag[i]] == 0 {
+			return nil, fmt.Errorf("zero diagonal entry in row %d", i)
+		}
+	}
+	return diag, nil
+}
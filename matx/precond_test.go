@@ -0,0 +1,81 @@
+package matx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPreconditioners(t *testing.T) {
+	n := 1
+
+	spd := laplacian2D(16, 0)
+	nonsym := laplacian2D(16, 0.4)
+	b := make([]float64, spd.Rows)
+	for i := range b {
+		b[i] = math.Cos(float64(i))
+	}
+	plain, _ := CG(spd, b, SolverOptions{Tol: 1e-10})
+
+	{ // Preconditioned CG needs fewer iterations
+		m := begin(t, n, "CG() with Jacobi/SSOR/IC0")
+		n++
+		jac, err1 := NewJacobi(spd)
+		ssor, err2 := NewSSOR(spd, 1.2)
+		ic, err3 := NewIC0(spd)
+		ok := err1 == nil && err2 == nil && err3 == nil
+		for _, p := range []Preconditioner{jac, ssor, ic} {
+			if !ok {
+				break
+			}
+			res, err := CG(spd, b, SolverOptions{Tol: 1e-10, Precond: p})
+			ok = err == nil && relResidual(spd, b, res.X) < 1e-9 && res.Iterations <= plain.Iterations
+		}
+		res, _ := CG(spd, b, SolverOptions{Tol: 1e-10, Precond: ic})
+		m.end(ok && res.Iterations < plain.Iterations)
+	}
+
+	{ // ILU(0) with GMRES and BiCGSTAB
+		m := begin(t, n, "GMRES()/BiCGSTAB() with ILU0")
+		n++
+		ilu, err := NewILU0(nonsym)
+		base, _ := GMRES(nonsym, b, SolverOptions{Tol: 1e-10})
+		res1, err1 := GMRES(nonsym, b, SolverOptions{Tol: 1e-10, Precond: ilu})
+		res2, err2 := BiCGSTAB(nonsym, b, SolverOptions{Tol: 1e-10, Precond: ilu})
+		m.end(err == nil && err1 == nil && err2 == nil &&
+			res1.Iterations < base.Iterations &&
+			relResidual(nonsym, b, res1.X) < 1e-9 && relResidual(nonsym, b, res2.X) < 1e-9)
+	}
+
+	{ // Zero fill-in factorizations are exact on tridiagonal matrices
+		m := begin(t, n, "ILU0/IC0 exact on tridiagonal")
+		n++
+		dense, _ := Zeros([]int{6, 6})
+		for i := 0; i < 6; i++ {
+			dense.Data[i*6+i] = 4
+			if i > 0 {
+				dense.Data[i*6+i-1] = -1
+				dense.Data[(i-1)*6+i] = -1
+			}
+		}
+		ilu, err1 := NewILU0Dense(dense)
+		ic, err2 := NewIC0Dense(dense)
+		rhs := []float64{1, 2, 3, 4, 5, 6}
+		x1 := make([]float64, 6)
+		x2 := make([]float64, 6)
+		ilu.Precondition(x1, rhs)
+		ic.Precondition(x2, rhs)
+		op, _ := DenseOperator(dense)
+		m.end(err1 == nil && err2 == nil && relResidual(op, rhs, x1) < 1e-14 && relResidual(op, rhs, x2) < 1e-14)
+	}
+
+	{ // Invalid inputs
+		m := begin(t, n, "Preconditioner validation")
+		n++
+		noDiag, _ := NewCSR(2, 2, []int{0, 1, 2}, []int{1, 0}, []float64{1, 1})
+		indefinite, _ := CSRFromMatx(&Matx{Data: []float64{1, 2, 2, 1}, Dimensions: []int{2, 2}})
+		_, err1 := NewJacobi(noDiag)
+		_, err2 := NewSSOR(spd, 2)
+		_, err3 := NewIC0(indefinite)
+		m.end(err1 != nil && err2 != nil && err3 != nil)
+	}
+}
@@ -0,0 +1,279 @@
+package matx
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PrintOptions controls how PrintMatx, Fprint and the fmt verbs render matrices.
+// Zero values select defaults.
+//   - Format is a PrintMatx alias ("int", "float", "f", "short", "sci", "g") or fmt
+//     verb for elements (default "%.4f").
+//   - Threshold is the element count above which long axes are summarized (default 1000).
+//   - EdgeItems is the number of entries kept at each end of a summarized axis (default 3).
+type PrintOptions struct {
+	Format    string
+	Threshold int
+	EdgeItems int
+}
+
+var (
+	printMu      sync.RWMutex
+	printOptions = DefaultPrintOptions()
+)
+
+// DefaultPrintOptions returns the options used when none have been set: four
+// decimals, and summaries for matrices of more than 1000 elements showing three
+// entries at each end of an axis, as NumPy does.
+func DefaultPrintOptions() PrintOptions {
+	return PrintOptions{Format: "%.4f", Threshold: 1000, EdgeItems: 3}
+}
+
+// SetPrintOptions replaces the package-wide print options.
+func SetPrintOptions(opts PrintOptions) {
+	printMu.Lock()
+	printOptions = opts
+	printMu.Unlock()
+}
+
+// CurrentPrintOptions returns the package-wide print options.
+func CurrentPrintOptions() PrintOptions {
+	printMu.RLock()
+	defer printMu.RUnlock()
+	return printOptions
+}
+
+// Fprint writes `m` to `w` in the nested-brace layout of PrintMatx, followed by a
+// newline. The optional `format` overrides the element format of the package
+// print options.
+func Fprint(w io.Writer, m *Matx, format ...string) error {
+	opts := CurrentPrintOptions()
+	opts.Format = resolveFormat(opts.Format, format...)
+	return FprintWith(w, m, opts)
+}
+
+// FprintWith is Fprint with explicit options instead of the package defaults.
+func FprintWith(w io.Writer, m *Matx, opts PrintOptions) error {
+	p, err := newMatxPrinter(m, opts)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	p.render(&b)
+	b.WriteByte('\n')
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to print matrix: %w", err)
+	}
+	return nil
+}
+
+// String implements fmt.Stringer with the package print options.
+func (m *Matx) String() string {
+	if m == nil {
+		return "<nil>"
+	}
+	p, err := newMatxPrinter(m, CurrentPrintOptions())
+	if err != nil {
+		return "<invalid Matx: " + err.Error() + ">"
+	}
+	var b strings.Builder
+	p.render(&b)
+	return b.String()
+}
+
+// Format implements fmt.Formatter. %v and %s use the package print options; %e, %E,
+// %f, %F, %g and %G format every element with that verb and %d rounds to integers.
+// Width is the minimum width of each column and precision the element precision
+// (%v with a precision uses %g). The '+' and ' ' flags apply to every element and
+// '-' left-aligns the columns. %#v prints the Go syntax of the struct.
+func (m *Matx) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		if m == nil {
+			io.WriteString(f, "(*matx.Matx)(nil)")
+			return
+		}
+		fmt.Fprintf(f, "&%#v", *m)
+		return
+	}
+	if m == nil {
+		io.WriteString(f, "<nil>")
+		return
+	}
+
+	opts := CurrentPrintOptions()
+	prec, hasPrec := f.Precision()
+	switch verb {
+	case 'v', 's':
+		if hasPrec {
+			opts.Format = "%." + strconv.Itoa(prec) + "g"
+		}
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		opts.Format = "%" + string(verb)
+		if hasPrec {
+			opts.Format = "%." + strconv.Itoa(prec) + string(verb)
+		}
+	case 'd':
+		opts.Format = "%.0f"
+	default:
+		fmt.Fprintf(f, "%%!%c(*matx.Matx=%v)", verb, m.Dimensions)
+		return
+	}
+	opts.Format = resolveFormat("%.4f", opts.Format)
+	for _, flag := range "+ " {
+		if f.Flag(int(flag)) && strings.HasPrefix(opts.Format, "%") {
+			opts.Format = "%" + string(flag) + opts.Format[1:]
+		}
+	}
+
+	p, err := newMatxPrinter(m, opts)
+	if err != nil {
+		fmt.Fprintf(f, "%%!%c(*matx.Matx=%v)", verb, err)
+		return
+	}
+	p.width, _ = f.Width()
+	p.left = f.Flag('-')
+	var b strings.Builder
+	p.render(&b)
+	io.WriteString(f, b.String())
+}
+
+// matxPrinter renders a matrix as nested braces with right-aligned columns,
+// summarizing long axes with "..." once the matrix exceeds the threshold.
+type matxPrinter struct {
+	m     *Matx
+	verb  string
+	edge  int     // entries kept at each end of an axis; 0 shows everything
+	width int     // minimum column width
+	left  bool    // left-align instead of right-align
+	shown [][]int // displayed indices per axis, -1 for "..."
+	cells []string
+}
+
+// newMatxPrinter validates `m` and resolves the options.
+func newMatxPrinter(m *Matx, opts PrintOptions) (*matxPrinter, error) {
+	if m == nil || m.Data == nil || m.Dimensions == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	size := 1
+	for _, d := range m.Dimensions {
+		size *= d
+	}
+	if size != len(m.Data) {
+		return nil, fmt.Errorf("data size %d does not match shape %v", len(m.Data), m.Dimensions)
+	}
+	def := DefaultPrintOptions()
+	if opts.Threshold <= 0 {
+		opts.Threshold = def.Threshold
+	}
+	if opts.EdgeItems <= 0 {
+		opts.EdgeItems = def.EdgeItems
+	}
+	p := &matxPrinter{m: m, verb: resolveFormat(def.Format, opts.Format)}
+	if len(m.Data) > opts.Threshold {
+		p.edge = opts.EdgeItems
+	}
+
+	p.shown = make([][]int, len(m.Dimensions))
+	for axis, d := range m.Dimensions {
+		if p.edge > 0 && d > 2*p.edge {
+			for i := 0; i < p.edge; i++ {
+				p.shown[axis] = append(p.shown[axis], i)
+			}
+			p.shown[axis] = append(p.shown[axis], -1)
+			for i := d - p.edge; i < d; i++ {
+				p.shown[axis] = append(p.shown[axis], i)
+			}
+			continue
+		}
+		for i := 0; i < d; i++ {
+			p.shown[axis] = append(p.shown[axis], i)
+		}
+	}
+	return p, nil
+}
+
+// render formats the displayed elements, then lays them out padded to a common width.
+func (p *matxPrinter) render(b *strings.Builder) {
+	p.cells = p.cells[:0]
+	p.collect(0, 0)
+	width := p.width
+	for _, c := range p.cells {
+		width = max(width, len(c))
+	}
+	next := 0
+	p.write(b, 0, 0, 0, width, &next)
+}
+
+// collect formats the displayed elements in display order.
+func (p *matxPrinter) collect(offset, axis int) {
+	if axis == len(p.m.Dimensions) {
+		p.cells = append(p.cells, fmt.Sprintf(p.verb, p.m.Data[offset]))
+		return
+	}
+	stride := p.stride(axis)
+	for _, i := range p.shown[axis] {
+		if i >= 0 {
+			p.collect(offset+i*stride, axis+1)
+		}
+	}
+}
+
+// stride returns the number of elements spanned by one step along `axis`.
+func (p *matxPrinter) stride(axis int) int {
+	stride := 1
+	for _, d := range p.m.Dimensions[axis+1:] {
+		stride *= d
+	}
+	return stride
+}
+
+// write lays out the sub-array at `offset` along `axis`, consuming cells in order.
+func (p *matxPrinter) write(b *strings.Builder, offset, axis, depth, width int, next *int) {
+	dims := p.m.Dimensions
+	if axis == len(dims) {
+		cell := p.cells[*next]
+		*next++
+		pad := strings.Repeat(" ", width-len(cell))
+		if p.left {
+			b.WriteString(cell + pad)
+		} else {
+			b.WriteString(pad + cell)
+		}
+		return
+	}
+
+	indent := strings.Repeat("  ", depth)
+	stride := p.stride(axis)
+	if axis == len(dims)-1 {
+		// Innermost axis: one row of cells
+		b.WriteString(indent + "{")
+		for k, i := range p.shown[axis] {
+			if k > 0 {
+				b.WriteString(", ")
+			}
+			if i < 0 {
+				b.WriteString("...")
+				continue
+			}
+			p.write(b, offset+i, axis+1, 0, width, next)
+		}
+		b.WriteString("}")
+		return
+	}
+
+	b.WriteString(indent + "{\n")
+	for k, i := range p.shown[axis] {
+		if k > 0 {
+			b.WriteString(",\n")
+		}
+		if i < 0 {
+			b.WriteString(indent + "  ...")
+			continue
+		}
+		p.write(b, offset+i*stride, axis+1, depth+1, width, next)
+	}
+	b.WriteString("\n" + indent + "}")
+}
@@ -0,0 +1,101 @@
+package matx
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPrint(t *testing.T) {
+	n := 1
+
+	a, _ := New([]float64{1, -2.5, 300, 4, 5, math.NaN()}, []int{2, 3})
+
+	{ // Columns are right-aligned to the widest element
+		m := begin(t, n, "Fprint() alignment")
+		n++
+		var buf bytes.Buffer
+		err := Fprint(&buf, a, "short")
+		InitExamples()
+		cube, _ := GiveMatx("matxCube2x2x2")
+		var cubeBuf bytes.Buffer
+		err2 := FprintWith(&cubeBuf, cube, PrintOptions{Format: "int"})
+		scalar, _ := New([]float64{2.5}, []int{})
+		var scalarBuf bytes.Buffer
+		err3 := Fprint(&scalarBuf, scalar)
+		errNil := Fprint(&buf, nil)
+		m.end(err == nil && err2 == nil && err3 == nil && errNil != nil &&
+			buf.String() == "{\n  {  1.00,  -2.50, 300.00},\n  {  4.00,   5.00,    NaN}\n}\n" &&
+			cubeBuf.String() == "{\n  {\n    {1, 2},\n    {3, 4}\n  },\n  {\n    {5, 6},\n    {7, 8}\n  }\n}\n" &&
+			scalarBuf.String() == "2.5000\n")
+	}
+
+	{ // Large matrices keep the edge items of each axis
+		m := begin(t, n, "Fprint() summarization")
+		n++
+		data := make([]float64, 1000*1000)
+		for i := range data {
+			data[i] = float64(i % 1000)
+		}
+		big, _ := New(data, []int{1000, 1000})
+		var buf bytes.Buffer
+		err := Fprint(&buf, big, "int")
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		vec, _ := New(append(data[:1000:1000], 1000), []int{1001})
+		small, _ := New(data[:1000], []int{1000})
+		var custom bytes.Buffer
+		err2 := FprintWith(&custom, small, PrintOptions{Format: "int", Threshold: 10, EdgeItems: 2})
+		m.end(err == nil && err2 == nil && len(lines) == 9 && lines[4] == "  ...," &&
+			lines[1] == "  {  0,   1,   2, ..., 997, 998, 999}," &&
+			fmt.Sprintf("%.0f", vec) == "{   0,    1,    2, ...,  998,  999, 1000}" &&
+			!strings.Contains(fmt.Sprintf("%.0f", small), "...") &&
+			custom.String() == "{  0,   1, ..., 998, 999}\n")
+	}
+
+	{ // fmt verbs, width, precision and flags
+		m := begin(t, n, "Format() verbs")
+		n++
+		b, _ := New([]float64{1, -2.5, 30}, []int{3})
+		var nilMatx *Matx
+		cases := [][2]string{
+			{fmt.Sprintf("%v", b), "{ 1.0000, -2.5000, 30.0000}"},
+			{fmt.Sprintf("%s", b), "{ 1.0000, -2.5000, 30.0000}"},
+			{fmt.Sprintf("%.1f", b), "{ 1.0, -2.5, 30.0}"},
+			{fmt.Sprintf("%6.1f", b), "{   1.0,   -2.5,   30.0}"},
+			{fmt.Sprintf("%-6.1f", b), "{1.0   , -2.5  , 30.0  }"},
+			{fmt.Sprintf("%+.1f", b), "{ +1.0,  -2.5, +30.0}"},
+			{fmt.Sprintf("%.2e", b), "{ 1.00e+00, -2.50e+00,  3.00e+01}"},
+			{fmt.Sprintf("%.3v", b), "{   1, -2.5,   30}"},
+			{fmt.Sprintf("%d", b), "{ 1, -2, 30}"},
+			{fmt.Sprintf("%x", b), "%!x(*matx.Matx=[3])"},
+			{fmt.Sprintf("%#v", b), "&matx.Matx{Data:[]float64{1, -2.5, 30}, Dimensions:[]int{3}}"},
+			{fmt.Sprintf("%v", nilMatx), "<nil>"},
+			{b.String(), "{ 1.0000, -2.5000, 30.0000}"},
+			{(&Matx{Data: []float64{1}, Dimensions: []int{2}}).String(), "<invalid Matx: data size 1 does not match shape [2]>"},
+		}
+		ok := true
+		for _, c := range cases {
+			if got, want := c[0], c[1]; got != want {
+				t.Logf("got %q, want %q", got, want)
+				ok = false
+			}
+		}
+		m.end(ok)
+	}
+
+	{ // Package-wide options apply to String, the verbs and Fprint
+		m := begin(t, n, "SetPrintOptions()")
+		n++
+		saved := CurrentPrintOptions()
+		SetPrintOptions(PrintOptions{Format: "sci", Threshold: 2, EdgeItems: 1})
+		v, _ := New([]float64{1, 2, 3}, []int{3})
+		s := v.String()
+		var buf bytes.Buffer
+		Fprint(&buf, v, "int")
+		SetPrintOptions(saved)
+		m.end(s == "{1.000000e+00, ..., 3.000000e+00}" && buf.String() == "{1, ..., 3}\n" &&
+			CurrentPrintOptions() == DefaultPrintOptions())
+	}
+}
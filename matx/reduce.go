@@ -0,0 +1,244 @@
+package matx
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// SummationMethod selects the accumulation algorithm used by Sum and Mean.
+type SummationMethod int
+
+const (
+	// PairwiseSummation recursively halves the input; rounding error grows as O(log n).
+	PairwiseSummation SummationMethod = iota
+	// KahanSummation carries a running compensation term (Neumaier's variant).
+	KahanSummation
+	// NaiveSummation accumulates left to right, matching the original behaviour.
+	NaiveSummation
+)
+
+// pairwiseBase is the lane length below which pairwise summation falls back to a plain loop.
+const pairwiseBase = 128
+
+// deterministicChunk is the fixed chunk length used to split a single long lane
+// when Deterministic is set, so partial sums never depend on the worker count.
+const deterministicChunk = 1 << 14
+
+// ReduceConfig controls how axis reductions (Sum, Mean, Min, Max) are executed.
+// - Summation picks the accumulation algorithm.
+// - Workers caps the number of goroutines; 0 means GOMAXPROCS.
+// - ParallelThreshold is the element count below which reductions stay single-threaded.
+// - Deterministic guarantees bit-identical results for any Workers value.
+type ReduceConfig struct {
+	Summation         SummationMethod
+	Workers           int
+	ParallelThreshold int
+	Deterministic     bool
+}
+
+var (
+	reduceMu     sync.RWMutex
+	reduceConfig = DefaultReduceConfig()
+)
+
+// DefaultReduceConfig returns the configuration used when none has been set:
+// pairwise summation, GOMAXPROCS workers, and deterministic results.
+func DefaultReduceConfig() ReduceConfig {
+	return ReduceConfig{
+		Summation:         PairwiseSummation,
+		Workers:           0,
+		ParallelThreshold: 1 << 16,
+		Deterministic:     true,
+	}
+}
+
+// SetReduceConfig replaces the package-wide reduction configuration.
+func SetReduceConfig(cfg ReduceConfig) {
+	reduceMu.Lock()
+	reduceConfig = cfg
+	reduceMu.Unlock()
+}
+
+// CurrentReduceConfig returns the package-wide reduction configuration.
+func CurrentReduceConfig() ReduceConfig {
+	reduceMu.RLock()
+	defer reduceMu.RUnlock()
+	return reduceConfig
+}
+
+// axisLayout describes how the lanes of a single axis are laid out in row-major `Data`.
+// Lane (b, i) holds the elements b*block + j*stride + i for j in [0, length).
+type axisLayout struct {
+	stride    int // product of dimensions after the axis
+	length    int // size of the reduced axis
+	block     int // elements spanned by one full traversal of the axis
+	numBlocks int // number of blocks in the flat data slice
+	outShape  []int
+}
+
+// lanes returns the number of independent lanes, i.e. the size of the reduced output.
+func (l axisLayout) lanes() int {
+	return l.numBlocks * l.stride
+}
+
+// base returns the flat index of the first element of lane `lane`.
+func (l axisLayout) base(lane int) int {
+	return (lane/l.stride)*l.block + lane%l.stride
+}
+
+// newAxisLayout validates `axis` against `m` and computes its lane layout.
+func newAxisLayout(m *Matx, axis int) (axisLayout, error) {
+	if m == nil {
+		return axisLayout{}, fmt.Errorf("Matrix is nil")
+	}
+	if axis < 0 || axis >= len(m.Dimensions) {
+		return axisLayout{}, fmt.Errorf("Invalid axis")
+	}
+
+	stride := 1
+	for i := axis + 1; i < len(m.Dimensions); i++ {
+		stride *= m.Dimensions[i]
+	}
+
+	layout := axisLayout{
+		stride: stride,
+		length: m.Dimensions[axis],
+		block:  m.Dimensions[axis] * stride,
+	}
+	if layout.block > 0 {
+		layout.numBlocks = len(m.Data) / layout.block
+	} else {
+		layout.numBlocks = 1
+		for _, d := range m.Dimensions[:axis] {
+			layout.numBlocks *= d
+		}
+	}
+
+	layout.outShape = append([]int{}, m.Dimensions[:axis]...)
+	layout.outShape = append(layout.outShape, m.Dimensions[axis+1:]...)
+	return layout, nil
+}
+
+// sumStrided adds `n` elements of `data` starting at `base` and `stride` apart.
+func sumStrided(data []float64, base, stride, n int, method SummationMethod) float64 {
+	switch method {
+	case KahanSummation:
+		sum, comp := 0.0, 0.0
+		for j := 0; j < n; j++ {
+			v := data[base+j*stride]
+			t := sum + v
+			if math.Abs(sum) >= math.Abs(v) {
+				comp += (sum - t) + v
+			} else {
+				comp += (v - t) + sum
+			}
+			sum = t
+		}
+		return sum + comp
+	case PairwiseSummation:
+		if n <= pairwiseBase {
+			return sumStrided(data, base, stride, n, NaiveSummation)
+		}
+		half := n / 2
+		return sumStrided(data, base, stride, half, PairwiseSummation) +
+			sumStrided(data, base+half*stride, stride, n-half, PairwiseSummation)
+	default:
+		sum := 0.0
+		for j := 0; j < n; j++ {
+			sum += data[base+j*stride]
+		}
+		return sum
+	}
+}
+
+// sumLanes reduces every lane of `layout` with the settings in `cfg`.
+// Lanes are distributed over workers when there are enough of them; otherwise each
+// lane is split into chunks whose partial sums are combined in index order.
+func sumLanes(data []float64, layout axisLayout, cfg ReduceConfig) []float64 {
+	lanes := layout.lanes()
+	result := make([]float64, lanes)
+	workers := resolveWorkers(cfg.Workers)
+	if len(data) < cfg.ParallelThreshold {
+		workers = 1
+	}
+
+	// Fixed-size chunks make the partial sums independent of the worker count.
+	chunk := 0
+	if cfg.Deterministic {
+		chunk = deterministicChunk
+	} else if lanes < workers {
+		chunk = (layout.length + workers - 1) / workers
+	}
+
+	if lanes >= workers || chunk == 0 || layout.length <= chunk {
+		parallelFor(lanes, workers, func(lo, hi int) {
+			for lane := lo; lane < hi; lane++ {
+				result[lane] = chunkedLaneSum(data, layout.base(lane), layout.stride, layout.length, chunk, cfg.Summation)
+			}
+		})
+		return result
+	}
+
+	// Few long lanes: parallelise across chunks of each lane instead.
+	for lane := 0; lane < lanes; lane++ {
+		base := layout.base(lane)
+		parts := make([]float64, (layout.length+chunk-1)/chunk)
+		parallelFor(len(parts), workers, func(lo, hi int) {
+			for c := lo; c < hi; c++ {
+				n := chunk
+				if rest := layout.length - c*chunk; rest < n {
+					n = rest
+				}
+				parts[c] = sumStrided(data, base+c*chunk*layout.stride, layout.stride, n, cfg.Summation)
+			}
+		})
+		result[lane] = sumStrided(parts, 0, 1, len(parts), cfg.Summation)
+	}
+	return result
+}
+
+// chunkedLaneSum sums one lane, first in `chunk`-sized pieces when chunk is positive.
+// Produces exactly the same value as the chunk-parallel path in sumLanes.
+func chunkedLaneSum(data []float64, base, stride, n, chunk int, method SummationMethod) float64 {
+	if chunk <= 0 || n <= chunk {
+		return sumStrided(data, base, stride, n, method)
+	}
+
+	parts := make([]float64, (n+chunk-1)/chunk)
+	for c := range parts {
+		size := chunk
+		if rest := n - c*chunk; rest < size {
+			size = rest
+		}
+		parts[c] = sumStrided(data, base+c*chunk*stride, stride, size, method)
+	}
+	return sumStrided(parts, 0, 1, len(parts), method)
+}
+
+// extremeLanes computes the minimum (less == true) or maximum of every lane,
+// optionally along with the position of the extreme value within the lane.
+func extremeLanes(data []float64, layout axisLayout, cfg ReduceConfig, less bool) ([]float64, []int) {
+	lanes := layout.lanes()
+	values := make([]float64, lanes)
+	indices := make([]int, lanes)
+	workers := resolveWorkers(cfg.Workers)
+	if len(data) < cfg.ParallelThreshold {
+		workers = 1
+	}
+
+	parallelFor(lanes, workers, func(lo, hi int) {
+		for lane := lo; lane < hi; lane++ {
+			base := layout.base(lane)
+			best, bestIdx := data[base], 0
+			for j := 1; j < layout.length; j++ {
+				v := data[base+j*layout.stride]
+				if (less && v < best) || (!less && v > best) {
+					best, bestIdx = v, j
+				}
+			}
+			values[lane], indices[lane] = best, bestIdx
+		}
+	})
+	return values, indices
+}
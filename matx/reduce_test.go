@@ -0,0 +1,84 @@
+package matx
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestReductions(t *testing.T) {
+	n := 1
+
+	{ // Min/Max along the last axis
+		m := begin(t, n, "Min()/Max() along axis 1")
+		n++
+		mat, _ := New([]float64{5, 1, 9, 0, 7, 3}, []int{2, 3})
+		mins, err1 := Min(mat, 1)
+		maxs, err2 := Max(mat, 1)
+		m.end(err1 == nil && err2 == nil &&
+			reflect.DeepEqual(mins, []float64{1, 0}) &&
+			reflect.DeepEqual(maxs, []float64{9, 7}))
+	}
+
+	{ // Compensated summation
+		m := begin(t, n, "Sum() Kahan vs naive")
+		n++
+		data := make([]float64, 1<<20)
+		for i := range data {
+			data[i] = 0.1
+		}
+		mat, _ := New(data, []int{len(data)})
+		want := 0.1 * float64(len(data))
+
+		cfg := DefaultReduceConfig()
+		cfg.Summation = KahanSummation
+		kahan, err := SumWith(mat, 0, cfg)
+		cfg.Summation = PairwiseSummation
+		pairwise, _ := SumWith(mat, 0, cfg)
+		cfg.Summation = NaiveSummation
+		naive, _ := SumWith(mat, 0, cfg)
+
+		ok := err == nil &&
+			math.Abs(kahan[0]-want) <= math.Abs(naive[0]-want) &&
+			math.Abs(pairwise[0]-want) <= math.Abs(naive[0]-want) &&
+			math.Abs(kahan[0]-want) < 1e-6
+		m.end(ok)
+	}
+
+	{ // Deterministic mode ignores worker count
+		m := begin(t, n, "SumWith() deterministic")
+		n++
+		data := make([]float64, 200000)
+		for i := range data {
+			data[i] = math.Sin(float64(i)) * 1e3
+		}
+		mat, _ := New(data, []int{2, 100000})
+
+		cfg := DefaultReduceConfig()
+		cfg.ParallelThreshold = 0
+		var first []float64
+		ok := true
+		for _, w := range []int{1, 2, 3, 7, 16} {
+			cfg.Workers = w
+			got, err := SumWith(mat, 1, cfg)
+			if err != nil {
+				ok = false
+				break
+			}
+			if first == nil {
+				first = got
+			} else if !reflect.DeepEqual(first, got) {
+				ok = false
+			}
+		}
+		m.end(ok)
+	}
+
+	{ // Mean over a 3D matrix
+		m := begin(t, n, "Mean() 3D along axis 1")
+		n++
+		mat, _ := New([]float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{2, 2, 2})
+		res, err := Mean(mat, 1)
+		m.end(err == nil && reflect.DeepEqual(res, []float64{2, 3, 6, 7}))
+	}
+}
@@ -0,0 +1,326 @@
+package matx
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RenderOptions configures ToLaTeX, ToMarkdown and ToHTML. Zero values select defaults.
+//   - Format is a PrintMatx alias ("int", "float", "f", "short", "sci", "g") or fmt
+//     verb for elements (default "%.4f").
+//   - Precision, when positive, sets the number of decimals as "%.<Precision>f" does,
+//     overriding Format.
+//   - Env is the LaTeX matrix environment: "bmatrix" (default), "pmatrix", "Bmatrix",
+//     "vmatrix", "Vmatrix" or "matrix".
+//   - RowLabels and ColLabels, when set, label every row and column; ToLaTeX then
+//     writes an array with the labels outside a rule instead of a delimited matrix.
+//
+// 1D matrices render as a single row. Matrices of higher rank render one table per
+// 2D slice over the last two axes, each captioned with its index, e.g. "[1, :, :]".
+type RenderOptions struct {
+	Format    string
+	Precision int
+	Env       string
+	RowLabels []string
+	ColLabels []string
+}
+
+// latexEnvs lists the amsmath matrix environments accepted by ToLaTeX.
+var latexEnvs = map[string]bool{"matrix": true, "pmatrix": true, "bmatrix": true, "Bmatrix": true, "vmatrix": true, "Vmatrix": true}
+
+// renderSlice is one 2D table of formatted cells.
+type renderSlice struct {
+	caption string // empty for 1D and 2D matrices
+	cols    int
+	cells   [][]string
+}
+
+// renderSlices formats `m` as 2D slices, validating the labels against their shape.
+func renderSlices(m *Matx, opts RenderOptions, cell func(float64) string) ([]renderSlice, error) {
+	if m == nil || m.Data == nil || m.Dimensions == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	size := 1
+	for _, d := range m.Dimensions {
+		size *= d
+	}
+	if size != len(m.Data) {
+		return nil, fmt.Errorf("data size %d does not match shape %v", len(m.Data), m.Dimensions)
+	}
+
+	dims := m.Dimensions
+	rows, cols := 1, 1
+	switch len(dims) {
+	case 0:
+	case 1:
+		cols = dims[0]
+	default:
+		rows, cols = dims[len(dims)-2], dims[len(dims)-1]
+	}
+	if opts.RowLabels != nil && len(opts.RowLabels) != rows {
+		return nil, fmt.Errorf("%d row labels for %d rows", len(opts.RowLabels), rows)
+	}
+	if opts.ColLabels != nil && len(opts.ColLabels) != cols {
+		return nil, fmt.Errorf("%d column labels for %d columns", len(opts.ColLabels), cols)
+	}
+
+	lead := []int{}
+	if len(dims) > 2 {
+		lead = dims[:len(dims)-2]
+	}
+	count := 1
+	for _, d := range lead {
+		count *= d
+	}
+	slices := make([]renderSlice, count)
+	idx := make([]int, len(lead))
+	for k := range slices {
+		s := renderSlice{cols: cols, cells: make([][]string, rows)}
+		if len(lead) > 0 {
+			parts := make([]string, len(dims))
+			for a := range parts {
+				parts[a] = ":"
+				if a < len(idx) {
+					parts[a] = strconv.Itoa(idx[a])
+				}
+			}
+			s.caption = "[" + strings.Join(parts, ", ") + "]"
+		}
+		offset := k * rows * cols
+		for i := range s.cells {
+			s.cells[i] = make([]string, cols)
+			for j := range s.cells[i] {
+				s.cells[i][j] = cell(m.Data[offset+i*cols+j])
+			}
+		}
+		slices[k] = s
+		for a := len(idx) - 1; a >= 0; a-- {
+			idx[a]++
+			if idx[a] < lead[a] {
+				break
+			}
+			idx[a] = 0
+		}
+	}
+	return slices, nil
+}
+
+// renderVerb returns the element format selected by `opts`.
+func renderVerb(opts RenderOptions) string {
+	if opts.Precision > 0 {
+		return "%." + strconv.Itoa(opts.Precision) + "f"
+	}
+	return resolveFormat("%.4f", opts.Format)
+}
+
+// ToLaTeX renders `m` as an amsmath matrix environment, e.g.
+//
+//	\begin{bmatrix}
+//	1.00 & 2.00 \\
+//	3.00 & 4.00
+//	\end{bmatrix}
+//
+// NaN renders as \mathrm{NaN} and infinities as \infty. Labels are escaped.
+func ToLaTeX(m *Matx, opts RenderOptions) (string, error) {
+	env := opts.Env
+	if env == "" {
+		env = "bmatrix"
+	}
+	if !latexEnvs[env] {
+		return "", fmt.Errorf("unsupported LaTeX environment %q", env)
+	}
+	verb := renderVerb(opts)
+	slices, err := renderSlices(m, opts, func(v float64) string {
+		switch {
+		case math.IsNaN(v):
+			return `\mathrm{NaN}`
+		case math.IsInf(v, 1):
+			return `\infty`
+		case math.IsInf(v, -1):
+			return `-\infty`
+		}
+		return fmt.Sprintf(verb, v)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	labeled := opts.RowLabels != nil || opts.ColLabels != nil
+	var b strings.Builder
+	for k, s := range slices {
+		if k > 0 {
+			b.WriteString("\n")
+		}
+		if s.caption != "" {
+			b.WriteString("% " + s.caption + "\n")
+		}
+		if !labeled {
+			b.WriteString(`\begin{` + env + "}\n")
+			for i, row := range s.cells {
+				b.WriteString(strings.Join(row, " & "))
+				if i < len(s.cells)-1 {
+					b.WriteString(` \\`)
+				}
+				b.WriteString("\n")
+			}
+			b.WriteString(`\end{` + env + "}\n")
+			continue
+		}
+
+		cols := s.cols
+		spec := strings.Repeat("r", cols)
+		if opts.RowLabels != nil {
+			spec = "l|" + spec
+		}
+		b.WriteString(`\begin{array}{` + spec + "}\n")
+		if opts.ColLabels != nil {
+			head := make([]string, 0, cols+1)
+			if opts.RowLabels != nil {
+				head = append(head, "")
+			}
+			for _, l := range opts.ColLabels {
+				head = append(head, latexEscape(l))
+			}
+			b.WriteString(strings.Join(head, " & ") + ` \\` + "\n" + `\hline` + "\n")
+		}
+		for i, row := range s.cells {
+			if opts.RowLabels != nil {
+				row = append([]string{latexEscape(opts.RowLabels[i])}, row...)
+			}
+			b.WriteString(strings.Join(row, " & "))
+			if i < len(s.cells)-1 {
+				b.WriteString(` \\`)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(`\end{array}` + "\n")
+	}
+	return b.String(), nil
+}
+
+// latexEscape escapes the characters that are special in LaTeX text.
+func latexEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`, `$`, `\$`, `&`, `\&`,
+		`#`, `\#`, `_`, `\_`, `%`, `\%`, `^`, `\^{}`, `~`, `\~{}`,
+	).Replace(s)
+}
+
+// ToMarkdown renders `m` as a GitHub-flavored Markdown table with right-aligned,
+// padded columns. Without ColLabels the header holds the column indices.
+func ToMarkdown(m *Matx, opts RenderOptions) (string, error) {
+	verb := renderVerb(opts)
+	slices, err := renderSlices(m, opts, func(v float64) string { return fmt.Sprintf(verb, v) })
+	if err != nil {
+		return "", err
+	}
+	escape := strings.NewReplacer(`|`, `\|`).Replace
+
+	var b strings.Builder
+	for k, s := range slices {
+		if k > 0 {
+			b.WriteString("\n")
+		}
+		if s.caption != "" {
+			b.WriteString("**" + s.caption + "**\n\n")
+		}
+		cols := s.cols
+
+		// Build every line as cells first so that columns can be padded to a common width
+		header := make([]string, 0, cols+1)
+		if opts.RowLabels != nil {
+			header = append(header, "")
+		}
+		for j := 0; j < cols; j++ {
+			if opts.ColLabels != nil {
+				header = append(header, escape(opts.ColLabels[j]))
+			} else {
+				header = append(header, strconv.Itoa(j))
+			}
+		}
+		body := make([][]string, len(s.cells))
+		for i, row := range s.cells {
+			if opts.RowLabels != nil {
+				row = append([]string{escape(opts.RowLabels[i])}, row...)
+			}
+			body[i] = row
+		}
+		widths := make([]int, len(header))
+		for j := range widths {
+			widths[j] = max(3, len(header[j]))
+			for _, row := range body {
+				widths[j] = max(widths[j], len(row[j]))
+			}
+		}
+
+		line := func(cells []string, align func(j int, c string) string) {
+			b.WriteString("|")
+			for j, c := range cells {
+				b.WriteString(" " + align(j, c) + " |")
+			}
+			b.WriteString("\n")
+		}
+		label := func(j int) bool { return j == 0 && opts.RowLabels != nil }
+		pad := func(j int, c string) string {
+			if label(j) {
+				return c + strings.Repeat(" ", widths[j]-len(c))
+			}
+			return strings.Repeat(" ", widths[j]-len(c)) + c
+		}
+		line(header, pad)
+		line(header, func(j int, _ string) string {
+			if label(j) {
+				return ":" + strings.Repeat("-", widths[j]-1)
+			}
+			return strings.Repeat("-", widths[j]-1) + ":"
+		})
+		for _, row := range body {
+			line(row, pad)
+		}
+	}
+	return b.String(), nil
+}
+
+// ToHTML renders `m` as an HTML table, with labels as <th> header cells and slice
+// indices as captions. Labels are escaped.
+func ToHTML(m *Matx, opts RenderOptions) (string, error) {
+	verb := renderVerb(opts)
+	slices, err := renderSlices(m, opts, func(v float64) string { return html.EscapeString(fmt.Sprintf(verb, v)) })
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, s := range slices {
+		b.WriteString("<table>\n")
+		if s.caption != "" {
+			b.WriteString("<caption>" + s.caption + "</caption>\n")
+		}
+		if opts.ColLabels != nil {
+			b.WriteString("<thead>\n<tr>")
+			if opts.RowLabels != nil {
+				b.WriteString("<th></th>")
+			}
+			for _, l := range opts.ColLabels {
+				b.WriteString("<th>" + html.EscapeString(l) + "</th>")
+			}
+			b.WriteString("</tr>\n</thead>\n")
+		}
+		b.WriteString("<tbody>\n")
+		for i, row := range s.cells {
+			b.WriteString("<tr>")
+			if opts.RowLabels != nil {
+				b.WriteString("<th>" + html.EscapeString(opts.RowLabels[i]) + "</th>")
+			}
+			for _, c := range row {
+				b.WriteString("<td>" + c + "</td>")
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</tbody>\n</table>\n")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,68 @@
+package matx
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	n := 1
+
+	a, _ := New([]float64{1, -2.5, 30, 4, math.NaN(), math.Inf(-1)}, []int{2, 3})
+
+	{ // Delimited environments, labeled arrays and non-finite values
+		m := begin(t, n, "ToLaTeX()")
+		n++
+		plain, err := ToLaTeX(a, RenderOptions{Precision: 2})
+		labeled, err2 := ToLaTeX(a, RenderOptions{Format: "int", RowLabels: []string{"x_1", "y"}, ColLabels: []string{"a", "b&c", "d"}})
+		vec, _ := New([]float64{1, 2}, []int{2})
+		paren, err3 := ToLaTeX(vec, RenderOptions{Format: "int", Env: "pmatrix"})
+		m.end(err == nil && err2 == nil && err3 == nil &&
+			plain == "\\begin{bmatrix}\n1.00 & -2.50 & 30.00 \\\\\n4.00 & \\mathrm{NaN} & -\\infty\n\\end{bmatrix}\n" &&
+			labeled == "\\begin{array}{l|rrr}\n & a & b\\&c & d \\\\\n\\hline\nx\\_1 & 1 & -2 & 30 \\\\\ny & 4 & \\mathrm{NaN} & -\\infty\n\\end{array}\n" &&
+			paren == "\\begin{pmatrix}\n1 & 2\n\\end{pmatrix}\n")
+	}
+
+	{ // Padded columns with index or label headers
+		m := begin(t, n, "ToMarkdown()")
+		n++
+		plain, err := ToMarkdown(a, RenderOptions{Format: "short"})
+		labeled, err2 := ToMarkdown(a, RenderOptions{Format: "short", RowLabels: []string{"first", "y|z"}, ColLabels: []string{"a", "b", "c"}})
+		m.end(err == nil && err2 == nil &&
+			plain == "|    0 |     1 |     2 |\n| ---: | ----: | ----: |\n| 1.00 | -2.50 | 30.00 |\n| 4.00 |   NaN |  -Inf |\n" &&
+			labeled == "|       |    a |     b |     c |\n| :---- | ---: | ----: | ----: |\n| first | 1.00 | -2.50 | 30.00 |\n| y\\|z  | 4.00 |   NaN |  -Inf |\n")
+	}
+
+	{ // Header cells for labels, escaped text and slice captions
+		m := begin(t, n, "ToHTML()")
+		n++
+		labeled, err := ToHTML(a, RenderOptions{Format: "g", RowLabels: []string{"<r>", "y"}, ColLabels: []string{"a", "b", "c"}})
+		InitExamples()
+		cube, _ := GiveMatx("matxCube2x2x2")
+		sliced, err2 := ToHTML(cube, RenderOptions{Format: "int"})
+		m.end(err == nil && err2 == nil &&
+			labeled == "<table>\n<thead>\n<tr><th></th><th>a</th><th>b</th><th>c</th></tr>\n</thead>\n<tbody>\n"+
+				"<tr><th>&lt;r&gt;</th><td>1</td><td>-2.5</td><td>30</td></tr>\n"+
+				"<tr><th>y</th><td>4</td><td>NaN</td><td>-Inf</td></tr>\n</tbody>\n</table>\n" &&
+			strings.Count(sliced, "<table>") == 2 && strings.Contains(sliced, "<caption>[1, :, :]</caption>") &&
+			strings.Contains(sliced, "<tr><td>7</td><td>8</td></tr>") && !strings.Contains(sliced, "<thead>"))
+	}
+
+	{ // One captioned table per 2D slice, and invalid options
+		m := begin(t, n, "Per-slice rendering and validation")
+		n++
+		InitExamples()
+		cube, _ := GiveMatx("matxCube2x2x2")
+		md, err := ToMarkdown(cube, RenderOptions{Format: "int"})
+		tex, err2 := ToLaTeX(cube, RenderOptions{Format: "int", Env: "pmatrix"})
+		_, errEnv := ToLaTeX(a, RenderOptions{Env: "tabular"})
+		_, errRows := ToMarkdown(a, RenderOptions{RowLabels: []string{"x"}})
+		_, errCols := ToHTML(a, RenderOptions{ColLabels: []string{"x"}})
+		_, errNil := ToLaTeX(nil, RenderOptions{})
+		m.end(err == nil && err2 == nil && errEnv != nil && errRows != nil && errCols != nil && errNil != nil &&
+			strings.HasPrefix(md, "**[0, :, :]**\n\n|   0 |   1 |\n") && strings.Contains(md, "\n\n**[1, :, :]**\n\n") &&
+			strings.HasSuffix(md, "|   7 |   8 |\n") &&
+			strings.Contains(tex, "% [1, :, :]\n\\begin{pmatrix}\n5 & 6 \\\\\n7 & 8\n\\end{pmatrix}\n"))
+	}
+}
@@ -0,0 +1,447 @@
+package matx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// safetensorsMaxHeader bounds the JSON header, as the reference implementation does.
+const safetensorsMaxHeader = 100 << 20
+
+// safetensorsMetadataKey holds the free-form string map of a safetensors header.
+const safetensorsMetadataKey = "__metadata__"
+
+// safetensorsDType describes how one element type is stored.
+type safetensorsDType struct {
+	size   int
+	decode func([]byte) float64
+	encode func([]byte, float64) error
+}
+
+// safetensorsDTypes lists the element types that can be read; all but BOOL and the
+// narrow integers can also be written.
+var safetensorsDTypes = map[string]safetensorsDType{
+	"F64": {size: 8,
+		decode: func(b []byte) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(b)) },
+		encode: func(b []byte, v float64) error {
+			binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+			return nil
+		}},
+	"F32": {size: 4,
+		decode: func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) },
+		encode: func(b []byte, v float64) error {
+			binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v)))
+			return nil
+		}},
+	"F16": {size: 2,
+		decode: func(b []byte) float64 { return unpackFloat16(binary.LittleEndian.Uint16(b), 5, 10) },
+		encode: func(b []byte, v float64) error {
+			binary.LittleEndian.PutUint16(b, packFloat16(v, 5, 10))
+			return nil
+		}},
+	"BF16": {size: 2,
+		decode: func(b []byte) float64 { return unpackFloat16(binary.LittleEndian.Uint16(b), 8, 7) },
+		encode: func(b []byte, v float64) error {
+			binary.LittleEndian.PutUint16(b, packFloat16(v, 8, 7))
+			return nil
+		}},
+	"I64": {size: 8,
+		decode: func(b []byte) float64 { return float64(int64(binary.LittleEndian.Uint64(b))) },
+		encode: func(b []byte, v float64) error {
+			if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+				return fmt.Errorf("cannot store %v as I64", v)
+			}
+			binary.LittleEndian.PutUint64(b, uint64(int64(v)))
+			return nil
+		}},
+	"I32":  {size: 4, decode: func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) }},
+	"I16":  {size: 2, decode: func(b []byte) float64 { return float64(int16(binary.LittleEndian.Uint16(b))) }},
+	"I8":   {size: 1, decode: func(b []byte) float64 { return float64(int8(b[0])) }},
+	"U8":   {size: 1, decode: func(b []byte) float64 { return float64(b[0]) }},
+	"BOOL": {size: 1, decode: func(b []byte) float64 { return float64(b[0]) }},
+}
+
+// SafetensorsInfo describes one tensor of a safetensors file. Offsets are relative
+// to the start of the data section, end exclusive.
+type SafetensorsInfo struct {
+	DType   string
+	Shape   []int
+	Offsets [2]int64
+}
+
+// SafetensorsFile is a parsed safetensors header over an io.ReaderAt. Tensors are
+// read on demand, so opening a large file costs only its header.
+type SafetensorsFile struct {
+	// Metadata is the "__metadata__" map of the header, or nil.
+	Metadata map[string]string
+
+	r         io.ReaderAt
+	closer    io.Closer
+	dataStart int64
+	names     []string
+	infos     map[string]SafetensorsInfo
+}
+
+// OpenSafetensors parses and validates the header of a safetensors file of `size`
+// bytes. The tensors must exactly tile the data section, without gaps or overlaps.
+func OpenSafetensors(r io.ReaderAt, size int64) (*SafetensorsFile, error) {
+	var prefix [8]byte
+	if _, err := r.ReadAt(prefix[:], 0); err != nil {
+		return nil, fmt.Errorf("safetensors: failed to read header size: %w", err)
+	}
+	headerLen := binary.LittleEndian.Uint64(prefix[:])
+	if headerLen > safetensorsMaxHeader || int64(headerLen) > size-8 {
+		return nil, fmt.Errorf("safetensors: invalid header size %d for a %d byte file", headerLen, size)
+	}
+	raw := make([]byte, headerLen)
+	if _, err := r.ReadAt(raw, 8); err != nil {
+		return nil, fmt.Errorf("safetensors: failed to read header: %w", err)
+	}
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil, fmt.Errorf("safetensors: header is not a JSON object")
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("safetensors: malformed header: %w", err)
+	}
+	f := &SafetensorsFile{r: r, dataStart: 8 + int64(headerLen), infos: make(map[string]SafetensorsInfo, len(entries))}
+	for name, value := range entries {
+		if name == safetensorsMetadataKey {
+			if err := json.Unmarshal(value, &f.Metadata); err != nil {
+				return nil, fmt.Errorf("safetensors: malformed metadata: %w", err)
+			}
+			continue
+		}
+		var entry struct {
+			DType       string  `json:"dtype"`
+			Shape       []int   `json:"shape"`
+			DataOffsets []int64 `json:"data_offsets"`
+		}
+		if err := json.Unmarshal(value, &entry); err != nil {
+			return nil, fmt.Errorf("safetensors: tensor %q: %w", name, err)
+		}
+		info, err := checkSafetensorsEntry(entry.DType, entry.Shape, entry.DataOffsets)
+		if err != nil {
+			return nil, fmt.Errorf("safetensors: tensor %q: %w", name, err)
+		}
+		f.infos[name] = info
+		f.names = append(f.names, name)
+	}
+
+	// Order by position in the data section and check that the tensors tile it
+	sort.Slice(f.names, func(i, j int) bool {
+		a, b := f.infos[f.names[i]].Offsets, f.infos[f.names[j]].Offsets
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		return a[1] < b[1]
+	})
+	end := int64(0)
+	for _, name := range f.names {
+		off := f.infos[name].Offsets
+		if off[0] != end {
+			return nil, fmt.Errorf("safetensors: tensor %q starts at offset %d, want %d", name, off[0], end)
+		}
+		end = off[1]
+	}
+	if dataLen := size - f.dataStart; end != dataLen {
+		return nil, fmt.Errorf("safetensors: tensors cover %d of %d data bytes", end, dataLen)
+	}
+	return f, nil
+}
+
+// checkSafetensorsEntry validates one tensor entry of the header.
+func checkSafetensorsEntry(dtype string, shape []int, offsets []int64) (SafetensorsInfo, error) {
+	dt, ok := safetensorsDTypes[dtype]
+	if !ok {
+		return SafetensorsInfo{}, fmt.Errorf("unsupported dtype %q", dtype)
+	}
+	if shape == nil || len(offsets) != 2 {
+		return SafetensorsInfo{}, fmt.Errorf(`entry needs "dtype", "shape" and two "data_offsets"`)
+	}
+	count := int64(1)
+	for _, d := range shape {
+		if d < 0 || (d > 0 && count > math.MaxInt32/int64(d)) {
+			return SafetensorsInfo{}, fmt.Errorf("invalid shape %v", shape)
+		}
+		count *= int64(d)
+	}
+	if offsets[0] < 0 || offsets[1] < offsets[0] || offsets[1]-offsets[0] != count*int64(dt.size) {
+		return SafetensorsInfo{}, fmt.Errorf("data offsets %v do not match %s shape %v", offsets, dtype, shape)
+	}
+	return SafetensorsInfo{DType: dtype, Shape: shape, Offsets: [2]int64{offsets[0], offsets[1]}}, nil
+}
+
+// Names returns the tensor names in the order of their data.
+func (f *SafetensorsFile) Names() []string {
+	return append([]string{}, f.names...)
+}
+
+// Info returns the header entry of tensor `name`.
+func (f *SafetensorsFile) Info(name string) (SafetensorsInfo, bool) {
+	info, ok := f.infos[name]
+	info.Shape = append([]int{}, info.Shape...)
+	return info, ok
+}
+
+// Tensor reads tensor `name`, converting its elements to float64. Integers beyond
+// 2^53 lose precision, and a 0-d tensor is read as a one-element vector.
+func (f *SafetensorsFile) Tensor(name string) (*Matx, error) {
+	info, ok := f.infos[name]
+	if !ok {
+		return nil, fmt.Errorf("safetensors: no tensor %q", name)
+	}
+	dt := safetensorsDTypes[info.DType]
+	raw := make([]byte, info.Offsets[1]-info.Offsets[0])
+	if _, err := f.r.ReadAt(raw, f.dataStart+info.Offsets[0]); err != nil {
+		return nil, fmt.Errorf("safetensors: failed to read tensor %q: %w", name, err)
+	}
+	data := make([]float64, len(raw)/dt.size)
+	for i := range data {
+		data[i] = dt.decode(raw[i*dt.size:])
+	}
+	dims := append([]int{}, info.Shape...)
+	if len(dims) == 0 {
+		dims = []int{1}
+	}
+	return New(data, dims)
+}
+
+// Close closes the file opened by OpenSafetensorsFile; it does nothing for
+// files returned by OpenSafetensors.
+func (f *SafetensorsFile) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+	return f.closer.Close()
+}
+
+// ReadSafetensors reads every tensor of a safetensors file, keyed by name.
+func ReadSafetensors(r io.ReaderAt, size int64) (map[string]*Matx, error) {
+	f, err := OpenSafetensors(r, size)
+	if err != nil {
+		return nil, err
+	}
+	tensors := make(map[string]*Matx, len(f.names))
+	for _, name := range f.names {
+		if tensors[name], err = f.Tensor(name); err != nil {
+			return nil, err
+		}
+	}
+	return tensors, nil
+}
+
+// OpenSafetensorsFile opens `path` for lazy reading with OpenSafetensors. The caller
+// must Close the result.
+func OpenSafetensorsFile(path string) (*SafetensorsFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open safetensors file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat safetensors file: %w", err)
+	}
+	f, err := OpenSafetensors(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	f.closer = file
+	return f, nil
+}
+
+// ReadSafetensorsFile opens `path` and reads it with ReadSafetensors.
+func ReadSafetensorsFile(path string) (map[string]*Matx, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open safetensors file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat safetensors file: %w", err)
+	}
+	return ReadSafetensors(f, info.Size())
+}
+
+// SafetensorsOptions configures WriteSafetensors.
+//   - DType is the stored element type: "F64" (default, lossless), "F32", "F16",
+//     "BF16" or "I64". Narrow floats round to nearest even and overflow to ±Inf;
+//     I64 requires integral values.
+//   - Metadata is written as the "__metadata__" string map when non-empty.
+type SafetensorsOptions struct {
+	DType    string
+	Metadata map[string]string
+}
+
+// WriteSafetensors writes named tensors as a safetensors file, in name order. The
+// header is padded with spaces to a multiple of 8 bytes so the data stays aligned.
+func WriteSafetensors(w io.Writer, tensors map[string]*Matx, opts SafetensorsOptions) error {
+	dtype := opts.DType
+	if dtype == "" {
+		dtype = "F64"
+	}
+	dt, ok := safetensorsDTypes[dtype]
+	if !ok || dt.encode == nil {
+		return fmt.Errorf("safetensors: unsupported dtype %q for writing (want F64, F32, F16, BF16 or I64)", dtype)
+	}
+
+	names := make([]string, 0, len(tensors))
+	for name := range tensors {
+		if name == safetensorsMetadataKey {
+			return fmt.Errorf("safetensors: %q is reserved for metadata", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var header bytes.Buffer
+	header.WriteByte('{')
+	if len(opts.Metadata) > 0 {
+		meta, err := json.Marshal(opts.Metadata)
+		if err != nil {
+			return fmt.Errorf("safetensors: %w", err)
+		}
+		fmt.Fprintf(&header, "%q:%s", safetensorsMetadataKey, meta)
+	}
+	offset := int64(0)
+	for i, name := range names {
+		m := tensors[name]
+		if m == nil || m.Data == nil || m.Dimensions == nil {
+			return fmt.Errorf("safetensors: tensor %q is nil", name)
+		}
+		count := 1
+		for _, d := range m.Dimensions {
+			count *= d
+		}
+		if count != len(m.Data) {
+			return fmt.Errorf("safetensors: tensor %q: data size %d does not match shape %v", name, len(m.Data), m.Dimensions)
+		}
+		entry, err := json.Marshal(struct {
+			DType       string  `json:"dtype"`
+			Shape       []int   `json:"shape"`
+			DataOffsets []int64 `json:"data_offsets"`
+		}{dtype, m.Dimensions, []int64{offset, offset + int64(count*dt.size)}})
+		if err != nil {
+			return fmt.Errorf("safetensors: %w", err)
+		}
+		key, _ := json.Marshal(name)
+		if i > 0 || len(opts.Metadata) > 0 {
+			header.WriteByte(',')
+		}
+		header.Write(key)
+		header.WriteByte(':')
+		header.Write(entry)
+		offset += int64(count * dt.size)
+	}
+	header.WriteByte('}')
+	for header.Len()%8 != 0 {
+		header.WriteByte(' ')
+	}
+
+	bw := bufio.NewWriter(w)
+	binary.Write(bw, binary.LittleEndian, uint64(header.Len()))
+	bw.Write(header.Bytes())
+	buf := make([]byte, dt.size)
+	for _, name := range names {
+		for _, v := range tensors[name].Data {
+			if err := dt.encode(buf, v); err != nil {
+				return fmt.Errorf("safetensors: tensor %q: %w", name, err)
+			}
+			bw.Write(buf)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("safetensors: write failed: %w", err)
+	}
+	return nil
+}
+
+// WriteSafetensorsFile creates (or truncates) `path` and writes the tensors to it
+// with WriteSafetensors.
+func WriteSafetensorsFile(path string, tensors map[string]*Matx, opts SafetensorsOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create safetensors file: %w", err)
+	}
+	if err := WriteSafetensors(f, tensors, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// unpackFloat16 decodes a 16-bit float with `expBits` exponent and `mantBits`
+// fraction bits: IEEE half precision is 5/10, bfloat16 8/7.
+func unpackFloat16(h uint16, expBits, mantBits uint) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>mantBits) & (1<<expBits - 1)
+	frac := float64(h & (1<<mantBits - 1))
+	bias := 1<<(expBits-1) - 1
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(frac, 1-bias-int(mantBits))
+	case 1<<expBits - 1:
+		if frac == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	}
+	return sign * math.Ldexp(frac+float64(uint(1)<<mantBits), exp-bias-int(mantBits))
+}
+
+// packFloat16 rounds `v` to the nearest 16-bit float with `expBits` exponent and
+// `mantBits` fraction bits, ties to even. Rounding straight from float64 avoids the
+// double rounding of going through float32.
+func packFloat16(v float64, expBits, mantBits uint) uint16 {
+	bits := math.Float64bits(v)
+	sign := uint16(bits>>48) & 0x8000
+	exp := int(bits>>52) & 0x7ff
+	frac := bits & (1<<52 - 1)
+	inf := uint64(1<<expBits-1) << mantBits
+	switch {
+	case exp == 0x7ff && frac != 0:
+		return sign | uint16(inf) | 1<<(mantBits-1)
+	case exp == 0x7ff:
+		return sign | uint16(inf)
+	case exp == 0:
+		return sign // float64 zeros and subnormals are far below either format
+	}
+
+	// The significand with its implicit bit, shifted down to the target precision.
+	// A carry out of the fraction correctly bumps the exponent, up to infinity.
+	sig := frac | 1<<52
+	e := exp - 1023 + (1<<(expBits-1) - 1)
+	shift := 52 - mantBits
+	base := uint64(0)
+	if e > 0 {
+		base = uint64(e-1) << mantBits
+	} else {
+		shift += uint(1 - e) // subnormal
+	}
+	if shift > 53 {
+		return sign
+	}
+	q := sig >> shift
+	rem := sig & (1<<shift - 1)
+	if half := uint64(1) << (shift - 1); rem > half || (rem == half && q&1 == 1) {
+		q++
+	}
+	if base+q >= inf {
+		return sign | uint16(inf)
+	}
+	return sign | uint16(base+q)
+}
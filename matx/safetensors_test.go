@@ -0,0 +1,164 @@
+package matx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// safetensorsBytes assembles a safetensors file from a JSON header and raw data.
+func safetensorsBytes(header string, data []byte) []byte {
+	out := binary.LittleEndian.AppendUint64(nil, uint64(len(header)))
+	return append(append(out, header...), data...)
+}
+
+// countingReaderAt records the number of bytes read through it.
+type countingReaderAt struct {
+	r *bytes.Reader
+	n int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.n += n
+	return n, err
+}
+
+func TestSafetensors(t *testing.T) {
+	n := 1
+
+	{ // Round trip with metadata; the header matches the spec byte for byte
+		m := begin(t, n, "WriteSafetensors()/ReadSafetensors()")
+		n++
+		w, _ := New([]float64{1, -2.5, 1.0 / 3, math.Inf(1), 0, 6}, []int{2, 3})
+		b, _ := New([]float64{0.5}, []int{})
+		var buf bytes.Buffer
+		err := WriteSafetensors(&buf, map[string]*Matx{"w": w, "b": b}, SafetensorsOptions{Metadata: map[string]string{"format": "pt"}})
+		data := buf.Bytes()
+		headerLen := int(binary.LittleEndian.Uint64(data))
+		header := string(data[8 : 8+headerLen])
+		got, err2 := ReadSafetensors(bytes.NewReader(data), int64(len(data)))
+		m.end(err == nil && err2 == nil && headerLen%8 == 0 &&
+			strings.TrimRight(header, " ") == `{"__metadata__":{"format":"pt"},"b":{"dtype":"F64","shape":[],"data_offsets":[0,8]},`+
+				`"w":{"dtype":"F64","shape":[2,3],"data_offsets":[8,56]}}` &&
+			denseEqual(got["w"], w, 0) && CheckDimensionEquality(got["b"].Dimensions, []int{1}) && got["b"].Data[0] == 0.5)
+	}
+
+	{ // Lazy loading reads only the header and the requested tensor
+		m := begin(t, n, "OpenSafetensors() lazy dtypes")
+		n++
+		var raw bytes.Buffer
+		binary.Write(&raw, binary.LittleEndian, []uint16{0x3c00, 0xc000, 0x0001, 0x7c00}) // F16: 1, -2, 2^-24, +Inf
+		binary.Write(&raw, binary.LittleEndian, []uint16{0x3f80, 0x4049})                 // BF16: 1, 3.140625
+		binary.Write(&raw, binary.LittleEndian, []float32{0.25, -8})
+		binary.Write(&raw, binary.LittleEndian, []int64{-3, 1 << 40})
+		header := `{"h":{"dtype":"F16","shape":[2,2],"data_offsets":[0,8]},` +
+			`"bf":{"shape":[2],"dtype":"BF16","data_offsets":[8,12]},` +
+			`"f":{"dtype":"F32","shape":[1,2],"data_offsets":[12,20]},` +
+			`"i":{"dtype":"I64","shape":[2],"data_offsets":[20,36]},"__metadata__":{"k":"v"}}`
+		file := safetensorsBytes(header, raw.Bytes())
+		cr := &countingReaderAt{r: bytes.NewReader(file)}
+		f, err := OpenSafetensors(cr, int64(len(file)))
+		ok := err == nil && cr.n == 8+len(header) && strings.Join(f.Names(), ",") == "h,bf,f,i" && f.Metadata["k"] == "v"
+		before := cr.n
+		i, err1 := f.Tensor("i")
+		ok = ok && err1 == nil && cr.n-before == 16 && closeSlices(i.Data, []float64{-3, 1 << 40}, 0)
+		h, err2 := f.Tensor("h")
+		bf, err3 := f.Tensor("bf")
+		f32, err4 := f.Tensor("f")
+		_, errMissing := f.Tensor("nope")
+		info, found := f.Info("bf")
+		m.end(ok && err2 == nil && err3 == nil && err4 == nil && errMissing != nil && found &&
+			info.DType == "BF16" && info.Offsets == [2]int64{8, 12} &&
+			closeSlices(h.Data[:3], []float64{1, -2, math.Ldexp(1, -24)}, 0) && math.IsInf(h.Data[3], 1) &&
+			closeSlices(bf.Data, []float64{1, 3.140625}, 0) && denseEqual(f32, &Matx{Data: []float64{0.25, -8}, Dimensions: []int{1, 2}}, 0))
+	}
+
+	{ // Half precision and bfloat16 round to nearest even
+		m := begin(t, n, "packFloat16()/unpackFloat16()")
+		n++
+		half := map[float64]uint16{
+			1: 0x3c00, -2: 0xc000, 65504: 0x7bff, 65520: 0x7c00, 1e6: 0x7c00, math.Inf(-1): 0xfc00,
+			1 + math.Ldexp(1, -11): 0x3c00, 1 + 3*math.Ldexp(1, -11): 0x3c02, // ties to even
+			math.Ldexp(1, -24): 0x0001, math.Ldexp(1, -25): 0x0000, math.Ldexp(3, -26): 0x0001, // subnormals
+			math.Ldexp(1023, -24) + math.Ldexp(1, -25): 0x0400, // rounds up into the normals
+		}
+		ok := true
+		for v, want := range half {
+			if got := packFloat16(v, 5, 10); got != want {
+				t.Logf("F16(%v) = %#04x, want %#04x", v, got, want)
+				ok = false
+			}
+		}
+		// 1 + 2^-8 + 2^-30 is just above a bfloat16 tie: float32 would round it to the tie
+		// and then down to 1, the direct rounding goes up
+		ok = ok && packFloat16(1+math.Ldexp(1, -8)+math.Ldexp(1, -30), 8, 7) == 0x3f81 &&
+			packFloat16(1+math.Ldexp(1, -8), 8, 7) == 0x3f80 && packFloat16(3e38, 8, 7) == 0x7f62 &&
+			math.IsNaN(unpackFloat16(packFloat16(math.NaN(), 5, 10), 5, 10)) &&
+			math.Signbit(unpackFloat16(packFloat16(math.Copysign(0, -1), 8, 7), 8, 7))
+		for h := 0; ok && h < 0x7c00; h++ {
+			ok = packFloat16(unpackFloat16(uint16(h), 5, 10), 5, 10) == uint16(h)
+		}
+
+		a, _ := New([]float64{1.0 / 3, -1e-3, 7, 70000}, []int{4})
+		var buf bytes.Buffer
+		err := WriteSafetensors(&buf, map[string]*Matx{"a": a}, SafetensorsOptions{DType: "F16"})
+		got, err2 := ReadSafetensors(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		m.end(ok && err == nil && err2 == nil && math.Abs(got["a"].Data[0]-1.0/3) < 1e-3 &&
+			got["a"].Data[2] == 7 && math.IsInf(got["a"].Data[3], 1))
+	}
+
+	{ // Malformed headers and unsupported values are rejected
+		m := begin(t, n, "Safetensors validation")
+		n++
+		data := make([]byte, 16)
+		bad := map[string][]byte{
+			"gap":      safetensorsBytes(`{"a":{"dtype":"F64","shape":[1],"data_offsets":[8,16]}}`, data),
+			"overlap":  safetensorsBytes(`{"a":{"dtype":"F64","shape":[2],"data_offsets":[0,16]},"b":{"dtype":"F64","shape":[1],"data_offsets":[8,16]}}`, data),
+			"trailing": safetensorsBytes(`{"a":{"dtype":"F64","shape":[1],"data_offsets":[0,8]}}`, data),
+			"size":     safetensorsBytes(`{"a":{"dtype":"F32","shape":[2],"data_offsets":[0,16]}}`, data),
+			"dtype":    safetensorsBytes(`{"a":{"dtype":"C64","shape":[1],"data_offsets":[0,16]}}`, data),
+			"negative": safetensorsBytes(`{"a":{"dtype":"F64","shape":[-2],"data_offsets":[0,16]}}`, data),
+			"array":    safetensorsBytes(`[1,2]`, data),
+			"json":     safetensorsBytes(`{"a":`, data),
+			"length":   append(binary.LittleEndian.AppendUint64(nil, 1<<40), data...),
+			"short":    {1, 2, 3},
+		}
+		ok := true
+		for name, file := range bad {
+			if _, err := OpenSafetensors(bytes.NewReader(file), int64(len(file))); err == nil {
+				t.Logf("%s: accepted", name)
+				ok = false
+			}
+		}
+		half, _ := New([]float64{1.5}, []int{1})
+		errInt := WriteSafetensors(&bytes.Buffer{}, map[string]*Matx{"x": half}, SafetensorsOptions{DType: "I64"})
+		errDType := WriteSafetensors(&bytes.Buffer{}, map[string]*Matx{"x": half}, SafetensorsOptions{DType: "U8"})
+		errReserved := WriteSafetensors(&bytes.Buffer{}, map[string]*Matx{"__metadata__": half}, SafetensorsOptions{})
+		errNil := WriteSafetensors(&bytes.Buffer{}, map[string]*Matx{"x": nil}, SafetensorsOptions{})
+		var empty bytes.Buffer
+		errEmpty := WriteSafetensors(&empty, map[string]*Matx{}, SafetensorsOptions{})
+		none, errNone := ReadSafetensors(bytes.NewReader(empty.Bytes()), int64(empty.Len()))
+		m.end(ok && errInt != nil && errDType != nil && errReserved != nil && errNil != nil &&
+			errEmpty == nil && errNone == nil && len(none) == 0)
+	}
+
+	{ // File helpers
+		m := begin(t, n, "Safetensors files")
+		n++
+		path := filepath.Join(t.TempDir(), "model.safetensors")
+		a := randomSparse(16, 8, 0.5)
+		bias, _ := New([]float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{8})
+		err1 := WriteSafetensorsFile(path, map[string]*Matx{"layer.weight": a, "layer.bias": bias}, SafetensorsOptions{DType: "F32"})
+		f, err2 := OpenSafetensorsFile(path)
+		w, err3 := f.Tensor("layer.weight")
+		err4 := f.Close()
+		all, err5 := ReadSafetensorsFile(path)
+		_, errMissing := OpenSafetensorsFile(filepath.Join(t.TempDir(), "missing"))
+		m.end(err1 == nil && err2 == nil && err3 == nil && err4 == nil && err5 == nil && errMissing != nil &&
+			closeSlices(w.Data, a.Data, 1e-7) && denseEqual(all["layer.bias"], bias, 0) && len(all) == 2)
+	}
+}
@@ -0,0 +1,427 @@
+package matx
+
+import (
+	"fmt"
+	"sort"
+)
+
+// COO stores a sparse 2D matrix as coordinate triplets (RowIdx[k], ColIdx[k], Values[k]).
+// Entries may be unordered and may repeat; repeated coordinates add up on conversion.
+// It is the easiest format to build incrementally.
+type COO struct {
+	Rows, Cols int
+	RowIdx     []int
+	ColIdx     []int
+	Values     []float64
+}
+
+// CSR stores a sparse 2D matrix in compressed sparse row form.
+// - Row i owns the entries RowPtr[i] to RowPtr[i+1]-1 of ColIdx and Values.
+// - Column indices are sorted and unique within each row.
+type CSR struct {
+	Rows, Cols int
+	RowPtr     []int
+	ColIdx     []int
+	Values     []float64
+}
+
+// CSC stores a sparse 2D matrix in compressed sparse column form.
+// - Column j owns the entries ColPtr[j] to ColPtr[j+1]-1 of RowIdx and Values.
+// - Row indices are sorted and unique within each column.
+type CSC struct {
+	Rows, Cols int
+	ColPtr     []int
+	RowIdx     []int
+	Values     []float64
+}
+
+// NewCOO builds a rows×cols COO matrix from triplets.
+// The slices are used as-is, not copied. Returns an error if their lengths differ
+// or any coordinate is out of bounds.
+func NewCOO(rows, cols int, rowIdx, colIdx []int, values []float64) (*COO, error) {
+	if rows < 0 || cols < 0 {
+		return nil, fmt.Errorf("invalid sparse matrix size: %dx%d", rows, cols)
+	}
+	if len(rowIdx) != len(values) || len(colIdx) != len(values) {
+		return nil, fmt.Errorf("triplet length mismatch: %d rows, %d cols, %d values",
+			len(rowIdx), len(colIdx), len(values))
+	}
+	for k := range values {
+		if rowIdx[k] < 0 || rowIdx[k] >= rows || colIdx[k] < 0 || colIdx[k] >= cols {
+			return nil, fmt.Errorf("entry %d at (%d, %d) out of bounds for %dx%d matrix",
+				k, rowIdx[k], colIdx[k], rows, cols)
+		}
+	}
+
+	return &COO{Rows: rows, Cols: cols, RowIdx: rowIdx, ColIdx: colIdx, Values: values}, nil
+}
+
+// NewCSR builds a CSR matrix from its raw arrays after checking that they are consistent:
+// len(rowPtr) == rows+1, non-decreasing pointers, and sorted, unique, in-range column indices.
+func NewCSR(rows, cols int, rowPtr, colIdx []int, values []float64) (*CSR, error) {
+	if err := checkCompressed(rows, cols, rowPtr, colIdx, values, "row"); err != nil {
+		return nil, err
+	}
+	return &CSR{Rows: rows, Cols: cols, RowPtr: rowPtr, ColIdx: colIdx, Values: values}, nil
+}
+
+// NewCSC builds a CSC matrix from its raw arrays, with the same checks as NewCSR applied per column.
+func NewCSC(rows, cols int, colPtr, rowIdx []int, values []float64) (*CSC, error) {
+	if err := checkCompressed(cols, rows, colPtr, rowIdx, values, "column"); err != nil {
+		return nil, err
+	}
+	return &CSC{Rows: rows, Cols: cols, ColPtr: colPtr, RowIdx: rowIdx, Values: values}, nil
+}
+
+// checkCompressed validates compressed storage with `major` outer slots of `minor` length each.
+func checkCompressed(major, minor int, ptr, idx []int, values []float64, kind string) error {
+	if major < 0 || minor < 0 {
+		return fmt.Errorf("invalid sparse matrix size")
+	}
+	if len(ptr) != major+1 {
+		return fmt.Errorf("%s pointer length %d, expected %d", kind, len(ptr), major+1)
+	}
+	if ptr[0] != 0 || ptr[major] != len(idx) || len(idx) != len(values) {
+		return fmt.Errorf("%s pointers do not span %d index/value entries", kind, len(values))
+	}
+	// Check every pointer before indexing, so a pointer that overshoots and comes back is caught
+	for s := 0; s < major; s++ {
+		if ptr[s] < 0 || ptr[s] > len(idx) {
+			return fmt.Errorf("%s pointer %d out of range at %s %d", kind, ptr[s], kind, s)
+		}
+		if ptr[s] > ptr[s+1] {
+			return fmt.Errorf("%s pointers decrease at %s %d", kind, kind, s)
+		}
+	}
+	for s := 0; s < major; s++ {
+		for k := ptr[s]; k < ptr[s+1]; k++ {
+			if idx[k] < 0 || idx[k] >= minor {
+				return fmt.Errorf("index %d out of bounds in %s %d", idx[k], kind, s)
+			}
+			if k > ptr[s] && idx[k] <= idx[k-1] {
+				return fmt.Errorf("indices not strictly increasing in %s %d", kind, s)
+			}
+		}
+	}
+	return nil
+}
+
+// COOFromMatx collects the nonzero entries of a 2D dense matrix.
+func COOFromMatx(m *Matx) (*COO, error) {
+	if m == nil || len(m.Dimensions) != 2 {
+		return nil, fmt.Errorf("sparse conversion only supports 2D matrices")
+	}
+
+	rows, cols := m.Dimensions[0], m.Dimensions[1]
+	c := &COO{Rows: rows, Cols: cols}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if v := m.Data[i*cols+j]; v != 0 {
+				c.RowIdx = append(c.RowIdx, i)
+				c.ColIdx = append(c.ColIdx, j)
+				c.Values = append(c.Values, v)
+			}
+		}
+	}
+	return c, nil
+}
+
+// CSRFromMatx compresses the nonzero entries of a 2D dense matrix by row.
+func CSRFromMatx(m *Matx) (*CSR, error) {
+	c, err := COOFromMatx(m)
+	if err != nil {
+		return nil, err
+	}
+	return c.ToCSR(), nil
+}
+
+// CSCFromMatx compresses the nonzero entries of a 2D dense matrix by column.
+func CSCFromMatx(m *Matx) (*CSC, error) {
+	c, err := COOFromMatx(m)
+	if err != nil {
+		return nil, err
+	}
+	return c.ToCSC(), nil
+}
+
+// Dims returns the number of rows and columns.
+func (c *COO) Dims() (int, int) { return c.Rows, c.Cols }
+
+// NNZ returns the number of stored entries, counting duplicates separately.
+func (c *COO) NNZ() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.Values)
+}
+
+// Append adds `v` at (i, j). Returns an error if the coordinate is out of bounds.
+func (c *COO) Append(i, j int, v float64) error {
+	if i < 0 || i >= c.Rows || j < 0 || j >= c.Cols {
+		return fmt.Errorf("coordinate (%d, %d) out of bounds for %dx%d matrix", i, j, c.Rows, c.Cols)
+	}
+	c.RowIdx = append(c.RowIdx, i)
+	c.ColIdx = append(c.ColIdx, j)
+	c.Values = append(c.Values, v)
+	return nil
+}
+
+// At returns the value at (i, j), summing duplicate entries. O(nnz).
+func (c *COO) At(i, j int) (float64, error) {
+	if i < 0 || i >= c.Rows || j < 0 || j >= c.Cols {
+		return 0, fmt.Errorf("coordinate (%d, %d) out of bounds for %dx%d matrix", i, j, c.Rows, c.Cols)
+	}
+	sum := 0.0
+	for k := range c.Values {
+		if c.RowIdx[k] == i && c.ColIdx[k] == j {
+			sum += c.Values[k]
+		}
+	}
+	return sum, nil
+}
+
+// Transpose returns a new COO with rows and columns swapped.
+func (c *COO) Transpose() *COO {
+	return &COO{
+		Rows:   c.Cols,
+		Cols:   c.Rows,
+		RowIdx: append([]int(nil), c.ColIdx...),
+		ColIdx: append([]int(nil), c.RowIdx...),
+		Values: append([]float64(nil), c.Values...),
+	}
+}
+
+// ToCSR compresses the triplets by row, sorting columns and summing duplicates.
+func (c *COO) ToCSR() *CSR {
+	ptr, idx, vals := compress(c.Rows, c.RowIdx, c.ColIdx, c.Values)
+	return &CSR{Rows: c.Rows, Cols: c.Cols, RowPtr: ptr, ColIdx: idx, Values: vals}
+}
+
+// ToCSC compresses the triplets by column, sorting rows and summing duplicates.
+func (c *COO) ToCSC() *CSC {
+	ptr, idx, vals := compress(c.Cols, c.ColIdx, c.RowIdx, c.Values)
+	return &CSC{Rows: c.Rows, Cols: c.Cols, ColPtr: ptr, RowIdx: idx, Values: vals}
+}
+
+// ToMatx expands the triplets into a dense matrix.
+func (c *COO) ToMatx() (*Matx, error) {
+	m, err := Zeros([]int{c.Rows, c.Cols})
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.Values {
+		m.Data[c.RowIdx[k]*c.Cols+c.ColIdx[k]] += v
+	}
+	return m, nil
+}
+
+// Dims returns the number of rows and columns.
+func (s *CSR) Dims() (int, int) { return s.Rows, s.Cols }
+
+// NNZ returns the number of stored entries.
+func (s *CSR) NNZ() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.Values)
+}
+
+// At returns the value at (i, j) using a binary search within row i.
+func (s *CSR) At(i, j int) (float64, error) {
+	if i < 0 || i >= s.Rows || j < 0 || j >= s.Cols {
+		return 0, fmt.Errorf("coordinate (%d, %d) out of bounds for %dx%d matrix", i, j, s.Rows, s.Cols)
+	}
+	return lookupCompressed(s.RowPtr, s.ColIdx, s.Values, i, j), nil
+}
+
+// Transpose returns the transpose as a new CSR matrix. O(nnz + rows + cols).
+func (s *CSR) Transpose() *CSR {
+	ptr, idx, vals := transposeCompressed(s.Rows, s.Cols, s.RowPtr, s.ColIdx, s.Values)
+	return &CSR{Rows: s.Cols, Cols: s.Rows, RowPtr: ptr, ColIdx: idx, Values: vals}
+}
+
+// ToCOO expands the row pointers into explicit triplets, in row-major order.
+func (s *CSR) ToCOO() *COO {
+	return &COO{
+		Rows:   s.Rows,
+		Cols:   s.Cols,
+		RowIdx: expandPointers(s.RowPtr),
+		ColIdx: append([]int(nil), s.ColIdx...),
+		Values: append([]float64(nil), s.Values...),
+	}
+}
+
+// ToCSC converts to compressed sparse column form.
+func (s *CSR) ToCSC() *CSC {
+	ptr, idx, vals := transposeCompressed(s.Rows, s.Cols, s.RowPtr, s.ColIdx, s.Values)
+	return &CSC{Rows: s.Rows, Cols: s.Cols, ColPtr: ptr, RowIdx: idx, Values: vals}
+}
+
+// ToMatx expands the matrix into dense storage.
+func (s *CSR) ToMatx() (*Matx, error) {
+	m, err := Zeros([]int{s.Rows, s.Cols})
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < s.Rows; i++ {
+		for k := s.RowPtr[i]; k < s.RowPtr[i+1]; k++ {
+			m.Data[i*s.Cols+s.ColIdx[k]] = s.Values[k]
+		}
+	}
+	return m, nil
+}
+
+// Dims returns the number of rows and columns.
+func (s *CSC) Dims() (int, int) { return s.Rows, s.Cols }
+
+// NNZ returns the number of stored entries.
+func (s *CSC) NNZ() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.Values)
+}
+
+// At returns the value at (i, j) using a binary search within column j.
+func (s *CSC) At(i, j int) (float64, error) {
+	if i < 0 || i >= s.Rows || j < 0 || j >= s.Cols {
+		return 0, fmt.Errorf("coordinate (%d, %d) out of bounds for %dx%d matrix", i, j, s.Rows, s.Cols)
+	}
+	return lookupCompressed(s.ColPtr, s.RowIdx, s.Values, j, i), nil
+}
+
+// Transpose returns the transpose as a new CSC matrix. O(nnz + rows + cols).
+func (s *CSC) Transpose() *CSC {
+	ptr, idx, vals := transposeCompressed(s.Cols, s.Rows, s.ColPtr, s.RowIdx, s.Values)
+	return &CSC{Rows: s.Cols, Cols: s.Rows, ColPtr: ptr, RowIdx: idx, Values: vals}
+}
+
+// ToCOO expands the column pointers into explicit triplets, in column-major order.
+func (s *CSC) ToCOO() *COO {
+	return &COO{
+		Rows:   s.Rows,
+		Cols:   s.Cols,
+		RowIdx: append([]int(nil), s.RowIdx...),
+		ColIdx: expandPointers(s.ColPtr),
+		Values: append([]float64(nil), s.Values...),
+	}
+}
+
+// ToCSR converts to compressed sparse row form.
+func (s *CSC) ToCSR() *CSR {
+	ptr, idx, vals := transposeCompressed(s.Cols, s.Rows, s.ColPtr, s.RowIdx, s.Values)
+	return &CSR{Rows: s.Rows, Cols: s.Cols, RowPtr: ptr, ColIdx: idx, Values: vals}
+}
+
+// ToMatx expands the matrix into dense storage.
+func (s *CSC) ToMatx() (*Matx, error) {
+	m, err := Zeros([]int{s.Rows, s.Cols})
+	if err != nil {
+		return nil, err
+	}
+	for j := 0; j < s.Cols; j++ {
+		for k := s.ColPtr[j]; k < s.ColPtr[j+1]; k++ {
+			m.Data[s.RowIdx[k]*s.Cols+j] = s.Values[k]
+		}
+	}
+	return m, nil
+}
+
+// compress groups triplets by their `major` index into pointer/index/value arrays,
+// sorting the `minor` indices of every slot and merging duplicates.
+func compress(n int, major, minor []int, values []float64) ([]int, []int, []float64) {
+	ptr := make([]int, n+1)
+	for _, r := range major {
+		ptr[r+1]++
+	}
+	for s := 0; s < n; s++ {
+		ptr[s+1] += ptr[s]
+	}
+
+	idx := make([]int, len(values))
+	vals := make([]float64, len(values))
+	next := append([]int(nil), ptr[:n]...)
+	for k, r := range major {
+		idx[next[r]] = minor[k]
+		vals[next[r]] = values[k]
+		next[r]++
+	}
+
+	// Sort each slot by minor index and fold duplicates, compacting in place
+	out := 0
+	for s := 0; s < n; s++ {
+		lo, hi := ptr[s], ptr[s+1]
+		sort.Sort(slotSorter{idx[lo:hi], vals[lo:hi]})
+		ptr[s] = out
+		for k := lo; k < hi; k++ {
+			if k > lo && idx[k] == idx[out-1] {
+				vals[out-1] += vals[k]
+				continue
+			}
+			idx[out], vals[out] = idx[k], vals[k]
+			out++
+		}
+	}
+	ptr[n] = out
+
+	return ptr, idx[:out:out], vals[:out:out]
+}
+
+// transposeCompressed swaps the roles of the major and minor dimension of compressed
+// storage (CSR <-> CSC) with a counting pass. Output indices come out sorted.
+func transposeCompressed(major, minor int, ptr, idx []int, values []float64) ([]int, []int, []float64) {
+	outPtr := make([]int, minor+1)
+	for _, j := range idx {
+		outPtr[j+1]++
+	}
+	for j := 0; j < minor; j++ {
+		outPtr[j+1] += outPtr[j]
+	}
+
+	outIdx := make([]int, len(idx))
+	outVals := make([]float64, len(values))
+	next := append([]int(nil), outPtr[:minor]...)
+	for s := 0; s < major; s++ {
+		for k := ptr[s]; k < ptr[s+1]; k++ {
+			j := idx[k]
+			outIdx[next[j]] = s
+			outVals[next[j]] = values[k]
+			next[j]++
+		}
+	}
+	return outPtr, outIdx, outVals
+}
+
+// lookupCompressed finds the value at (slot, target) in compressed storage, or 0 if absent.
+func lookupCompressed(ptr, idx []int, values []float64, slot, target int) float64 {
+	lo, hi := ptr[slot], ptr[slot+1]
+	k := lo + sort.SearchInts(idx[lo:hi], target)
+	if k < hi && idx[k] == target {
+		return values[k]
+	}
+	return 0
+}
+
+// expandPointers turns a pointer array into one explicit slot index per entry.
+func expandPointers(ptr []int) []int {
+	out := make([]int, ptr[len(ptr)-1])
+	for s := 0; s+1 < len(ptr); s++ {
+		for k := ptr[s]; k < ptr[s+1]; k++ {
+			out[k] = s
+		}
+	}
+	return out
+}
+
+// slotSorter orders the entries of one compressed slot by index, keeping values aligned.
+type slotSorter struct {
+	idx  []int
+	vals []float64
+}
+
+func (s slotSorter) Len() int           { return len(s.idx) }
+func (s slotSorter) Less(i, j int) bool { return s.idx[i] < s.idx[j] }
+func (s slotSorter) Swap(i, j int) {
+	s.idx[i], s.idx[j] = s.idx[j], s.idx[i]
+	s.vals[i], s.vals[j] = s.vals[j], s.vals[i]
+}
@@ -0,0 +1,221 @@
+package matx
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// isPermutation reports whether p holds each of 0..n-1 exactly once.
+func isPermutation(p []int, n int) bool {
+	if len(p) != n {
+		return false
+	}
+	s := append([]int(nil), p...)
+	sort.Ints(s)
+	for i, v := range s {
+		if v != i {
+			return false
+		}
+	}
+	return true
+}
+
+// bandwidth returns max |i - j| over the entries of P·A·Pᵀ.
+func bandwidth(a *CSR, perm []int) int {
+	pinv := invertPermutation(perm)
+	bw := 0
+	for i := 0; i < a.Rows; i++ {
+		for k := a.RowPtr[i]; k < a.RowPtr[i+1]; k++ {
+			if d := pinv[i] - pinv[a.ColIdx[k]]; d > bw {
+				bw = d
+			} else if -d > bw {
+				bw = -d
+			}
+		}
+	}
+	return bw
+}
+
+// shuffled returns P·A·Pᵀ for a fixed pseudo-random permutation, hiding the natural order.
+func shuffled(a *CSR) *CSR {
+	n := a.Rows
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = (i*7919 + 13) % n
+	}
+	coo := a.ToCOO()
+	for k := range coo.RowIdx {
+		coo.RowIdx[k], coo.ColIdx[k] = perm[coo.RowIdx[k]], perm[coo.ColIdx[k]]
+	}
+	return coo.ToCSR()
+}
+
+func TestSparseDirect(t *testing.T) {
+	n := 1
+
+	spd := shuffled(laplacian2D(15, 0))
+	nonsym := shuffled(laplacian2D(15, 0.6))
+	b := make([]float64, spd.Rows)
+	for i := range b {
+		b[i] = math.Sin(float64(i) + 1)
+	}
+
+	{ // Orderings are permutations; RCM shrinks the bandwidth
+		m := begin(t, n, "RCMOrder()/AMDOrder()")
+		n++
+		rcm, err1 := RCMOrder(spd)
+		amd, err2 := AMDOrder(spd)
+		ok := err1 == nil && err2 == nil && isPermutation(rcm, spd.Rows) && isPermutation(amd, spd.Rows)
+		natural, _ := computeOrdering(spd, NaturalOrdering)
+		m.end(ok && bandwidth(spd, rcm) <= 16 && bandwidth(spd, rcm) < bandwidth(spd, natural))
+	}
+
+	{ // AMD and RCM give less fill than the shuffled natural order
+		m := begin(t, n, "AnalyzeCholesky() fill")
+		n++
+		nat, err1 := AnalyzeCholesky(spd, NaturalOrdering)
+		amd, err2 := AnalyzeCholesky(spd, AMDOrdering)
+		rcm, err3 := AnalyzeCholesky(spd, RCMOrdering)
+		ok := err1 == nil && err2 == nil && err3 == nil
+		m.end(ok && amd.NNZ() < nat.NNZ() && rcm.NNZ() < nat.NNZ() && amd.NNZ() <= rcm.NNZ())
+	}
+
+	{ // Cholesky solves under every ordering and L·Lᵀ reproduces P·A·Pᵀ
+		m := begin(t, n, "SparseCholeskyFactor().Solve()")
+		n++
+		ok := true
+		for _, o := range []Ordering{NaturalOrdering, AMDOrdering, RCMOrdering} {
+			c, err := SparseCholeskyFactor(spd, o)
+			if err != nil {
+				ok = false
+				break
+			}
+			x, err := c.Solve(b)
+			ok = ok && err == nil && relResidual(spd, b, x) < 1e-12
+		}
+		l, perm, err := SparseCholeskyDecompose(spd, AMDOrdering)
+		if ok && err == nil {
+			lt := l.Transpose()
+			llt, _ := MultiplyCSR(l, lt)
+			for i := 0; i < spd.Rows && ok; i++ {
+				for j := 0; j < spd.Rows; j++ {
+					want, _ := spd.At(perm[i], perm[j])
+					got, _ := llt.At(i, j)
+					if math.Abs(want-got) > 1e-12 {
+						ok = false
+						break
+					}
+				}
+			}
+		}
+		m.end(ok && err == nil)
+	}
+
+	{ // Symbolic analysis is reused for new values with the same pattern
+		m := begin(t, n, "CholeskySymbolic.Factor() reuse")
+		n++
+		s, err := AnalyzeCholesky(spd, AMDOrdering)
+		ok := err == nil
+		shifted := &CSR{Rows: spd.Rows, Cols: spd.Cols, RowPtr: spd.RowPtr, ColIdx: spd.ColIdx, Values: make([]float64, spd.NNZ())}
+		for i := 0; i < spd.Rows && ok; i++ {
+			for k := spd.RowPtr[i]; k < spd.RowPtr[i+1]; k++ {
+				shifted.Values[k] = 2 * spd.Values[k]
+				if spd.ColIdx[k] == i {
+					shifted.Values[k] += 1
+				}
+			}
+		}
+		for _, a := range []*CSR{spd, shifted} {
+			if !ok {
+				break
+			}
+			c, err := s.Factor(a)
+			if err != nil {
+				ok = false
+				break
+			}
+			x, _ := c.Solve(b)
+			ok = relResidual(a, b, x) < 1e-12
+		}
+		_, errPattern := s.Factor(laplacian2D(15, 0))
+		_, errIndef := s.Factor(&CSR{Rows: spd.Rows, Cols: spd.Cols, RowPtr: spd.RowPtr, ColIdx: spd.ColIdx, Values: negate(spd.Values)})
+		m.end(ok && errPattern != nil && errIndef != nil)
+	}
+
+	{ // LU on a nonsymmetric system, with pivoting forced by a zero diagonal
+		m := begin(t, n, "SparseLUFactor().Solve()")
+		n++
+		ok := true
+		for _, o := range []Ordering{NaturalOrdering, AMDOrdering, RCMOrdering} {
+			lu, err := SparseLUFactor(nonsym, o)
+			if err != nil {
+				ok = false
+				break
+			}
+			x, err := lu.Solve(b)
+			ok = ok && err == nil && relResidual(nonsym, b, x) < 1e-12
+		}
+
+		dense, _ := New([]float64{0, 2, 1, 1, 1, 0, 3, 0, 4}, []int{3, 3})
+		a, _ := CSRFromMatx(dense)
+		lu, err := SparseLUFactor(a, AMDOrdering)
+		want, _ := Det(dense)
+		if err == nil {
+			x, _ := lu.Solve([]float64{1, 2, 3})
+			ok = ok && relResidual(a, []float64{1, 2, 3}, x) < 1e-14 && math.Abs(lu.Det()-want) < 1e-12
+		}
+		m.end(ok && err == nil)
+	}
+
+	{ // L·U reproduces the permuted matrix, mirroring LUDecomposeWithPivoting
+		m := begin(t, n, "SparseLUDecomposeWithPivoting()")
+		n++
+		dense := randomSparse(30, 30, 0.15)
+		for i := 0; i < 30; i++ {
+			dense.Data[i*30+i] += 5
+		}
+		a, _ := CSRFromMatx(dense)
+		l, u, pivots, colPerm, err := SparseLUDecomposeWithPivoting(a, AMDOrdering)
+		ok := err == nil && isPermutation(pivots, 30) && isPermutation(colPerm, 30)
+		if ok {
+			prod, _ := MultiplyCSR(l, u)
+			for i := 0; i < 30 && ok; i++ {
+				for j := 0; j < 30; j++ {
+					got, _ := prod.At(i, j)
+					if math.Abs(got-dense.Data[pivots[i]*30+colPerm[j]]) > 1e-12 {
+						ok = false
+						break
+					}
+				}
+			}
+			lu, _ := SparseLUFactor(a, AMDOrdering)
+			want, _ := Det(dense)
+			ok = ok && math.Abs(lu.Det()-want) <= 1e-9*math.Abs(want)
+		}
+		m.end(ok)
+	}
+
+	{ // Singular and mismatched inputs are rejected
+		m := begin(t, n, "SparseLUFactor() errors")
+		n++
+		singular, _ := NewCSR(2, 2, []int{0, 2, 4}, []int{0, 1, 0, 1}, []float64{1, 2, 2, 4})
+		_, err1 := SparseLUFactor(singular, AMDOrdering)
+		rect, _ := NewCSR(2, 3, []int{0, 1, 2}, []int{0, 1}, []float64{1, 1})
+		_, err2 := AnalyzeLU(rect, NaturalOrdering)
+		s, _ := AnalyzeLU(nonsym, AMDOrdering)
+		_, err3 := s.Factor(laplacian2D(15, 0.6))
+		lu, _ := s.Factor(nonsym)
+		_, err4 := lu.Solve([]float64{1})
+		m.end(err1 != nil && err2 != nil && err3 != nil && err4 != nil)
+	}
+}
+
+// negate returns -v as a new slice.
+func negate(v []float64) []float64 {
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = -x
+	}
+	return out
+}
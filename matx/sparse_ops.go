@@ -0,0 +1,522 @@
+package matx
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// sparseWorkers picks the worker count for a sparse kernel touching `nnz` entries,
+// following the package ReduceConfig: single-threaded below ParallelThreshold.
+func sparseWorkers(nnz int) int {
+	cfg := CurrentReduceConfig()
+	if nnz < cfg.ParallelThreshold {
+		return 1
+	}
+	return cfg.Workers
+}
+
+// sparseShape returns the [rows, cols] shape used in trace events for a CSR matrix.
+func sparseShape(s *CSR) []int {
+	if s == nil {
+		return nil
+	}
+	return []int{s.Rows, s.Cols}
+}
+
+// cscShape is sparseShape for a CSC matrix.
+func cscShape(s *CSC) []int {
+	if s == nil {
+		return nil
+	}
+	return []int{s.Rows, s.Cols}
+}
+
+// MulVec returns the sparse matrix-vector product A·x, computed in parallel over rows.
+// Returns an error if len(x) does not match the number of columns.
+func (s *CSR) MulVec(x []float64) (y []float64, err error) {
+	if span := startTraceShapes("SpMV", sparseShape(s), []int{len(x)}); span != nil {
+		defer func() { span.end(2*int64(s.NNZ()), 8*int64(len(y)), err) }()
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	if len(x) != s.Cols {
+		return nil, fmt.Errorf("vector length %d does not match %d columns", len(x), s.Cols)
+	}
+
+	y = make([]float64, s.Rows)
+	s.mulVecInto(y, x)
+	return y, nil
+}
+
+// mulVecInto writes A·x into y without validation or allocation.
+func (s *CSR) mulVecInto(y, x []float64) {
+	parallelFor(s.Rows, sparseWorkers(s.NNZ()), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			sum := 0.0
+			for k := s.RowPtr[i]; k < s.RowPtr[i+1]; k++ {
+				sum += s.Values[k] * x[s.ColIdx[k]]
+			}
+			y[i] = sum
+		}
+	})
+}
+
+// MulVecTrans returns the product Aᵀ·x without forming the transpose.
+// Returns an error if len(x) does not match the number of rows.
+func (s *CSR) MulVecTrans(x []float64) (y []float64, err error) {
+	if span := startTraceShapes("SpMVTrans", sparseShape(s), []int{len(x)}); span != nil {
+		defer func() { span.end(2*int64(s.NNZ()), 8*int64(len(y)), err) }()
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	if len(x) != s.Rows {
+		return nil, fmt.Errorf("vector length %d does not match %d rows", len(x), s.Rows)
+	}
+
+	y = make([]float64, s.Cols)
+	s.mulVecTransInto(y, x)
+	return y, nil
+}
+
+// mulVecTransInto writes Aᵀ·x into y by scattering each row; y is overwritten.
+func (s *CSR) mulVecTransInto(y, x []float64) {
+	for j := range y {
+		y[j] = 0
+	}
+	for i := 0; i < s.Rows; i++ {
+		xi := x[i]
+		for k := s.RowPtr[i]; k < s.RowPtr[i+1]; k++ {
+			y[s.ColIdx[k]] += s.Values[k] * xi
+		}
+	}
+}
+
+// MulVec returns the sparse matrix-vector product A·x by scattering each column.
+// Returns an error if len(x) does not match the number of columns.
+func (s *CSC) MulVec(x []float64) (y []float64, err error) {
+	if span := startTraceShapes("SpMVCSC", cscShape(s), []int{len(x)}); span != nil {
+		defer func() { span.end(2*int64(s.NNZ()), 8*int64(len(y)), err) }()
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+	if len(x) != s.Cols {
+		return nil, fmt.Errorf("vector length %d does not match %d columns", len(x), s.Cols)
+	}
+
+	y = make([]float64, s.Rows)
+	for j := 0; j < s.Cols; j++ {
+		xj := x[j]
+		for k := s.ColPtr[j]; k < s.ColPtr[j+1]; k++ {
+			y[s.RowIdx[k]] += s.Values[k] * xj
+		}
+	}
+	return y, nil
+}
+
+// MultiplyCSRDense returns the dense product of a sparse matrix `a` and a 2D dense matrix `b`,
+// computed in parallel over the rows of `a`.
+func MultiplyCSRDense(a *CSR, b *Matx) (result *Matx, err error) {
+	if span := startTraceShapes("MultiplyCSRDense", sparseShape(a), dimsOf(b)); span != nil {
+		defer func() { span.end(2*int64(a.NNZ())*dimOf(b, 1), 8*elementCount(result), err) }()
+	}
+
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("one or both input matrices are nil")
+	}
+	if len(b.Dimensions) != 2 || a.Cols != b.Dimensions[0] {
+		return nil, fmt.Errorf("multiplication not possible: sparse %dx%d by dense %v", a.Rows, a.Cols, b.Dimensions)
+	}
+
+	cols := b.Dimensions[1]
+	result, err = Zeros([]int{a.Rows, cols})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create result matrix: %w", err)
+	}
+
+	parallelFor(a.Rows, sparseWorkers(a.NNZ()*cols), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			outRow := result.Data[i*cols : (i+1)*cols]
+			for k := a.RowPtr[i]; k < a.RowPtr[i+1]; k++ {
+				v := a.Values[k]
+				bRow := b.Data[a.ColIdx[k]*cols : (a.ColIdx[k]+1)*cols]
+				for j, bv := range bRow {
+					outRow[j] += v * bv
+				}
+			}
+		}
+	})
+	return result, nil
+}
+
+// MultiplyDenseCSR returns the dense product of a 2D dense matrix `a` and a sparse matrix `b`,
+// computed in parallel over the rows of `a`.
+func MultiplyDenseCSR(a *Matx, b *CSR) (result *Matx, err error) {
+	if span := startTraceShapes("MultiplyDenseCSR", dimsOf(a), sparseShape(b)); span != nil {
+		defer func() { span.end(2*int64(b.NNZ())*dimOf(a, 0), 8*elementCount(result), err) }()
+	}
+
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("one or both input matrices are nil")
+	}
+	if len(a.Dimensions) != 2 || a.Dimensions[1] != b.Rows {
+		return nil, fmt.Errorf("multiplication not possible: dense %v by sparse %dx%d", a.Dimensions, b.Rows, b.Cols)
+	}
+
+	rows, inner := a.Dimensions[0], a.Dimensions[1]
+	result, err = Zeros([]int{rows, b.Cols})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create result matrix: %w", err)
+	}
+
+	parallelFor(rows, sparseWorkers(b.NNZ()*rows), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			outRow := result.Data[i*b.Cols : (i+1)*b.Cols]
+			for k := 0; k < inner; k++ {
+				v := a.Data[i*inner+k]
+				for p := b.RowPtr[k]; p < b.RowPtr[k+1]; p++ {
+					outRow[b.ColIdx[p]] += v * b.Values[p]
+				}
+			}
+		}
+	})
+	return result, nil
+}
+
+// MultiplyCSR returns the sparse product a·b. It runs SymbolicMultiplyCSR to find the
+// sparsity pattern and NumericMultiplyCSR to fill in the values.
+func MultiplyCSR(a, b *CSR) (result *CSR, err error) {
+	if span := startTraceShapes("MultiplyCSR", sparseShape(a), sparseShape(b)); span != nil {
+		// The values are counted by the nested NumericMultiplyCSR event
+		defer func() { span.end(0, 16*int64(result.NNZ()), err) }()
+	}
+
+	result, err = SymbolicMultiplyCSR(a, b)
+	if err != nil {
+		return nil, err
+	}
+	if err := NumericMultiplyCSR(result, a, b); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SymbolicMultiplyCSR computes the sparsity pattern of a·b (Gustavson's algorithm).
+// The returned matrix has sorted column indices and zero values; pass it to
+// NumericMultiplyCSR, repeatedly if a and b change values but keep their patterns.
+func SymbolicMultiplyCSR(a, b *CSR) (*CSR, error) {
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("one or both input matrices are nil")
+	}
+	if a.Cols != b.Rows {
+		return nil, fmt.Errorf("multiplication not possible: a columns (%d) != b rows (%d)", a.Cols, b.Rows)
+	}
+
+	rowPtr := make([]int, a.Rows+1)
+	var colIdx []int
+	marker := make([]int, b.Cols)
+	for j := range marker {
+		marker[j] = -1
+	}
+
+	for i := 0; i < a.Rows; i++ {
+		start := len(colIdx)
+		for k := a.RowPtr[i]; k < a.RowPtr[i+1]; k++ {
+			r := a.ColIdx[k]
+			for p := b.RowPtr[r]; p < b.RowPtr[r+1]; p++ {
+				if j := b.ColIdx[p]; marker[j] != i {
+					marker[j] = i
+					colIdx = append(colIdx, j)
+				}
+			}
+		}
+		sort.Ints(colIdx[start:])
+		rowPtr[i+1] = len(colIdx)
+	}
+
+	return &CSR{
+		Rows:   a.Rows,
+		Cols:   b.Cols,
+		RowPtr: rowPtr,
+		ColIdx: colIdx,
+		Values: make([]float64, len(colIdx)),
+	}, nil
+}
+
+// NumericMultiplyCSR overwrites the values of `c` with a·b, where `c` holds the pattern
+// produced by SymbolicMultiplyCSR for matrices with the same sparsity as a and b.
+// Rows are processed in parallel, each worker with its own dense accumulator.
+func NumericMultiplyCSR(c, a, b *CSR) (err error) {
+	if span := startTraceShapes("NumericMultiplyCSR", sparseShape(a), sparseShape(b)); span != nil {
+		defer func() { span.end(2*int64(c.NNZ()), 0, err) }()
+	}
+
+	if c == nil || a == nil || b == nil {
+		return fmt.Errorf("one or more matrices are nil")
+	}
+	if a.Cols != b.Rows || c.Rows != a.Rows || c.Cols != b.Cols {
+		return fmt.Errorf("pattern %dx%d does not match product of %dx%d and %dx%d",
+			c.Rows, c.Cols, a.Rows, a.Cols, b.Rows, b.Cols)
+	}
+
+	var (
+		mu      sync.Mutex
+		missing error
+	)
+	parallelFor(a.Rows, sparseWorkers(a.NNZ()+b.NNZ()), func(lo, hi int) {
+		acc := make([]float64, b.Cols)
+		inPattern := make([]bool, b.Cols)
+		for i := lo; i < hi; i++ {
+			for p := c.RowPtr[i]; p < c.RowPtr[i+1]; p++ {
+				inPattern[c.ColIdx[p]] = true
+			}
+			for k := a.RowPtr[i]; k < a.RowPtr[i+1]; k++ {
+				av, r := a.Values[k], a.ColIdx[k]
+				for p := b.RowPtr[r]; p < b.RowPtr[r+1]; p++ {
+					j := b.ColIdx[p]
+					if !inPattern[j] {
+						mu.Lock()
+						missing = fmt.Errorf("entry (%d, %d) of the product is outside the given pattern", i, j)
+						mu.Unlock()
+						continue
+					}
+					acc[j] += av * b.Values[p]
+				}
+			}
+			for p := c.RowPtr[i]; p < c.RowPtr[i+1]; p++ {
+				j := c.ColIdx[p]
+				c.Values[p] = acc[j]
+				acc[j], inPattern[j] = 0, false
+			}
+		}
+	})
+	return missing
+}
+
+// AddCSR returns the element-wise sum of two sparse matrices of the same shape.
+func AddCSR(a, b *CSR) (result *CSR, err error) {
+	if span := startTraceShapes("AddCSR", sparseShape(a), sparseShape(b)); span != nil {
+		defer func() { span.end(int64(result.NNZ()), 16*int64(result.NNZ()), err) }()
+	}
+
+	return mergeCSR(a, b, true)
+}
+
+// HadamardCSR returns the element-wise product of two sparse matrices of the same shape.
+// Only coordinates stored in both inputs can be nonzero in the result.
+func HadamardCSR(a, b *CSR) (result *CSR, err error) {
+	if span := startTraceShapes("HadamardCSR", sparseShape(a), sparseShape(b)); span != nil {
+		defer func() { span.end(int64(result.NNZ()), 16*int64(result.NNZ()), err) }()
+	}
+
+	return mergeCSR(a, b, false)
+}
+
+// mergeCSR walks the sorted rows of a and b together, taking the union of the patterns
+// when `union` is set (sum) and the intersection otherwise (product).
+func mergeCSR(a, b *CSR, union bool) (*CSR, error) {
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("one or both matrices are nil")
+	}
+	if a.Rows != b.Rows || a.Cols != b.Cols {
+		return nil, fmt.Errorf("dimension mismatch: %dx%d vs %dx%d", a.Rows, a.Cols, b.Rows, b.Cols)
+	}
+
+	out := &CSR{Rows: a.Rows, Cols: a.Cols, RowPtr: make([]int, a.Rows+1)}
+	for i := 0; i < a.Rows; i++ {
+		p, pEnd := a.RowPtr[i], a.RowPtr[i+1]
+		q, qEnd := b.RowPtr[i], b.RowPtr[i+1]
+		for p < pEnd || q < qEnd {
+			switch {
+			case q >= qEnd || (p < pEnd && a.ColIdx[p] < b.ColIdx[q]):
+				if union {
+					out.ColIdx = append(out.ColIdx, a.ColIdx[p])
+					out.Values = append(out.Values, a.Values[p])
+				}
+				p++
+			case p >= pEnd || b.ColIdx[q] < a.ColIdx[p]:
+				if union {
+					out.ColIdx = append(out.ColIdx, b.ColIdx[q])
+					out.Values = append(out.Values, b.Values[q])
+				}
+				q++
+			default:
+				v := a.Values[p] * b.Values[q]
+				if union {
+					v = a.Values[p] + b.Values[q]
+				}
+				out.ColIdx = append(out.ColIdx, a.ColIdx[p])
+				out.Values = append(out.Values, v)
+				p++
+				q++
+			}
+		}
+		out.RowPtr[i+1] = len(out.ColIdx)
+	}
+	return out, nil
+}
+
+// Scale multiplies every stored value by `alpha` in place.
+func (s *CSR) Scale(alpha float64) (err error) {
+	if span := startTraceShapes("ScaleCSR", sparseShape(s)); span != nil {
+		defer func() { span.end(int64(s.NNZ()), 0, err) }()
+	}
+
+	if s == nil {
+		return fmt.Errorf("nil matrix given")
+	}
+	for k := range s.Values {
+		s.Values[k] *= alpha
+	}
+	return nil
+}
+
+// SumCSR sums a sparse matrix along `axis`, like Sum on a dense 2D matrix:
+// axis 0 collapses rows (one value per column), axis 1 collapses columns (one per row).
+// Accumulation and parallelism follow the package-wide ReduceConfig.
+func SumCSR(s *CSR, axis int) (sum []float64, err error) {
+	if span := startTraceShapes("SumCSR", sparseShape(s)); span != nil {
+		defer func() { span.end(int64(s.NNZ()), 8*int64(len(sum)), err) }()
+	}
+
+	return sumCSR(s, axis, CurrentReduceConfig())
+}
+
+// sumCSR is the untraced body shared by SumCSR and MeanCSR. Along axis 0 the values
+// are first gathered by column, so that every lane is summed with cfg.Summation.
+func sumCSR(s *CSR, axis int, cfg ReduceConfig) ([]float64, error) {
+	if s == nil {
+		return nil, fmt.Errorf("Matrix is nil")
+	}
+
+	var ptr []int
+	var values []float64
+	switch axis {
+	case 0:
+		ptr, _, values = transposeCompressed(s.Rows, s.Cols, s.RowPtr, s.ColIdx, s.Values)
+	case 1:
+		ptr, values = s.RowPtr, s.Values
+	default:
+		return nil, fmt.Errorf("Invalid axis")
+	}
+
+	out := make([]float64, len(ptr)-1)
+	parallelFor(len(out), sparseWorkers(s.NNZ()), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			out[i] = sumStrided(values, ptr[i], 1, ptr[i+1]-ptr[i], cfg.Summation)
+		}
+	})
+	return out, nil
+}
+
+// MeanCSR averages a sparse matrix along `axis`, counting implicit zeros.
+func MeanCSR(s *CSR, axis int) (mean []float64, err error) {
+	if span := startTraceShapes("MeanCSR", sparseShape(s)); span != nil {
+		defer func() { span.end(int64(s.NNZ())+int64(len(mean)), 8*int64(len(mean)), err) }()
+	}
+
+	sum, err := sumCSR(s, axis, CurrentReduceConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	count := float64(s.Cols)
+	if axis == 0 {
+		count = float64(s.Rows)
+	}
+	for i := range sum {
+		sum[i] /= count
+	}
+	return sum, nil
+}
+
+// MinCSR returns the minimum along `axis`; implicit zeros take part in the comparison.
+func MinCSR(s *CSR, axis int) (result []float64, err error) {
+	if span := startTraceShapes("MinCSR", sparseShape(s)); span != nil {
+		defer func() { span.end(int64(s.NNZ()), 8*int64(len(result)), err) }()
+	}
+
+	return extremeCSR(s, axis, true)
+}
+
+// MaxCSR returns the maximum along `axis`; implicit zeros take part in the comparison.
+func MaxCSR(s *CSR, axis int) (result []float64, err error) {
+	if span := startTraceShapes("MaxCSR", sparseShape(s)); span != nil {
+		defer func() { span.end(int64(s.NNZ()), 8*int64(len(result)), err) }()
+	}
+
+	return extremeCSR(s, axis, false)
+}
+
+// extremeCSR implements MinCSR (less == true) and MaxCSR.
+func extremeCSR(s *CSR, axis int, less bool) ([]float64, error) {
+	if s == nil || axis < 0 || axis > 1 {
+		return nil, fmt.Errorf("Invalid input or axis")
+	}
+	if (axis == 0 && s.Rows == 0) || (axis == 1 && s.Cols == 0) {
+		return nil, fmt.Errorf("cannot reduce along empty axis %d", axis)
+	}
+
+	better := func(v, best float64) bool {
+		if less {
+			return v < best
+		}
+		return v > best
+	}
+	start := math.Inf(1)
+	if !less {
+		start = math.Inf(-1)
+	}
+
+	if axis == 1 {
+		out := make([]float64, s.Rows)
+		parallelFor(s.Rows, sparseWorkers(s.NNZ()), func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				best := start
+				if s.RowPtr[i+1]-s.RowPtr[i] < s.Cols {
+					best = 0
+				}
+				for k := s.RowPtr[i]; k < s.RowPtr[i+1]; k++ {
+					if better(s.Values[k], best) {
+						best = s.Values[k]
+					}
+				}
+				out[i] = best
+			}
+		})
+		return out, nil
+	}
+
+	out := make([]float64, s.Cols)
+	counts := make([]int, s.Cols)
+	for j := range out {
+		out[j] = start
+	}
+	for k, j := range s.ColIdx {
+		counts[j]++
+		if better(s.Values[k], out[j]) {
+			out[j] = s.Values[k]
+		}
+	}
+	for j := range out {
+		if counts[j] < s.Rows && better(0, out[j]) {
+			out[j] = 0
+		}
+	}
+	return out, nil
+}
+
+// dimsOf returns the Dimensions of `m`, or nil for a nil matrix.
+func dimsOf(m *Matx) []int {
+	if m == nil {
+		return nil
+	}
+	return m.Dimensions
+}
@@ -0,0 +1,149 @@
+package matx
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// randomSparse returns a dense rows×cols matrix with roughly `density` nonzeros.
+func randomSparse(rows, cols int, density float64) *Matx {
+	m, _ := Rand(rows, cols)
+	for i, v := range m.Data {
+		if v > density {
+			m.Data[i] = 0
+		} else {
+			m.Data[i] = v/density*2 - 1
+		}
+	}
+	return m
+}
+
+func closeSlices(a, b []float64, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSparseOps(t *testing.T) {
+	n := 1
+
+	{ // SpMV against dense Multiply
+		m := begin(t, n, "CSR.MulVec() / MulVecTrans()")
+		n++
+		a := randomSparse(60, 40, 0.1)
+		csr, _ := CSRFromMatx(a)
+		csc, _ := CSCFromMatx(a)
+		x, _ := Rand(40, 1)
+		want, _ := Multiply(a, x)
+		y1, err1 := csr.MulVec(x.Data)
+		y2, err2 := csc.MulVec(x.Data)
+
+		xt, _ := Rand(1, 60)
+		wantT, _ := Multiply(xt, a)
+		yt, err3 := csr.MulVecTrans(xt.Data)
+		var nilCSR *CSR
+		var nilCSC *CSC
+		_, errNil1 := nilCSR.MulVec(x.Data)
+		_, errNil2 := nilCSR.MulVecTrans(x.Data)
+		_, errNil3 := nilCSC.MulVec(x.Data)
+		m.end(err1 == nil && err2 == nil && err3 == nil && errNil1 != nil && errNil2 != nil && errNil3 != nil &&
+			closeSlices(y1, want.Data, 1e-12) && closeSlices(y2, want.Data, 1e-12) &&
+			closeSlices(yt, wantT.Data, 1e-12))
+	}
+
+	{ // Sparse × dense and dense × sparse
+		m := begin(t, n, "MultiplyCSRDense()/DenseCSR()")
+		n++
+		a := randomSparse(30, 20, 0.2)
+		b, _ := Rand(20, 7)
+		c, _ := Rand(5, 30)
+		csr, _ := CSRFromMatx(a)
+		want1, _ := Multiply(a, b)
+		want2, _ := Multiply(c, a)
+		got1, err1 := MultiplyCSRDense(csr, b)
+		got2, err2 := MultiplyDenseCSR(c, csr)
+		// A zero dense entry times a stored Inf is NaN, as in Multiply
+		z, _ := New([]float64{0, 1}, []int{1, 2})
+		inf, _ := NewCSR(2, 1, []int{0, 1, 2}, []int{0, 0}, []float64{math.Inf(1), 2})
+		nan, err3 := MultiplyDenseCSR(z, inf)
+		m.end(err1 == nil && err2 == nil && err3 == nil && math.IsNaN(nan.Data[0]) &&
+			closeSlices(got1.Data, want1.Data, 1e-12) && closeSlices(got2.Data, want2.Data, 1e-12))
+	}
+
+	{ // Sparse × sparse with pattern reuse
+		m := begin(t, n, "MultiplyCSR() symbolic/numeric")
+		n++
+		a := randomSparse(25, 30, 0.15)
+		b := randomSparse(30, 20, 0.15)
+		sa, _ := CSRFromMatx(a)
+		sb, _ := CSRFromMatx(b)
+		want, _ := Multiply(a, b)
+		c, err := MultiplyCSR(sa, sb)
+		got, _ := c.ToMatx()
+		ok := err == nil && closeSlices(got.Data, want.Data, 1e-12)
+
+		sa.Scale(2)
+		ok = ok && NumericMultiplyCSR(c, sa, sb) == nil
+		got, _ = c.ToMatx()
+		for i := range want.Data {
+			want.Data[i] *= 2
+		}
+		m.end(ok && closeSlices(got.Data, want.Data, 1e-12))
+	}
+
+	{ // Element-wise operations
+		m := begin(t, n, "AddCSR()/HadamardCSR()")
+		n++
+		a := randomSparse(10, 12, 0.3)
+		b := randomSparse(10, 12, 0.3)
+		sa, _ := CSRFromMatx(a)
+		sb, _ := CSRFromMatx(b)
+		wantAdd, _ := Add(a, b)
+		wantHad, _ := Hadamard(a, b)
+		sum, err1 := AddCSR(sa, sb)
+		had, err2 := HadamardCSR(sa, sb)
+		gotAdd, _ := sum.ToMatx()
+		gotHad, _ := had.ToMatx()
+		m.end(err1 == nil && err2 == nil &&
+			reflect.DeepEqual(gotAdd.Data, wantAdd.Data) && reflect.DeepEqual(gotHad.Data, wantHad.Data))
+	}
+
+	{ // Reductions agree with the dense versions
+		m := begin(t, n, "SumCSR()/MinCSR()/MaxCSR()")
+		n++
+		a, _ := New([]float64{0, -2, 3, 4, 0, 0, 0, 5, 6, 1, 1, 1}, []int{3, 4})
+		s, _ := CSRFromMatx(a)
+		ok := true
+		for axis := 0; axis < 2; axis++ {
+			ws, _ := Sum(a, axis)
+			wmin, _ := Min(a, axis)
+			wmax, _ := Max(a, axis)
+			wmean, _ := Mean(a, axis)
+			gs, _ := SumCSR(s, axis)
+			gmin, _ := MinCSR(s, axis)
+			gmax, _ := MaxCSR(s, axis)
+			gmean, _ := MeanCSR(s, axis)
+			ok = ok && reflect.DeepEqual(ws, gs) && reflect.DeepEqual(wmin, gmin) &&
+				reflect.DeepEqual(wmax, gmax) && closeSlices(wmean, gmean, 1e-15)
+		}
+
+		// Both axes follow the configured summation; naive order would lose the 1
+		prev := CurrentReduceConfig()
+		cfg := prev
+		cfg.Summation = KahanSummation
+		SetReduceConfig(cfg)
+		c, _ := New([]float64{1e16, 1, -1e16, 1, 0, 0, -1e16, 0, 0}, []int{3, 3})
+		sc, _ := CSRFromMatx(c)
+		cols, _ := SumCSR(sc, 0)
+		rows, _ := SumCSR(sc, 1)
+		SetReduceConfig(prev)
+		m.end(ok && cols[0] == 1 && rows[0] == 1)
+	}
+}
@@ -0,0 +1,62 @@
+package matx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSparse(t *testing.T) {
+	n := 1
+	InitExamples()
+	dense, _ := GiveMatx("matxSparse4x4")
+
+	{ // Round trip through every format
+		m := begin(t, n, "CSR/CSC/COO round trip")
+		n++
+		coo, err := COOFromMatx(dense)
+		ok := err == nil && coo.NNZ() == 3
+		back1, _ := coo.ToCSR().ToMatx()
+		back2, _ := coo.ToCSC().ToMatx()
+		back3, _ := coo.ToCSR().ToCSC().ToCSR().ToCOO().ToMatx()
+		ok = ok && reflect.DeepEqual(back1.Data, dense.Data) &&
+			reflect.DeepEqual(back2.Data, dense.Data) &&
+			reflect.DeepEqual(back3.Data, dense.Data)
+		m.end(ok)
+	}
+
+	{ // Duplicates are summed and columns sorted
+		m := begin(t, n, "COO.ToCSR() merges duplicates")
+		n++
+		coo, _ := NewCOO(2, 3, []int{1, 0, 1, 1}, []int{2, 1, 0, 2}, []float64{1, 5, 2, 3})
+		csr := coo.ToCSR()
+		v, _ := csr.At(1, 2)
+		z, _ := csr.At(0, 0)
+		m.end(reflect.DeepEqual(csr.RowPtr, []int{0, 1, 3}) &&
+			reflect.DeepEqual(csr.ColIdx, []int{1, 0, 2}) &&
+			v == 4 && z == 0)
+	}
+
+	{ // Transpose agrees with dense Transpose
+		m := begin(t, n, "CSR.Transpose()")
+		n++
+		a, _ := New([]float64{1, 0, 2, 0, 0, 3}, []int{2, 3})
+		csr, _ := CSRFromMatx(a)
+		want, _ := Transpose(a)
+		got1, _ := csr.Transpose().ToMatx()
+		csc, _ := CSCFromMatx(a)
+		got2, _ := csc.Transpose().ToMatx()
+		m.end(reflect.DeepEqual(got1.Data, want.Data) && reflect.DeepEqual(got2.Data, want.Data))
+	}
+
+	{ // Validation
+		m := begin(t, n, "NewCSR()/NewCOO() validation")
+		n++
+		_, err1 := NewCSR(2, 2, []int{0, 2, 1}, []int{0, 1}, []float64{1, 2})
+		_, err2 := NewCSR(1, 2, []int{0, 2}, []int{1, 0}, []float64{1, 2})
+		_, err3 := NewCOO(2, 2, []int{2}, []int{0}, []float64{1})
+		_, err4 := NewCSC(2, 2, []int{0, 1, 2}, []int{1, 0}, []float64{1, 2})
+		_, err5 := NewCSR(2, 3, []int{0, 5, 2}, []int{0, 1}, []float64{1, 2})
+		_, err6 := NewCSC(3, 2, []int{0, -1, 2}, []int{0, 1}, []float64{1, 2})
+		m.end(err1 != nil && err2 != nil && err3 != nil && err4 == nil && err5 != nil && err6 != nil)
+	}
+}
@@ -10,7 +10,16 @@ import "fmt"
 // - A slice containing the mean values along the axis
 // - An error if computation fails (e.g., invalid axis)
 func Mean(m *Matx, axis int) ([]float64, error) {
-	sum, err := Sum(m, axis)
+	return MeanWith(m, axis, CurrentReduceConfig())
+}
+
+// MeanWith is Mean with an explicit reduction configuration instead of the package default.
+func MeanWith(m *Matx, axis int, cfg ReduceConfig) (mean []float64, err error) {
+	if span := startTrace("Mean", m); span != nil {
+		defer func() { span.end(elementCount(m)+int64(len(mean)), 8*int64(len(mean)), err) }()
+	}
+
+	sum, err := sumAxis(m, axis, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -26,6 +35,7 @@ func Mean(m *Matx, axis int) ([]float64, error) {
 }
 
 // Sum computes the sum of matrix values along the specified axis.
+// Accumulation and parallelism follow the package-wide ReduceConfig.
 // Parameters:
 // - m: input matrix
 // - axis: the axis along which the summation is performed
@@ -33,47 +43,26 @@ func Mean(m *Matx, axis int) ([]float64, error) {
 // - A slice containing the summed values along the axis
 // - An error if input is nil or axis is out of bounds
 func Sum(m *Matx, axis int) ([]float64, error) {
-	if m == nil {
-		return nil, fmt.Errorf("Matrix is nil")
-	}
-	if axis < 0 || axis >= len(m.Dimensions) {
-		return nil, fmt.Errorf("Invalid axis")
-	}
+	return SumWith(m, axis, CurrentReduceConfig())
+}
 
-	// Calculate stride: product of dimensions after the target axis
-	stride := 1
-	for i := axis + 1; i < len(m.Dimensions); i++ {
-		stride *= m.Dimensions[i]
+// SumWith is Sum with an explicit reduction configuration instead of the package default.
+func SumWith(m *Matx, axis int, cfg ReduceConfig) (sum []float64, err error) {
+	if span := startTrace("Sum", m); span != nil {
+		defer func() { span.end(elementCount(m), 8*int64(len(sum)), err) }()
 	}
 
-	block := m.Dimensions[axis] * stride // Total elements in a single axis block
-	numBlocks := len(m.Data) / block     // Number of such blocks in the flat data slice
-
-	// Calculate output shape (excluding the specified axis)
-	outShape := append([]int{}, m.Dimensions[:axis]...)
-	outShape = append(outShape, m.Dimensions[axis+1:]...)
-
-	// Determine size of output
-	outSize := 1
-	for _, s := range outShape {
-		outSize *= s
-	}
+	return sumAxis(m, axis, cfg)
+}
 
-	result := make([]float64, outSize)
-
-	// Perform summation along the axis
-	for b := 0; b < numBlocks; b++ {
-		for i := 0; i < stride; i++ {
-			sum := 0.0
-			for j := 0; j < m.Dimensions[axis]; j++ {
-				idx := b*block + j*stride + i
-				sum += m.Data[idx]
-			}
-			result[b*stride+i] = sum
-		}
+// sumAxis is the untraced body shared by SumWith and MeanWith.
+func sumAxis(m *Matx, axis int, cfg ReduceConfig) ([]float64, error) {
+	layout, err := newAxisLayout(m, axis)
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return sumLanes(m.Data, layout, cfg), nil
 }
 
 // Min returns the minimum value along the specified axis.
@@ -83,37 +72,17 @@ func Sum(m *Matx, axis int) ([]float64, error) {
 // Returns:
 // - A slice of minimum values along the axis
 // - An error if input is nil or axis is invalid
-func Min(m *Matx, axis int) ([]float64, error) {
-	if m == nil || axis < 0 || axis >= len(m.Dimensions) {
-		return nil, fmt.Errorf("Invalid input or axis")
-	}
-
-	stride := 1
-	for i := axis + 1; i < len(m.Dimensions); i++ {
-		stride *= m.Dimensions[i]
+func Min(m *Matx, axis int) (result []float64, err error) {
+	if span := startTrace("Min", m); span != nil {
+		defer func() { span.end(elementCount(m), 8*int64(len(result)), err) }()
 	}
-	block := m.Dimensions[axis] * stride
-	numBlocks := len(m.Data) / block
 
-	result := make([]float64, numBlocks*stride)
-
-	// Initialize result with the first element of each slice
-	for i := range result {
-		result[i] = m.Data[i]
+	layout, err := extremeLayout(m, axis)
+	if err != nil {
+		return nil, err
 	}
 
-	// Compute minimum across axis
-	for b := 0; b < numBlocks; b++ {
-		for i := 0; i < stride; i++ {
-			for j := 1; j < m.Dimensions[axis]; j++ {
-				idx := b*block + j*stride + i
-				outIdx := b*stride + i
-				if m.Data[idx] < result[outIdx] {
-					result[outIdx] = m.Data[idx]
-				}
-			}
-		}
-	}
+	result, _ = extremeLanes(m.Data, layout, CurrentReduceConfig(), true)
 	return result, nil
 }
 
@@ -124,37 +93,17 @@ func Min(m *Matx, axis int) ([]float64, error) {
 // Returns:
 // - A slice of maximum values along the axis
 // - An error if input is nil or axis is invalid
-func Max(m *Matx, axis int) ([]float64, error) {
-	if m == nil || axis < 0 || axis >= len(m.Dimensions) {
-		return nil, fmt.Errorf("Invalid input or axis")
+func Max(m *Matx, axis int) (result []float64, err error) {
+	if span := startTrace("Max", m); span != nil {
+		defer func() { span.end(elementCount(m), 8*int64(len(result)), err) }()
 	}
 
-	stride := 1
-	for i := axis + 1; i < len(m.Dimensions); i++ {
-		stride *= m.Dimensions[i]
-	}
-	block := m.Dimensions[axis] * stride
-	numBlocks := len(m.Data) / block
-
-	result := make([]float64, numBlocks*stride)
-
-	// Initialize result with the first element of each slice
-	for i := range result {
-		result[i] = m.Data[i]
+	layout, err := extremeLayout(m, axis)
+	if err != nil {
+		return nil, err
 	}
 
-	// Compute maximum across axis
-	for b := 0; b < numBlocks; b++ {
-		for i := 0; i < stride; i++ {
-			for j := 1; j < m.Dimensions[axis]; j++ {
-				idx := b*block + j*stride + i
-				outIdx := b*stride + i
-				if m.Data[idx] > result[outIdx] {
-					result[outIdx] = m.Data[idx]
-				}
-			}
-		}
-	}
+	result, _ = extremeLanes(m.Data, layout, CurrentReduceConfig(), false)
 	return result, nil
 }
 
@@ -165,35 +114,17 @@ func Max(m *Matx, axis int) ([]float64, error) {
 // Returns:
 // - A slice of indices corresponding to the maximum value in each slice
 // - An error if input is nil or axis is invalid
-func ArgMax(m *Matx, axis int) ([]int, error) {
-	if m == nil || axis < 0 || axis >= len(m.Dimensions) {
-		return nil, fmt.Errorf("Invalid input or axis")
+func ArgMax(m *Matx, axis int) (result []int, err error) {
+	if span := startTrace("ArgMax", m); span != nil {
+		defer func() { span.end(elementCount(m), 8*int64(len(result)), err) }()
 	}
 
-	stride := 1
-	for i := axis + 1; i < len(m.Dimensions); i++ {
-		stride *= m.Dimensions[i]
-	}
-	block := m.Dimensions[axis] * stride
-	numBlocks := len(m.Data) / block
-
-	result := make([]int, numBlocks*stride)
-
-	// Compute index of maximum value across axis
-	for b := 0; b < numBlocks; b++ {
-		for i := 0; i < stride; i++ {
-			maxVal := m.Data[b*block+i]
-			maxIdx := 0
-			for j := 1; j < m.Dimensions[axis]; j++ {
-				idx := b*block + j*stride + i
-				if m.Data[idx] > maxVal {
-					maxVal = m.Data[idx]
-					maxIdx = j
-				}
-			}
-			result[b*stride+i] = maxIdx
-		}
+	layout, err := extremeLayout(m, axis)
+	if err != nil {
+		return nil, err
 	}
+
+	_, result = extremeLanes(m.Data, layout, CurrentReduceConfig(), false)
 	return result, nil
 }
 
@@ -204,34 +135,27 @@ func ArgMax(m *Matx, axis int) ([]int, error) {
 // Returns:
 // - A slice of indices corresponding to the minimum value in each slice
 // - An error if input is nil or axis is invalid
-func ArgMin(m *Matx, axis int) ([]int, error) {
-	if m == nil || axis < 0 || axis >= len(m.Dimensions) {
-		return nil, fmt.Errorf("Invalid input or axis")
+func ArgMin(m *Matx, axis int) (result []int, err error) {
+	if span := startTrace("ArgMin", m); span != nil {
+		defer func() { span.end(elementCount(m), 8*int64(len(result)), err) }()
 	}
 
-	stride := 1
-	for i := axis + 1; i < len(m.Dimensions); i++ {
-		stride *= m.Dimensions[i]
-	}
-	block := m.Dimensions[axis] * stride
-	numBlocks := len(m.Data) / block
-
-	result := make([]int, numBlocks*stride)
-
-	// Compute index of minimum value across axis
-	for b := 0; b < numBlocks; b++ {
-		for i := 0; i < stride; i++ {
-			minVal := m.Data[b*block+i]
-			minIdx := 0
-			for j := 1; j < m.Dimensions[axis]; j++ {
-				idx := b*block + j*stride + i
-				if m.Data[idx] < minVal {
-					minVal = m.Data[idx]
-					minIdx = j
-				}
-			}
-			result[b*stride+i] = minIdx
-		}
+	layout, err := extremeLayout(m, axis)
+	if err != nil {
+		return nil, err
 	}
+
+	_, result = extremeLanes(m.Data, layout, CurrentReduceConfig(), true)
 	return result, nil
 }
+
+// extremeLayout validates input for the min/max family, which needs a non-empty axis.
+func extremeLayout(m *Matx, axis int) (axisLayout, error) {
+	if m == nil || axis < 0 || axis >= len(m.Dimensions) {
+		return axisLayout{}, fmt.Errorf("Invalid input or axis")
+	}
+	if m.Dimensions[axis] == 0 {
+		return axisLayout{}, fmt.Errorf("cannot reduce along empty axis %d", axis)
+	}
+	return newAxisLayout(m, axis)
+}
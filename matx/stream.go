@@ -0,0 +1,574 @@
+package matx
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// ChunkReader yields a matrix in blocks of rows along axis 0. Next returns io.EOF
+// once every row has been returned; any other error is final.
+type ChunkReader interface {
+	Next() (*Matx, error)
+}
+
+// ChunkWriter appends blocks of rows to a stream. Close finishes the stream without
+// closing the underlying writer.
+type ChunkWriter interface {
+	Write(block *Matx) error
+	Close() error
+}
+
+// CSVChunkReader reads CSV input as rows×cols chunks, holding only one chunk in memory.
+type CSVChunkReader struct {
+	dec  *csvDecoder
+	rows int
+	err  error
+}
+
+// NewCSVChunkReader returns a reader yielding chunks of up to `rows` rows from `r`.
+// Options and errors are those of ReadCSV; the header, if any, is read immediately.
+func NewCSVChunkReader(r io.Reader, rows int, opts CSVOptions) (*CSVChunkReader, error) {
+	if rows <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive, got %d", rows)
+	}
+	dec, err := newCSVDecoder(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &CSVChunkReader{dec: dec, rows: rows}, nil
+}
+
+// Names returns the selected header names when opts.Header was set, otherwise nil.
+func (c *CSVChunkReader) Names() []string {
+	return c.dec.names
+}
+
+// Next returns the next chunk; only the last one may have fewer rows.
+func (c *CSVChunkReader) Next() (*Matx, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	cols := len(c.dec.cols)
+	data := make([]float64, 0, c.rows*cols)
+	n := 0
+	for ; n < c.rows; n++ {
+		var err error
+		if data, err = c.dec.readRow(data); err == io.EOF {
+			c.err = io.EOF
+			break
+		} else if err != nil {
+			c.err = err
+			return nil, err
+		}
+	}
+	if n == 0 {
+		return nil, io.EOF
+	}
+	return New(data, []int{n, cols})
+}
+
+// CSVChunkWriter writes row blocks as CSV. The column count is fixed by opts.Names
+// or by the first block.
+type CSVChunkWriter struct {
+	cw     *csv.Writer
+	opts   CSVOptions
+	format func(float64) string
+	cols   int // -1 until known
+	header bool
+	closed bool
+}
+
+// NewCSVChunkWriter returns a writer that appends blocks to `w` with the WriteCSV
+// options; opts.Names is written as the header before the first row.
+func NewCSVChunkWriter(w io.Writer, opts CSVOptions) *CSVChunkWriter {
+	cw := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		cw.Comma = opts.Delimiter
+	}
+	cols := -1
+	if len(opts.Names) > 0 {
+		cols = len(opts.Names)
+	}
+	return &CSVChunkWriter{cw: cw, opts: opts, format: csvFormatter(opts), cols: cols}
+}
+
+// Write appends the rows of a 2D block. A 1D block is written as a single column,
+// as WriteCSV writes a 1D matrix, so a vector can be streamed in pieces.
+func (c *CSVChunkWriter) Write(block *Matx) error {
+	if c.closed {
+		return fmt.Errorf("csv: write to closed chunk writer")
+	}
+	if block == nil || block.Data == nil {
+		return fmt.Errorf("Matrix is nil")
+	}
+	var rows, cols int
+	switch len(block.Dimensions) {
+	case 1:
+		rows, cols = block.Dimensions[0], 1
+	case 2:
+		rows, cols = block.Dimensions[0], block.Dimensions[1]
+	default:
+		return fmt.Errorf("csv: chunk writer requires 1D or 2D blocks, got dimensions %v", block.Dimensions)
+	}
+	if c.cols >= 0 && cols != c.cols {
+		return fmt.Errorf("csv: block has %d columns, want %d", cols, c.cols)
+	}
+	c.cols = cols
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	if err := writeCSVRows(c.cw, block.Data, rows, cols, c.format); err != nil {
+		return err
+	}
+	if err := c.cw.Error(); err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+	return nil
+}
+
+// writeHeader writes opts.Names once.
+func (c *CSVChunkWriter) writeHeader() error {
+	if c.header || len(c.opts.Names) == 0 {
+		return nil
+	}
+	c.header = true
+	if err := c.cw.Write(c.opts.Names); err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+	return nil
+}
+
+// Close writes the header if no block was written and flushes buffered output.
+func (c *CSVChunkWriter) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.cw.Flush()
+	if err := c.cw.Error(); err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+	return nil
+}
+
+// streamMagic identifies the row stream written by BinaryChunkWriter.
+var streamMagic = [8]byte{'G', 'O', 'T', 'E', 'N', 'S', 'T', 0}
+
+// streamVersion is the current row stream version; newer streams are rejected.
+const streamVersion = 1
+
+// streamBlockElems caps the elements per written block so that readers never
+// allocate more than a few megabytes for one block, however large the chunks.
+const streamBlockElems = 1 << 20
+
+// The row stream exists alongside the native format of EncodeBinary because that
+// format stores the full shape, including the number of rows, before the data: a
+// writer that does not know how many rows will follow would have to seek back to
+// patch the header, which pipes and network connections cannot do. The row stream
+// stores only the row shape and ends with a marker block instead.
+//
+// The row stream format (little endian):
+// - 8 byte magic "GOTENST\x00", uint32 version
+// - uint32 number of row dimensions, one uint64 per dimension
+// - uint32 CRC-32 (IEEE) of the header
+// - blocks of uint32 row count, the float64 rows in row-major order and a uint32
+//   CRC-32 of the block; a block with a row count of 0 ends the stream
+
+// BinaryChunkWriter appends row blocks of a fixed row shape to a binary stream that
+// BinaryChunkReader reads back. Rows are stored as lossless float64.
+type BinaryChunkWriter struct {
+	w        *bufio.Writer
+	rowShape []int
+	rowSize  int
+	buf      []byte
+	closed   bool
+}
+
+// NewBinaryChunkWriter writes the stream header to `w`. Every row has shape
+// `rowShape`; an empty shape makes each row a single scalar.
+func NewBinaryChunkWriter(w io.Writer, rowShape []int) (*BinaryChunkWriter, error) {
+	rowSize := 1
+	for _, d := range rowShape {
+		if d < 0 {
+			return nil, fmt.Errorf("negative dimension in row shape %v", rowShape)
+		}
+		rowSize *= d
+	}
+	c := &BinaryChunkWriter{w: bufio.NewWriter(w), rowShape: append([]int{}, rowShape...), rowSize: rowSize}
+
+	header := make([]byte, 16+8*len(rowShape))
+	copy(header, streamMagic[:])
+	binary.LittleEndian.PutUint32(header[8:], streamVersion)
+	binary.LittleEndian.PutUint32(header[12:], uint32(len(rowShape)))
+	for i, d := range rowShape {
+		binary.LittleEndian.PutUint64(header[16+8*i:], uint64(d))
+	}
+	header = binary.LittleEndian.AppendUint32(header, crc32.ChecksumIEEE(header))
+	if _, err := c.w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write stream header: %w", err)
+	}
+	return c, nil
+}
+
+// Write appends a block of shape [n, rowShape...], or a single row of shape rowShape.
+// Output is buffered until Close.
+func (c *BinaryChunkWriter) Write(block *Matx) error {
+	if c.closed {
+		return fmt.Errorf("write to closed chunk writer")
+	}
+	if block == nil || block.Data == nil {
+		return fmt.Errorf("Matrix is nil")
+	}
+	rows := 1
+	switch len(block.Dimensions) {
+	case len(c.rowShape):
+		if !CheckDimensionEquality(block.Dimensions, c.rowShape) {
+			return fmt.Errorf("block dimensions %v do not match row shape %v", block.Dimensions, c.rowShape)
+		}
+	case len(c.rowShape) + 1:
+		if !CheckDimensionEquality(block.Dimensions[1:], c.rowShape) {
+			return fmt.Errorf("block dimensions %v do not match row shape %v", block.Dimensions, c.rowShape)
+		}
+		rows = block.Dimensions[0]
+	default:
+		return fmt.Errorf("block dimensions %v do not match row shape %v", block.Dimensions, c.rowShape)
+	}
+	if len(block.Data) != rows*c.rowSize {
+		return fmt.Errorf("data size %d does not match shape %v", len(block.Data), block.Dimensions)
+	}
+
+	perBlock := max(1, streamBlockElems/max(c.rowSize, 1))
+	for start := 0; start < rows; start += perBlock {
+		if err := c.writeBlock(block.Data, start, min(start+perBlock, rows)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBlock writes rows [lo, hi) of `data` as one block.
+func (c *BinaryChunkWriter) writeBlock(data []float64, lo, hi int) error {
+	values := data[lo*c.rowSize : hi*c.rowSize]
+	c.buf = binary.LittleEndian.AppendUint32(c.buf[:0], uint32(hi-lo))
+	for _, v := range values {
+		c.buf = binary.LittleEndian.AppendUint64(c.buf, math.Float64bits(v))
+	}
+	c.buf = binary.LittleEndian.AppendUint32(c.buf, crc32.ChecksumIEEE(c.buf))
+	if _, err := c.w.Write(c.buf); err != nil {
+		return fmt.Errorf("failed to write stream block: %w", err)
+	}
+	return nil
+}
+
+// Close writes the end marker and flushes buffered output. A stream that is not
+// closed reads back as truncated.
+func (c *BinaryChunkWriter) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if err := c.writeBlock(nil, 0, 0); err != nil {
+		return err
+	}
+	if err := c.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write stream block: %w", err)
+	}
+	return nil
+}
+
+// BinaryChunkReader reads a stream written by BinaryChunkWriter, or a matrix in the
+// native format of EncodeBinary and Save, in chunks of a fixed number of rows along
+// axis 0, regardless of the block sizes it was written with.
+type BinaryChunkReader struct {
+	r           *bufio.Reader
+	rows        int
+	rowShape    []int
+	rowSize     int
+	pending     []float64 // rows read but not yet returned
+	pendingRows int
+	done        bool // end marker seen
+	err         error
+
+	// Native format input; nil for row streams
+	native    *binaryHeader
+	cr        *crcReader
+	payload   io.Reader
+	remaining int // rows not yet read from the payload
+	block     []byte
+}
+
+// NewBinaryChunkReader reads the header from `r` and returns a reader yielding chunks
+// of up to `rows` rows. Both row streams and the native format are accepted, with
+// the first axis of a native matrix read as rows; its checksum is verified once the
+// last row has been read.
+func NewBinaryChunkReader(r io.Reader, rows int) (*BinaryChunkReader, error) {
+	if rows <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive, got %d", rows)
+	}
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(8); err == nil && [8]byte(magic) == binaryMagic {
+		return newNativeChunkReader(br, rows)
+	}
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(br, fixed); err != nil {
+		return nil, fmt.Errorf("failed to read stream header: %w", err)
+	}
+	if [8]byte(fixed[:8]) != streamMagic {
+		return nil, fmt.Errorf("not a matrix stream: bad magic %q", fixed[:8])
+	}
+	if v := binary.LittleEndian.Uint32(fixed[8:]); v == 0 || v > streamVersion {
+		return nil, fmt.Errorf("unsupported stream version %d (newest supported is %d)", v, streamVersion)
+	}
+	ndims := int(binary.LittleEndian.Uint32(fixed[12:]))
+	if ndims > 64 {
+		return nil, fmt.Errorf("invalid number of row dimensions in stream header: %d", ndims)
+	}
+	rest := make([]byte, 8*ndims+4)
+	if _, err := io.ReadFull(br, rest); err != nil {
+		return nil, fmt.Errorf("failed to read stream header: %w", noEOF(err))
+	}
+	crc := crc32.Update(crc32.ChecksumIEEE(fixed), crc32.IEEETable, rest[:8*ndims])
+	if got := binary.LittleEndian.Uint32(rest[8*ndims:]); got != crc {
+		return nil, fmt.Errorf("stream header checksum mismatch: stored %08x, computed %08x", got, crc)
+	}
+
+	c := &BinaryChunkReader{r: br, rows: rows, rowShape: make([]int, ndims), rowSize: 1}
+	for i := range c.rowShape {
+		d := binary.LittleEndian.Uint64(rest[8*i:])
+		if d > math.MaxInt32 || (d > 0 && uint64(c.rowSize) > math.MaxInt32/d) {
+			return nil, fmt.Errorf("stream row shape too large: %d at axis %d", d, i)
+		}
+		c.rowShape[i] = int(d)
+		c.rowSize *= c.rowShape[i]
+	}
+	return c, nil
+}
+
+// newNativeChunkReader reads the header of a native format matrix from `br`.
+func newNativeChunkReader(br *bufio.Reader, rows int) (*BinaryChunkReader, error) {
+	cr := &crcReader{r: br, crc: crc32.NewIEEE()}
+	h, err := readBinaryHeader(cr)
+	if err != nil {
+		return nil, err
+	}
+	c := &BinaryChunkReader{r: br, rows: rows, rowShape: h.dims[1:], rowSize: 1,
+		native: h, cr: cr, payload: h.payload(cr), remaining: h.dims[0]}
+	for _, d := range c.rowShape {
+		c.rowSize *= d
+	}
+	return c, nil
+}
+
+// noEOF reports a clean end of input inside a record as truncation.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// RowShape returns the shape of one row; chunks have shape [n, RowShape()...].
+func (c *BinaryChunkReader) RowShape() []int {
+	return append([]int{}, c.rowShape...)
+}
+
+// Next returns the next chunk; only the last one may have fewer rows. A stream
+// without its end marker fails with an error wrapping io.ErrUnexpectedEOF.
+func (c *BinaryChunkReader) Next() (*Matx, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	for c.pendingRows < c.rows && !c.done {
+		if err := c.readBlock(); err != nil {
+			c.err = err
+			return nil, err
+		}
+	}
+	if c.pendingRows == 0 {
+		c.err = io.EOF
+		return nil, io.EOF
+	}
+
+	n := min(c.rows, c.pendingRows)
+	data := append([]float64{}, c.pending[:n*c.rowSize]...)
+	c.pending = append(c.pending[:0], c.pending[n*c.rowSize:]...)
+	c.pendingRows -= n
+	return New(data, append([]int{n}, c.rowShape...))
+}
+
+// readBlock appends the rows of the next block to pending, verifying its checksum.
+func (c *BinaryChunkReader) readBlock() error {
+	if c.native != nil {
+		return c.readNativeBlock()
+	}
+	var head [4]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return fmt.Errorf("failed to read stream block: %w", noEOF(err))
+	}
+	rows := int(binary.LittleEndian.Uint32(head[:]))
+	if rows > 0 && rows*c.rowSize > max(streamBlockElems, c.rowSize) {
+		return fmt.Errorf("stream block of %d rows exceeds the block size limit", rows)
+	}
+
+	raw := make([]byte, 8*rows*c.rowSize+4)
+	if _, err := io.ReadFull(c.r, raw); err != nil {
+		return fmt.Errorf("failed to read stream block: %w", noEOF(err))
+	}
+	body := raw[:len(raw)-4]
+	crc := crc32.Update(crc32.ChecksumIEEE(head[:]), crc32.IEEETable, body)
+	if got := binary.LittleEndian.Uint32(raw[len(body):]); got != crc {
+		return fmt.Errorf("stream block checksum mismatch: stored %08x, computed %08x", got, crc)
+	}
+	if rows == 0 {
+		c.done = true
+		return nil
+	}
+	for i := 0; i < len(body); i += 8 {
+		c.pending = append(c.pending, math.Float64frombits(binary.LittleEndian.Uint64(body[i:])))
+	}
+	c.pendingRows += rows
+	return nil
+}
+
+// readNativeBlock appends up to streamBlockElems elements worth of rows from a native
+// format payload to pending, verifying the checksum after the last row.
+func (c *BinaryChunkReader) readNativeBlock() error {
+	if c.remaining == 0 {
+		c.done = true
+		return c.native.finish(c.cr, c.payload)
+	}
+	rows := min(c.remaining, max(1, streamBlockElems/max(c.rowSize, 1)))
+	if c.block == nil {
+		c.block = make([]byte, c.native.size*min(c.remaining*c.rowSize, max(streamBlockElems, c.rowSize)))
+	}
+	var err error
+	if c.pending, err = c.native.readElems(c.payload, c.pending, rows*c.rowSize, c.block); err != nil {
+		return err
+	}
+	c.remaining -= rows
+	c.pendingRows += rows
+	return nil
+}
+
+// AxisAccumulator computes Sum and Mean along axis 0 over a sequence of row blocks.
+// Each block is reduced with the package ReduceConfig and the partial sums are
+// combined with Neumaier compensation. The zero value is ready to use.
+type AxisAccumulator struct {
+	rowShape []int
+	rows     int
+	sum      []float64
+	comp     []float64
+}
+
+// Add accumulates the rows of `block`; every block must have the trailing dimensions
+// of the first one.
+func (a *AxisAccumulator) Add(block *Matx) error {
+	if block == nil || block.Data == nil {
+		return fmt.Errorf("Matrix is nil")
+	}
+	if len(block.Dimensions) == 0 {
+		return fmt.Errorf("Invalid axis")
+	}
+	if a.sum != nil && !CheckDimensionEquality(block.Dimensions[1:], a.rowShape) {
+		return fmt.Errorf("block dimensions %v do not match row shape %v", block.Dimensions, a.rowShape)
+	}
+	partial, err := sumAxis(block, 0, CurrentReduceConfig())
+	if err != nil {
+		return err
+	}
+	if a.sum == nil {
+		a.rowShape = append([]int{}, block.Dimensions[1:]...)
+		a.sum = make([]float64, len(partial))
+		a.comp = make([]float64, len(partial))
+	}
+
+	for i, v := range partial {
+		s := a.sum[i]
+		t := s + v
+		if math.IsInf(t, 0) || math.IsNaN(t) {
+			// Compensation is meaningless once the sum is no longer finite
+			a.sum[i] = t
+			continue
+		}
+		if math.Abs(s) >= math.Abs(v) {
+			a.comp[i] += (s - t) + v
+		} else {
+			a.comp[i] += (v - t) + s
+		}
+		a.sum[i] = t
+	}
+	a.rows += block.Dimensions[0]
+	return nil
+}
+
+// Rows returns the number of rows accumulated so far.
+func (a *AxisAccumulator) Rows() int {
+	return a.rows
+}
+
+// Sum returns the sum along axis 0 of every row added, in the layout of Sum(m, 0).
+func (a *AxisAccumulator) Sum() []float64 {
+	out := make([]float64, len(a.sum))
+	for i := range out {
+		out[i] = a.sum[i]
+		if !math.IsInf(a.sum[i], 0) && !math.IsNaN(a.sum[i]) {
+			out[i] += a.comp[i]
+		}
+	}
+	return out
+}
+
+// Mean returns the mean along axis 0 of every row added.
+func (a *AxisAccumulator) Mean() ([]float64, error) {
+	if a.rows == 0 {
+		return nil, fmt.Errorf("Mean of no rows")
+	}
+	out := a.Sum()
+	for i := range out {
+		out[i] /= float64(a.rows)
+	}
+	return out, nil
+}
+
+// SumChunks reads `r` to the end and returns the sum along axis 0 of all its rows.
+func SumChunks(r ChunkReader) ([]float64, error) {
+	acc, err := accumulateChunks(r)
+	if err != nil {
+		return nil, err
+	}
+	return acc.Sum(), nil
+}
+
+// MeanChunks reads `r` to the end and returns the mean along axis 0 of all its rows.
+func MeanChunks(r ChunkReader) ([]float64, error) {
+	acc, err := accumulateChunks(r)
+	if err != nil {
+		return nil, err
+	}
+	return acc.Mean()
+}
+
+// accumulateChunks adds every chunk of `r` to a new accumulator.
+func accumulateChunks(r ChunkReader) (*AxisAccumulator, error) {
+	acc := &AxisAccumulator{}
+	for {
+		chunk, err := r.Next()
+		if err == io.EOF {
+			return acc, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := acc.Add(chunk); err != nil {
+			return nil, err
+		}
+	}
+}
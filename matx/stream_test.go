@@ -0,0 +1,197 @@
+package matx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	n := 1
+
+	// readAll concatenates every chunk of r, returning the chunk row counts
+	readAll := func(r ChunkReader) ([]float64, []int, error) {
+		var data []float64
+		var sizes []int
+		for {
+			chunk, err := r.Next()
+			if err == io.EOF {
+				return data, sizes, nil
+			}
+			if err != nil {
+				return data, sizes, err
+			}
+			data = append(data, chunk.Data...)
+			sizes = append(sizes, chunk.Dimensions[0])
+		}
+	}
+
+	{ // CSV chunks concatenate to what ReadCSV returns
+		m := begin(t, n, "CSVChunkReader")
+		n++
+		input := "a,b,c\n1,2,3\n4,,6\n7,8,9\n10,11,12\n13,14,15\n16,17,18\n19,20,21\n"
+		opts := CSVOptions{Header: true, ColumnNames: []string{"c", "a"}}
+		whole, names, err1 := ReadCSV(strings.NewReader(input), opts)
+		r, err2 := NewCSVChunkReader(strings.NewReader(input), 3, opts)
+		data, sizes, err3 := readAll(r)
+		_, errEOF := r.Next()
+		_, errSize := NewCSVChunkReader(strings.NewReader(input), 0, opts)
+		bad, _ := NewCSVChunkReader(strings.NewReader("1,2\n3,x\n"), 5, CSVOptions{Strict: true})
+		_, errStrict := bad.Next()
+		_, errSticky := bad.Next()
+		m.end(err1 == nil && err2 == nil && err3 == nil && errEOF == io.EOF && errSize != nil &&
+			errStrict != nil && errSticky == errStrict &&
+			closeSlices(data, whole.Data, 0) && CheckDimensionEquality(sizes, []int{3, 3, 1}) &&
+			strings.Join(r.Names(), ",") == "c,a" && strings.Join(names, ",") == "c,a")
+	}
+
+	{ // Appending row blocks gives the same file as one WriteCSV call
+		m := begin(t, n, "CSVChunkWriter")
+		n++
+		a := randomSparse(9, 4, 0.5)
+		opts := CSVOptions{Names: []string{"w", "x", "y", "z"}, Delimiter: ';'}
+		var whole, chunked bytes.Buffer
+		err1 := WriteCSV(&whole, a, opts)
+		w := NewCSVChunkWriter(&chunked, opts)
+		top, _ := New(append([]float64{}, a.Data[:20]...), []int{5, 4})
+		row, _ := New(append([]float64{}, a.Data[20:24]...), []int{1, 4})
+		rest, _ := New(append([]float64{}, a.Data[24:]...), []int{3, 4})
+		narrow, _ := New([]float64{1, 2}, []int{1, 2})
+		err2 := w.Write(top)
+		err3 := w.Write(row)
+		errCols := w.Write(narrow)
+		err4 := w.Write(rest)
+		err5 := w.Close()
+		errClosed := w.Write(rest)
+		var empty bytes.Buffer
+		err6 := NewCSVChunkWriter(&empty, opts).Close()
+		// 1D blocks are columns, as in WriteCSV
+		vec, _ := New([]float64{1, 2, 3, 4, 5}, []int{5})
+		head, _ := New([]float64{1, 2, 3}, []int{3})
+		tail, _ := New([]float64{4, 5}, []int{2})
+		var vecWhole, vecChunked bytes.Buffer
+		WriteCSV(&vecWhole, vec, CSVOptions{})
+		vw := NewCSVChunkWriter(&vecChunked, CSVOptions{})
+		err7 := vw.Write(head)
+		err8 := vw.Write(tail)
+		vw.Close()
+		m.end(err1 == nil && err2 == nil && err3 == nil && err4 == nil && err5 == nil && err6 == nil &&
+			errCols != nil && errClosed != nil && chunked.String() == whole.String() && empty.String() == "w;x;y;z\n" &&
+			err7 == nil && err8 == nil && vecChunked.String() == vecWhole.String() && vecWhole.String() == "1\n2\n3\n4\n5\n")
+	}
+
+	{ // Binary streams re-chunk uneven blocks and round-trip exactly
+		m := begin(t, n, "BinaryChunkWriter/BinaryChunkReader")
+		n++
+		a := randomSparse(23, 6, 0.5)
+		a.Data[0] = math.NaN()
+		var buf bytes.Buffer
+		w, err := NewBinaryChunkWriter(&buf, []int{2, 3})
+		ok := err == nil
+		for _, span := range [][2]int{{0, 7}, {7, 8}, {8, 8}, {8, 23}} {
+			block, _ := New(a.Data[6*span[0]:6*span[1]], []int{span[1] - span[0], 2, 3})
+			ok = ok && w.Write(block) == nil
+		}
+		single, _ := New([]float64{1, 2, 3, 4, 5, 6}, []int{2, 3})
+		wrong, _ := New([]float64{1, 2, 3, 4, 5, 6}, []int{1, 6})
+		ok = ok && w.Write(single) == nil && w.Write(wrong) != nil && w.Close() == nil
+		r, err := NewBinaryChunkReader(&buf, 5)
+		data, sizes, err2 := readAll(r)
+		ok = ok && err == nil && err2 == nil && CheckDimensionEquality(r.RowShape(), []int{2, 3}) &&
+			CheckDimensionEquality(sizes, []int{5, 5, 5, 5, 4}) && math.IsNaN(data[0])
+		m.end(ok && closeSlices(data[1:23*6], a.Data[1:], 0) && closeSlices(data[23*6:], single.Data, 0))
+	}
+
+	{ // Truncated and corrupted streams are detected
+		m := begin(t, n, "BinaryChunkReader integrity checks")
+		n++
+		var buf, open bytes.Buffer
+		w, _ := NewBinaryChunkWriter(&buf, []int{4})
+		w.Write(randomSparse(10, 4, 1))
+		w.Close()
+		unclosed, _ := NewBinaryChunkWriter(&open, []int{4})
+		unclosed.Write(randomSparse(10, 4, 1))
+		unclosed.w.Flush()
+		stream := buf.Bytes()
+		drain := func(b []byte) error {
+			r, err := NewBinaryChunkReader(bytes.NewReader(b), 3)
+			if err != nil {
+				return err
+			}
+			_, _, err = readAll(r)
+			return err
+		}
+		flipped := append([]byte(nil), stream...)
+		flipped[len(stream)/2] ^= 0x01
+		header := append([]byte(nil), stream...)
+		header[16] ^= 0x01
+		errOpen := drain(open.Bytes())
+		errCut := drain(stream[:len(stream)-3])
+		errFlip := drain(flipped)
+		errHeader := drain(header)
+		errMagic := drain([]byte("GOTENMX\x00 nope nope"))
+		m.end(drain(stream) == nil && errors.Is(errOpen, io.ErrUnexpectedEOF) && errors.Is(errCut, io.ErrUnexpectedEOF) &&
+			errFlip != nil && strings.Contains(errFlip.Error(), "checksum") && errHeader != nil && errMagic != nil)
+	}
+
+	{ // Files written by EncodeBinary and Save read back in chunks
+		m := begin(t, n, "BinaryChunkReader native format")
+		n++
+		a := randomSparse(17, 3, 0.5)
+		ok := true
+		for _, opts := range []BinaryOptions{{}, {Float32: true, Compress: true}} {
+			var buf bytes.Buffer
+			EncodeBinary(&buf, a, opts)
+			whole, _ := DecodeBinary(bytes.NewReader(buf.Bytes()))
+			r, err := NewBinaryChunkReader(bytes.NewReader(buf.Bytes()), 4)
+			data, sizes, err2 := readAll(r)
+			corrupt := append([]byte(nil), buf.Bytes()...)
+			corrupt[len(corrupt)-1] ^= 0x01
+			rc, _ := NewBinaryChunkReader(bytes.NewReader(corrupt), 4)
+			_, _, errCorrupt := readAll(rc)
+			ok = ok && err == nil && err2 == nil && CheckDimensionEquality(r.RowShape(), []int{3}) &&
+				CheckDimensionEquality(sizes, []int{4, 4, 4, 4, 1}) && closeSlices(data, whole.Data, 0) &&
+				errCorrupt != nil && strings.Contains(errCorrupt.Error(), "checksum")
+		}
+		m.end(ok)
+	}
+
+	{ // Incremental reductions match Sum and Mean on the whole matrix
+		m := begin(t, n, "AxisAccumulator / SumChunks / MeanChunks")
+		n++
+		a := randomSparse(1000, 7, 0.7)
+		a.Data[3] = math.Inf(1)
+		wantSum, _ := Sum(a, 0)
+		wantMean, _ := Mean(a, 0)
+		var acc AxisAccumulator
+		ok := true
+		for start := 0; start < 1000; start += 137 {
+			end := min(start+137, 1000)
+			block, _ := New(a.Data[7*start:7*end], []int{end - start, 7})
+			ok = ok && acc.Add(block) == nil
+		}
+		mean, err := acc.Mean()
+		other, _ := New([]float64{1, 2}, []int{1, 2})
+		errShape := acc.Add(other)
+		_, errEmpty := (&AxisAccumulator{}).Mean()
+
+		var buf bytes.Buffer
+		WriteCSV(&buf, a, CSVOptions{})
+		r, _ := NewCSVChunkReader(&buf, 64, CSVOptions{})
+		csvMean, err2 := MeanChunks(r)
+		cube, _ := New([]float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{2, 2, 2})
+		var bin bytes.Buffer
+		bw, _ := NewBinaryChunkWriter(&bin, []int{2, 2})
+		bw.Write(cube)
+		bw.Write(cube)
+		bw.Close()
+		br, _ := NewBinaryChunkReader(&bin, 3)
+		cubeSum, err3 := SumChunks(br)
+		m.end(ok && err == nil && err2 == nil && err3 == nil && errShape != nil && errEmpty != nil && acc.Rows() == 1000 &&
+			closeSlices(acc.Sum(), wantSum, 1e-12) && closeSlices(mean, wantMean, 1e-12) && math.IsInf(acc.Sum()[3], 1) &&
+			closeSlices(csvMean, wantMean, 1e-12) && closeSlices(cubeSum, []float64{12, 16, 20, 24}, 0))
+	}
+}
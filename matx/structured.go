@@ -0,0 +1,602 @@
+package matx
+
+import (
+	"fmt"
+	"math"
+)
+
+// Diagonal is an n×n matrix whose only nonzeros are Data[i] at (i, i).
+type Diagonal struct {
+	Data []float64
+}
+
+// Triangular is an n×n upper or lower triangular matrix in packed row-major storage,
+// holding only the N·(N+1)/2 entries of its triangle.
+//   - Upper: row i stores columns i..N-1, starting at i·N − i·(i−1)/2.
+//   - Lower: row i stores columns 0..i, starting at i·(i+1)/2.
+type Triangular struct {
+	N     int
+	Upper bool
+	Data  []float64
+}
+
+// Symmetric is an n×n symmetric matrix in packed storage: only the lower triangle is
+// kept, row by row, so (i, j) and (j, i) share the slot at max·(max+1)/2 + min.
+type Symmetric struct {
+	N    int
+	Data []float64
+}
+
+// NewDiagonal builds a diagonal matrix from its diagonal. The slice is used as-is.
+func NewDiagonal(diag []float64) (*Diagonal, error) {
+	if len(diag) == 0 {
+		return nil, fmt.Errorf("diagonal matrix needs at least one entry")
+	}
+	return &Diagonal{Data: diag}, nil
+}
+
+// DiagonalFromMatx copies the diagonal of a square 2D matrix.
+// Returns an error if any off-diagonal entry is nonzero.
+func DiagonalFromMatx(m *Matx) (*Diagonal, error) {
+	n, err := squareSize(m)
+	if err != nil {
+		return nil, err
+	}
+	d := make([]float64, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if v := m.Data[i*n+j]; i == j {
+				d[i] = v
+			} else if v != 0 {
+				return nil, fmt.Errorf("entry (%d, %d) is off the diagonal", i, j)
+			}
+		}
+	}
+	return &Diagonal{Data: d}, nil
+}
+
+// squareSize returns the order of a square 2D matrix.
+func squareSize(m *Matx) (int, error) {
+	if m == nil {
+		return 0, fmt.Errorf("Nil matrix")
+	}
+	if len(m.Dimensions) != 2 || m.Dimensions[0] != m.Dimensions[1] {
+		return 0, fmt.Errorf("Matrix must be square")
+	}
+	return m.Dimensions[0], nil
+}
+
+// Dims returns the number of rows and columns.
+func (d *Diagonal) Dims() (int, int) { return len(d.Data), len(d.Data) }
+
+// At returns the entry at (i, j); off-diagonal entries are zero.
+func (d *Diagonal) At(i, j int) (float64, error) {
+	n := len(d.Data)
+	if i < 0 || i >= n || j < 0 || j >= n {
+		return 0, fmt.Errorf("index (%d, %d) out of bounds for %dx%d matrix", i, j, n, n)
+	}
+	if i != j {
+		return 0, nil
+	}
+	return d.Data[i], nil
+}
+
+// MulVec returns D·x in O(n).
+func (d *Diagonal) MulVec(x []float64) ([]float64, error) {
+	y := make([]float64, len(d.Data))
+	if err := d.Apply(y, x); err != nil {
+		return nil, err
+	}
+	return y, nil
+}
+
+// Apply writes D·x into dst, making *Diagonal an Operator.
+func (d *Diagonal) Apply(dst, x []float64) error {
+	if err := checkApply(len(d.Data), len(d.Data), dst, x); err != nil {
+		return err
+	}
+	for i, v := range d.Data {
+		dst[i] = v * x[i]
+	}
+	return nil
+}
+
+// ApplyTranspose writes D·x into dst, since D is its own transpose.
+func (d *Diagonal) ApplyTranspose(dst, x []float64) error {
+	return d.Apply(dst, x)
+}
+
+// Det returns the product of the diagonal.
+func (d *Diagonal) Det() float64 {
+	det := 1.0
+	for _, v := range d.Data {
+		det *= v
+	}
+	return det
+}
+
+// Inverse returns the diagonal of reciprocals. Returns an error on a zero entry.
+func (d *Diagonal) Inverse() (*Diagonal, error) {
+	inv := make([]float64, len(d.Data))
+	for i, v := range d.Data {
+		if v == 0 {
+			return nil, fmt.Errorf("Matrix is singular (zero diagonal entry %d)", i)
+		}
+		inv[i] = 1 / v
+	}
+	return &Diagonal{Data: inv}, nil
+}
+
+// Solve returns x such that D x = b in O(n).
+func (d *Diagonal) Solve(b []float64) ([]float64, error) {
+	if len(b) != len(d.Data) {
+		return nil, fmt.Errorf("right-hand side length %d does not match matrix order %d", len(b), len(d.Data))
+	}
+	x := make([]float64, len(b))
+	for i, v := range d.Data {
+		if v == 0 {
+			return nil, fmt.Errorf("Matrix is singular (zero diagonal entry %d)", i)
+		}
+		x[i] = b[i] / v
+	}
+	return x, nil
+}
+
+// ToMatx expands the diagonal matrix to a dense 2D Matx.
+func (d *Diagonal) ToMatx() (*Matx, error) {
+	n := len(d.Data)
+	m, err := Zeros([]int{n, n})
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range d.Data {
+		m.Data[i*n+i] = v
+	}
+	return m, nil
+}
+
+// NewTriangular returns an n×n zero upper or lower triangular matrix.
+func NewTriangular(n int, upper bool) (*Triangular, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid triangular matrix order %d", n)
+	}
+	return &Triangular{N: n, Upper: upper, Data: make([]float64, n*(n+1)/2)}, nil
+}
+
+// TriangularFromMatx packs the upper or lower triangle of a square 2D matrix.
+// Returns an error if any entry in the other triangle is nonzero.
+func TriangularFromMatx(m *Matx, upper bool) (*Triangular, error) {
+	n, err := squareSize(m)
+	if err != nil {
+		return nil, err
+	}
+	t, err := NewTriangular(n, upper)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			v := m.Data[i*n+j]
+			if t.inTriangle(i, j) {
+				t.Data[t.index(i, j)] = v
+			} else if v != 0 {
+				return nil, fmt.Errorf("entry (%d, %d) is outside the triangle", i, j)
+			}
+		}
+	}
+	return t, nil
+}
+
+// inTriangle reports whether (i, j) belongs to the stored triangle.
+func (t *Triangular) inTriangle(i, j int) bool {
+	if t.Upper {
+		return j >= i
+	}
+	return j <= i
+}
+
+// index returns the packed position of (i, j), which must be inside the triangle.
+func (t *Triangular) index(i, j int) int {
+	if t.Upper {
+		return i*t.N - i*(i-1)/2 + j - i
+	}
+	return i*(i+1)/2 + j
+}
+
+// row returns the stored part of row i and the column of its first entry.
+func (t *Triangular) row(i int) ([]float64, int) {
+	if t.Upper {
+		start := t.index(i, i)
+		return t.Data[start : start+t.N-i], i
+	}
+	start := t.index(i, 0)
+	return t.Data[start : start+i+1], 0
+}
+
+// Dims returns the number of rows and columns.
+func (t *Triangular) Dims() (int, int) { return t.N, t.N }
+
+// At returns the entry at (i, j); entries outside the triangle are zero.
+func (t *Triangular) At(i, j int) (float64, error) {
+	if i < 0 || i >= t.N || j < 0 || j >= t.N {
+		return 0, fmt.Errorf("index (%d, %d) out of bounds for %dx%d matrix", i, j, t.N, t.N)
+	}
+	if !t.inTriangle(i, j) {
+		return 0, nil
+	}
+	return t.Data[t.index(i, j)], nil
+}
+
+// Set stores v at (i, j). Returns an error if (i, j) is outside the triangle.
+func (t *Triangular) Set(i, j int, v float64) error {
+	if i < 0 || i >= t.N || j < 0 || j >= t.N || !t.inTriangle(i, j) {
+		return fmt.Errorf("index (%d, %d) outside the triangle of %dx%d matrix", i, j, t.N, t.N)
+	}
+	t.Data[t.index(i, j)] = v
+	return nil
+}
+
+// MulVec returns T·x in O(n²/2).
+func (t *Triangular) MulVec(x []float64) ([]float64, error) {
+	y := make([]float64, t.N)
+	if err := t.Apply(y, x); err != nil {
+		return nil, err
+	}
+	return y, nil
+}
+
+// Apply writes T·x into dst, making *Triangular an Operator.
+func (t *Triangular) Apply(dst, x []float64) error {
+	if err := checkApply(t.N, t.N, dst, x); err != nil {
+		return err
+	}
+	for i := 0; i < t.N; i++ {
+		row, first := t.row(i)
+		sum := 0.0
+		for k, v := range row {
+			sum += v * x[first+k]
+		}
+		dst[i] = sum
+	}
+	return nil
+}
+
+// ApplyTranspose writes Tᵀ·x into dst by scattering each row, making *Triangular
+// a LinearOperator.
+func (t *Triangular) ApplyTranspose(dst, x []float64) error {
+	if err := checkApply(t.N, t.N, dst, x); err != nil {
+		return err
+	}
+	for j := range dst {
+		dst[j] = 0
+	}
+	for i := 0; i < t.N; i++ {
+		row, first := t.row(i)
+		for k, v := range row {
+			dst[first+k] += v * x[i]
+		}
+	}
+	return nil
+}
+
+// Det returns the product of the diagonal.
+func (t *Triangular) Det() float64 {
+	det := 1.0
+	for i := 0; i < t.N; i++ {
+		det *= t.Data[t.index(i, i)]
+	}
+	return det
+}
+
+// Solve returns x such that T x = b by forward or backward substitution in O(n²).
+// Returns an error on a zero diagonal entry.
+func (t *Triangular) Solve(b []float64) ([]float64, error) {
+	if len(b) != t.N {
+		return nil, fmt.Errorf("right-hand side length %d does not match matrix order %d", len(b), t.N)
+	}
+
+	x := make([]float64, t.N)
+	for step := 0; step < t.N; step++ {
+		i := step
+		if t.Upper {
+			i = t.N - 1 - step
+		}
+		row, first := t.row(i)
+		sum := b[i]
+		for k, v := range row {
+			if j := first + k; j != i {
+				sum -= v * x[j]
+			}
+		}
+		d := t.Data[t.index(i, i)]
+		if d == 0 {
+			return nil, fmt.Errorf("Matrix is singular (zero diagonal entry %d)", i)
+		}
+		x[i] = sum / d
+	}
+	return x, nil
+}
+
+// Inverse returns the inverse, which is triangular with the same orientation.
+// Column j is solved against the unit vector e_j restricted to the triangle,
+// O(n³/6) in total. Returns an error on a zero diagonal entry.
+func (t *Triangular) Inverse() (*Triangular, error) {
+	n := t.N
+	inv, err := NewTriangular(n, t.Upper)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		if t.Data[t.index(i, i)] == 0 {
+			return nil, fmt.Errorf("Matrix is singular (zero diagonal entry %d)", i)
+		}
+	}
+
+	for j := 0; j < n; j++ {
+		inv.Data[inv.index(j, j)] = 1 / t.Data[t.index(j, j)]
+		if t.Upper {
+			// X[i,j] = −(Σ_{k=i+1..j} T[i,k]·X[k,j]) / T[i,i], rows above j
+			for i := j - 1; i >= 0; i-- {
+				sum := 0.0
+				for k := i + 1; k <= j; k++ {
+					sum += t.Data[t.index(i, k)] * inv.Data[inv.index(k, j)]
+				}
+				inv.Data[inv.index(i, j)] = -sum / t.Data[t.index(i, i)]
+			}
+		} else {
+			// X[i,j] = −(Σ_{k=j..i-1} T[i,k]·X[k,j]) / T[i,i], rows below j
+			for i := j + 1; i < n; i++ {
+				sum := 0.0
+				for k := j; k < i; k++ {
+					sum += t.Data[t.index(i, k)] * inv.Data[inv.index(k, j)]
+				}
+				inv.Data[inv.index(i, j)] = -sum / t.Data[t.index(i, i)]
+			}
+		}
+	}
+	return inv, nil
+}
+
+// multiplyTriangular returns t·o for two triangular matrices of the same orientation,
+// summing only over the indices where both factors can be nonzero.
+func (t *Triangular) multiplyTriangular(o *Triangular) *Triangular {
+	out, _ := NewTriangular(t.N, t.Upper)
+	for i := 0; i < t.N; i++ {
+		for j := 0; j < t.N; j++ {
+			if !t.inTriangle(i, j) {
+				continue
+			}
+			lo, hi := j, i // lower: k in [j, i]
+			if t.Upper {
+				lo, hi = i, j
+			}
+			sum := 0.0
+			for k := lo; k <= hi; k++ {
+				sum += t.Data[t.index(i, k)] * o.Data[o.index(k, j)]
+			}
+			out.Data[out.index(i, j)] = sum
+		}
+	}
+	return out
+}
+
+// ToMatx expands the triangular matrix to a dense 2D Matx.
+func (t *Triangular) ToMatx() (*Matx, error) {
+	m, err := Zeros([]int{t.N, t.N})
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < t.N; i++ {
+		row, first := t.row(i)
+		copy(m.Data[i*t.N+first:], row)
+	}
+	return m, nil
+}
+
+// NewSymmetric returns an n×n zero symmetric matrix.
+func NewSymmetric(n int) (*Symmetric, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid symmetric matrix order %d", n)
+	}
+	return &Symmetric{N: n, Data: make([]float64, n*(n+1)/2)}, nil
+}
+
+// SymmetricFromMatx packs a square 2D matrix. Returns an error if it is not symmetric.
+func SymmetricFromMatx(m *Matx) (*Symmetric, error) {
+	n, err := squareSize(m)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewSymmetric(n)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			if m.Data[i*n+j] != m.Data[j*n+i] {
+				return nil, fmt.Errorf("matrix is not symmetric at (%d, %d)", i, j)
+			}
+			s.Data[i*(i+1)/2+j] = m.Data[i*n+j]
+		}
+	}
+	return s, nil
+}
+
+// index returns the packed position shared by (i, j) and (j, i).
+func (s *Symmetric) index(i, j int) int {
+	if j > i {
+		i, j = j, i
+	}
+	return i*(i+1)/2 + j
+}
+
+// Dims returns the number of rows and columns.
+func (s *Symmetric) Dims() (int, int) { return s.N, s.N }
+
+// At returns the entry at (i, j).
+func (s *Symmetric) At(i, j int) (float64, error) {
+	if i < 0 || i >= s.N || j < 0 || j >= s.N {
+		return 0, fmt.Errorf("index (%d, %d) out of bounds for %dx%d matrix", i, j, s.N, s.N)
+	}
+	return s.Data[s.index(i, j)], nil
+}
+
+// Set stores v at both (i, j) and (j, i).
+func (s *Symmetric) Set(i, j int, v float64) error {
+	if i < 0 || i >= s.N || j < 0 || j >= s.N {
+		return fmt.Errorf("index (%d, %d) out of bounds for %dx%d matrix", i, j, s.N, s.N)
+	}
+	s.Data[s.index(i, j)] = v
+	return nil
+}
+
+// MulVec returns S·x, reading each stored entry once.
+func (s *Symmetric) MulVec(x []float64) ([]float64, error) {
+	y := make([]float64, s.N)
+	if err := s.Apply(y, x); err != nil {
+		return nil, err
+	}
+	return y, nil
+}
+
+// Apply writes S·x into dst, making *Symmetric an Operator.
+func (s *Symmetric) Apply(dst, x []float64) error {
+	if err := checkApply(s.N, s.N, dst, x); err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = 0
+	}
+	for i := 0; i < s.N; i++ {
+		row := s.Data[i*(i+1)/2 : i*(i+1)/2+i+1]
+		for j, v := range row[:i] {
+			dst[i] += v * x[j]
+			dst[j] += v * x[i]
+		}
+		dst[i] += row[i] * x[i]
+	}
+	return nil
+}
+
+// ApplyTranspose writes S·x into dst, since S is its own transpose.
+func (s *Symmetric) ApplyTranspose(dst, x []float64) error {
+	return s.Apply(dst, x)
+}
+
+// cholesky returns the packed lower Cholesky factor, or false if S is not
+// positive definite.
+func (s *Symmetric) cholesky() ([]float64, bool) {
+	l := make([]float64, len(s.Data))
+	for i := 0; i < s.N; i++ {
+		ri := i * (i + 1) / 2
+		for j := 0; j <= i; j++ {
+			rj := j * (j + 1) / 2
+			sum := s.Data[ri+j]
+			for k := 0; k < j; k++ {
+				sum -= l[ri+k] * l[rj+k]
+			}
+			if i == j {
+				if sum <= 0 || math.IsNaN(sum) {
+					return nil, false
+				}
+				l[ri+i] = math.Sqrt(sum)
+			} else {
+				l[ri+j] = sum / l[rj+j]
+			}
+		}
+	}
+	return l, true
+}
+
+// Det returns the determinant, from the packed Cholesky factor when S is positive
+// definite and from a dense LU otherwise.
+func (s *Symmetric) Det() (float64, error) {
+	if l, ok := s.cholesky(); ok {
+		det := 1.0
+		for i := 0; i < s.N; i++ {
+			d := l[i*(i+1)/2+i]
+			det *= d * d
+		}
+		return det, nil
+	}
+	m, err := s.ToMatx()
+	if err != nil {
+		return 0, err
+	}
+	return Det(m)
+}
+
+// Solve returns x such that S x = b, using the packed Cholesky factor when S is
+// positive definite and a dense LU otherwise.
+func (s *Symmetric) Solve(b []float64) ([]float64, error) {
+	if len(b) != s.N {
+		return nil, fmt.Errorf("right-hand side length %d does not match matrix order %d", len(b), s.N)
+	}
+
+	l, ok := s.cholesky()
+	if !ok {
+		m, err := s.ToMatx()
+		if err != nil {
+			return nil, err
+		}
+		lu, err := LUFactor(m)
+		if err != nil {
+			return nil, err
+		}
+		return lu.Solve(b)
+	}
+
+	// L·y = b, then Lᵀ·x = y
+	x := make([]float64, s.N)
+	for i := 0; i < s.N; i++ {
+		ri := i * (i + 1) / 2
+		sum := b[i]
+		for k := 0; k < i; k++ {
+			sum -= l[ri+k] * x[k]
+		}
+		x[i] = sum / l[ri+i]
+	}
+	for i := s.N - 1; i >= 0; i-- {
+		x[i] /= l[i*(i+1)/2+i]
+		for k := 0; k < i; k++ {
+			x[k] -= l[i*(i+1)/2+k] * x[i]
+		}
+	}
+	return x, nil
+}
+
+// Inverse returns the inverse as a new Symmetric, via a dense LU.
+func (s *Symmetric) Inverse() (*Symmetric, error) {
+	m, err := s.ToMatx()
+	if err != nil {
+		return nil, err
+	}
+	inv, err := Invert(m)
+	if err != nil {
+		return nil, err
+	}
+
+	// Average the two triangles to remove rounding asymmetry
+	out, _ := NewSymmetric(s.N)
+	for i := 0; i < s.N; i++ {
+		for j := 0; j <= i; j++ {
+			out.Data[i*(i+1)/2+j] = (inv.Data[i*s.N+j] + inv.Data[j*s.N+i]) / 2
+		}
+	}
+	return out, nil
+}
+
+// ToMatx expands the symmetric matrix to a dense 2D Matx.
+func (s *Symmetric) ToMatx() (*Matx, error) {
+	m, err := Zeros([]int{s.N, s.N})
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < s.N; i++ {
+		for j := 0; j <= i; j++ {
+			v := s.Data[i*(i+1)/2+j]
+			m.Data[i*s.N+j], m.Data[j*s.N+i] = v, v
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,191 @@
+package matx
+
+import (
+	"io"
+	"math"
+	"testing"
+)
+
+// denseEqual reports whether two matrices have the same shape and entries within tol.
+func denseEqual(a, b Matrix, tol float64) bool {
+	ma, err1 := ToDense(a)
+	mb, err2 := ToDense(b)
+	if err1 != nil || err2 != nil || !CheckDimensionEquality(ma.Dimensions, mb.Dimensions) {
+		return false
+	}
+	return closeSlices(ma.Data, mb.Data, tol)
+}
+
+func TestStructured(t *testing.T) {
+	n := 1
+	InitExamples()
+	diagM, _ := GiveMatx("matxDiag3x3")
+	upperM, _ := GiveMatx("matxUpperTri3x3")
+	lowerM, _ := GiveMatx("matxLowerTri3x3")
+	symM, _ := GiveMatx("matxSymmetric3x3")
+
+	{ // Every representation satisfies Matrix and round-trips through ToDense
+		m := begin(t, n, "Matrix interface / ToDense()")
+		n++
+		diag, err1 := DiagonalFromMatx(diagM)
+		upper, err2 := TriangularFromMatx(upperM, true)
+		lower, err3 := TriangularFromMatx(lowerM, false)
+		sym, err4 := SymmetricFromMatx(symM)
+		_, errNotDiag := DiagonalFromMatx(symM)
+		_, errNotTri := TriangularFromMatx(lowerM, true)
+		_, errNotSym := SymmetricFromMatx(upperM)
+		csr, _ := CSRFromMatx(symM)
+		v, _ := upper.At(2, 0)
+		ok := err1 == nil && err2 == nil && err3 == nil && err4 == nil &&
+			errNotDiag != nil && errNotTri != nil && errNotSym != nil && v == 0 &&
+			len(upper.Data) == 6 && len(sym.Data) == 6
+		for _, pair := range [][2]Matrix{{diag, diagM}, {upper, upperM}, {lower, lowerM}, {sym, symM}, {csr, symM}} {
+			ok = ok && denseEqual(pair[0], pair[1], 0)
+		}
+		var nilMatx *Matx
+		rows, cols := nilMatx.Dims()
+		_, errAt := nilMatx.At(0, 0)
+		_, errDense := ToDense(nilMatx)
+		errWrite := WriteMatrixMarket(io.Discard, nilMatx, MatrixMarketOptions{})
+		// Typed nil pointers of the other types are rejected by every entry point
+		one, _ := NewDiagonal([]float64{1})
+		for _, a := range []Matrix{(*Diagonal)(nil), (*Triangular)(nil), (*Symmetric)(nil), (*Tridiagonal)(nil),
+			(*Band)(nil), (*COO)(nil), (*CSR)(nil), (*CSC)(nil)} {
+			_, err1 := MatrixDet(a)
+			_, err2 := MatrixInvert(a)
+			_, err3 := MatrixSolve(a, []float64{1})
+			_, err4 := MatrixMultiply(one, a)
+			_, err5 := ToDense(a)
+			_, err6 := NewLinearOperator(a)
+			err7 := WriteMatrixMarket(io.Discard, a, MatrixMarketOptions{})
+			if err1 == nil || err2 == nil || err3 == nil || err4 == nil || err5 == nil || err6 == nil || err7 == nil {
+				t.Logf("%T: nil not rejected", a)
+				ok = false
+			}
+		}
+		m.end(ok && rows == 0 && cols == 0 && errAt != nil && errDense != nil && errWrite != nil)
+	}
+
+	{ // Structured Det agrees with the dense LU
+		m := begin(t, n, "MatrixDet()")
+		n++
+		diag, _ := DiagonalFromMatx(diagM)
+		upper, _ := TriangularFromMatx(upperM, true)
+		sym, _ := SymmetricFromMatx(symM)
+		tri, _ := NewTridiagonal([]float64{1, 1}, []float64{3, 3, 3}, []float64{2, 2})
+		ok := true
+		for _, a := range []Matrix{diag, upper, sym, tri, symM} {
+			got, err := MatrixDet(a)
+			dense, _ := ToDense(a)
+			want, _ := Det(dense)
+			ok = ok && err == nil && math.Abs(got-want) < 1e-10
+		}
+		spd, _ := NewSymmetric(2)
+		spd.Set(0, 0, 4)
+		spd.Set(1, 0, 2)
+		spd.Set(1, 1, 3)
+		d, _ := MatrixDet(spd)
+		_, errShape := MatrixDet(&Matx{Data: make([]float64, 6), Dimensions: []int{2, 3}})
+		// An exactly singular band matrix has determinant zero rather than an error
+		flat, _ := NewTridiagonal([]float64{1, 1}, []float64{1, 1, 0}, []float64{1, 0})
+		zero, errZero := MatrixDet(flat)
+		m.end(ok && math.Abs(d-8) < 1e-12 && errShape != nil && zero == 0 && errZero == nil)
+	}
+
+	{ // Structured inverses keep their type
+		m := begin(t, n, "MatrixInvert()")
+		n++
+		diag, _ := DiagonalFromMatx(diagM)
+		upper, _ := TriangularFromMatx(upperM, true)
+		lower, _ := TriangularFromMatx(lowerM, false)
+		sym, _ := SymmetricFromMatx(symM)
+		ok := true
+		for _, a := range []Matrix{diag, upper, lower, sym} {
+			inv, err := MatrixInvert(a)
+			if err != nil {
+				ok = false
+				break
+			}
+			prod, _ := MatrixMultiply(a, inv)
+			id, _ := Identity(3, 3)
+			ok = ok && denseEqual(prod, id, 1e-10)
+		}
+		dInv, _ := MatrixInvert(diag)
+		uInv, _ := MatrixInvert(upper)
+		sInv, _ := MatrixInvert(sym)
+		_, isDiag := dInv.(*Diagonal)
+		tri, isTri := uInv.(*Triangular)
+		_, isSym := sInv.(*Symmetric)
+		singular, _ := NewDiagonal([]float64{1, 0})
+		bad, errSingular := MatrixInvert(singular)
+		m.end(ok && isDiag && isTri && tri.Upper && isSym && errSingular != nil && bad == nil)
+	}
+
+	{ // Products dispatch to structured kernels and match the dense product
+		m := begin(t, n, "MatrixMultiply()")
+		n++
+		diag, _ := DiagonalFromMatx(diagM)
+		upper, _ := TriangularFromMatx(upperM, true)
+		lower, _ := TriangularFromMatx(lowerM, false)
+		sym, _ := SymmetricFromMatx(symM)
+		ok := true
+		for _, pair := range [][2]Matrix{{diag, diag}, {diag, sym}, {upper, diag}, {upper, upper}, {lower, lower}, {upper, lower}, {sym, symM}} {
+			got, err := MatrixMultiply(pair[0], pair[1])
+			a, _ := ToDense(pair[0])
+			b, _ := ToDense(pair[1])
+			want, _ := Multiply(a, b)
+			ok = ok && err == nil && denseEqual(got, want, 1e-12)
+		}
+		dd, _ := MatrixMultiply(diag, diag)
+		uu, _ := MatrixMultiply(upper, upper)
+		_, isDiag := dd.(*Diagonal)
+		_, isTri := uu.(*Triangular)
+		_, errShape := MatrixMultiply(diag, &Matx{Data: make([]float64, 4), Dimensions: []int{2, 2}})
+		m.end(ok && isDiag && isTri && errShape != nil)
+	}
+
+	{ // Solves dispatch by type and satisfy A x = b
+		m := begin(t, n, "MatrixSolve()")
+		n++
+		diag, _ := DiagonalFromMatx(diagM)
+		upper, _ := TriangularFromMatx(upperM, true)
+		lower, _ := TriangularFromMatx(lowerM, false)
+		sym, _ := SymmetricFromMatx(symM)
+		csr, _ := CSRFromMatx(upperM)
+		band, _ := BandFromMatx(lowerM, 2, 0)
+		b := []float64{1, -2, 3}
+		ok := true
+		for _, a := range []Matrix{diag, upper, lower, sym, csr, band, symM} {
+			x, err := MatrixSolve(a, b)
+			dense, _ := ToDense(a)
+			op, _ := DenseOperator(dense)
+			ok = ok && err == nil && relResidual(op, b, x) < 1e-12
+		}
+		zero, _ := NewTriangular(2, false)
+		_, errSingular := MatrixSolve(zero, []float64{1, 1})
+		_, errLen := upper.Solve([]float64{1})
+		m.end(ok && errSingular != nil && errLen != nil)
+	}
+
+	{ // Packed matvecs agree with the dense product
+		m := begin(t, n, "MulVec() on structured types")
+		n++
+		x := []float64{1, 2, -1}
+		diag, _ := DiagonalFromMatx(diagM)
+		upper, _ := TriangularFromMatx(upperM, true)
+		lower, _ := TriangularFromMatx(lowerM, false)
+		sym, _ := SymmetricFromMatx(symM)
+		ok := true
+		for _, a := range []interface {
+			Matrix
+			Operator
+		}{diag, upper, lower, sym} {
+			dense, _ := ToDense(a)
+			op, _ := DenseOperator(dense)
+			want, got := make([]float64, 3), make([]float64, 3)
+			op.Apply(want, x)
+			ok = ok && a.Apply(got, x) == nil && closeSlices(got, want, 1e-12)
+		}
+		m.end(ok)
+	}
+}
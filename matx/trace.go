@@ -0,0 +1,244 @@
+package matx
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// OpEvent describes a single completed matrix operation.
+// - Op is the operation name, e.g. "Multiply".
+// - Shapes holds a copy of the Dimensions of each matrix input (nil for nil inputs).
+// - FLOPs and Bytes are estimates of floating point work and of memory allocated for results.
+// - Err is the error returned by the operation, if any.
+type OpEvent struct {
+	Op       string
+	Shapes   [][]int
+	Duration time.Duration
+	FLOPs    int64
+	Bytes    int64
+	Err      error
+}
+
+// TraceHook receives an OpEvent after every instrumented operation finishes.
+// Hooks may be called concurrently from several goroutines and must be safe for that.
+// Operations built on other public operations (Invert on LUFactor, for example)
+// report the nested events as well as their own, and count only their own work in
+// FLOPs and Bytes, so totals over all events do not count anything twice.
+type TraceHook func(OpEvent)
+
+// traceHook holds the registered hook; a nil pointer disables tracing.
+var traceHook atomic.Pointer[TraceHook]
+
+// SetTraceHook registers `hook` to receive events from every instrumented operation
+// and returns the previously registered hook. Passing nil turns tracing off, which is
+// the default and costs a single atomic load per operation.
+//
+// Every public operation is instrumented except the ones that other operations use as
+// building blocks, where an event per call would flood the hook: New and Zeros, which
+// allocate the result of nearly every operation (their memory is counted in that
+// operation's Bytes), the element accessors Get and Set, the shape queries Size,
+// CheckDimensionEquality and CheckMultiplicationCondition, and the printing and
+// example helpers.
+func SetTraceHook(hook TraceHook) TraceHook {
+	var prev *TraceHook
+	if hook == nil {
+		prev = traceHook.Swap(nil)
+	} else {
+		prev = traceHook.Swap(&hook)
+	}
+	if prev == nil {
+		return nil
+	}
+	return *prev
+}
+
+// traceSpan is an in-flight operation started while a hook was registered.
+type traceSpan struct {
+	hook   TraceHook
+	op     string
+	shapes [][]int
+	start  time.Time
+}
+
+// startTrace begins timing `op` if tracing is on; otherwise it returns nil.
+func startTrace(op string, inputs ...*Matx) *traceSpan {
+	hook := traceHook.Load()
+	if hook == nil {
+		return nil
+	}
+
+	shapes := make([][]int, len(inputs))
+	for i, m := range inputs {
+		if m != nil {
+			shapes[i] = append([]int(nil), m.Dimensions...)
+		}
+	}
+	return &traceSpan{hook: *hook, op: op, shapes: shapes, start: time.Now()}
+}
+
+// startTraceShapes is startTrace for inputs that are not dense matrices
+// (sparse matrices, vectors); callers pass the input shapes directly.
+func startTraceShapes(op string, shapes ...[]int) *traceSpan {
+	hook := traceHook.Load()
+	if hook == nil {
+		return nil
+	}
+	return &traceSpan{hook: *hook, op: op, shapes: shapes, start: time.Now()}
+}
+
+// end reports the finished operation to the hook captured by startTrace.
+func (s *traceSpan) end(flops, bytes int64, err error) {
+	if s == nil {
+		return
+	}
+	s.hook(OpEvent{
+		Op:       s.op,
+		Shapes:   s.shapes,
+		Duration: time.Since(s.start),
+		FLOPs:    flops,
+		Bytes:    bytes,
+		Err:      err,
+	})
+}
+
+// elementCount returns the number of elements of `m`, treating nil as empty.
+func elementCount(m *Matx) int64 {
+	if m == nil {
+		return 0
+	}
+	return int64(len(m.Data))
+}
+
+// dimOf returns the size of `axis` of `m`, or 0 if `m` is nil or has fewer axes.
+func dimOf(m *Matx, axis int) int64 {
+	if m == nil || axis >= len(m.Dimensions) {
+		return 0
+	}
+	return int64(m.Dimensions[axis])
+}
+
+// squareOrder returns the number of rows of a 2D matrix, or 0 for anything else.
+func squareOrder(m *Matx) int64 {
+	if m == nil || len(m.Dimensions) != 2 {
+		return 0
+	}
+	return int64(m.Dimensions[0])
+}
+
+// luFlops estimates the work of an LU factorization of `m`: 2n³/3.
+func luFlops(m *Matx) int64 {
+	n := squareOrder(m)
+	return 2 * n * n * n / 3
+}
+
+// multiplyFlops estimates the work of the product m1×m2: 2·rows·inner·cols.
+func multiplyFlops(m1, m2 *Matx) int64 {
+	if m1 == nil || m2 == nil || len(m1.Dimensions) != 2 || len(m2.Dimensions) != 2 {
+		return 0
+	}
+	return 2 * int64(m1.Dimensions[0]) * int64(m1.Dimensions[1]) * int64(m2.Dimensions[1])
+}
+
+// OpStats accumulates the events reported for one operation name.
+type OpStats struct {
+	Calls  int
+	Errors int
+	Total  time.Duration
+	Max    time.Duration
+	FLOPs  int64
+	Bytes  int64
+}
+
+// TraceAggregator is a ready-made TraceHook target that tallies events per operation.
+// Register it with SetTraceHook(agg.Hook).
+type TraceAggregator struct {
+	mu    sync.Mutex
+	stats map[string]*OpStats
+}
+
+// NewTraceAggregator returns an empty aggregator.
+func NewTraceAggregator() *TraceAggregator {
+	return &TraceAggregator{stats: map[string]*OpStats{}}
+}
+
+// Hook records a single event. Its method value satisfies TraceHook.
+func (a *TraceAggregator) Hook(e OpEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.stats[e.Op]
+	if !ok {
+		s = &OpStats{}
+		a.stats[e.Op] = s
+	}
+	s.Calls++
+	if e.Err != nil {
+		s.Errors++
+	}
+	s.Total += e.Duration
+	if e.Duration > s.Max {
+		s.Max = e.Duration
+	}
+	s.FLOPs += e.FLOPs
+	s.Bytes += e.Bytes
+}
+
+// Stats returns a snapshot of the per-operation totals.
+func (a *TraceAggregator) Stats() map[string]OpStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	out := make(map[string]OpStats, len(a.stats))
+	for op, s := range a.stats {
+		out[op] = *s
+	}
+	return out
+}
+
+// Reset discards everything recorded so far.
+func (a *TraceAggregator) Reset() {
+	a.mu.Lock()
+	a.stats = map[string]*OpStats{}
+	a.mu.Unlock()
+}
+
+// Fprint writes a summary table to `w`, one row per operation, sorted by total time.
+func (a *TraceAggregator) Fprint(w io.Writer) error {
+	stats := a.Stats()
+	ops := make([]string, 0, len(stats))
+	for op := range stats {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if stats[ops[i]].Total != stats[ops[j]].Total {
+			return stats[ops[i]].Total > stats[ops[j]].Total
+		}
+		return ops[i] < ops[j]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\tcalls\terrors\ttotal\tmean\tmax\tGFLOP\tGFLOP/s\tMB alloc\t")
+	for _, op := range ops {
+		s := stats[op]
+		mean := s.Total / time.Duration(s.Calls)
+		rate := 0.0
+		if s.Total > 0 {
+			rate = float64(s.FLOPs) / s.Total.Seconds() / 1e9
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%.3f\t%.3f\t%.2f\t\n",
+			op, s.Calls, s.Errors, s.Total, mean, s.Max,
+			float64(s.FLOPs)/1e9, rate, float64(s.Bytes)/(1<<20))
+	}
+	return tw.Flush()
+}
+
+// Print writes the summary table to standard output.
+func (a *TraceAggregator) Print() {
+	a.Fprint(os.Stdout)
+}
@@ -0,0 +1,131 @@
+package matx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	n := 1
+
+	{ // Aggregator collects events from instrumented ops
+		m := begin(t, n, "SetTraceHook() with aggregator")
+		n++
+		agg := NewTraceAggregator()
+		prev := SetTraceHook(agg.Hook)
+
+		a, _ := New([]float64{1, 2, 3, 4, 5, 6}, []int{2, 3})
+		b, _ := New([]float64{1, 2, 3, 4, 5, 6}, []int{3, 2})
+		Multiply(a, b)
+		Multiply(a, b)
+		Sum(a, 0)
+		Add(a, b)
+
+		SetTraceHook(prev)
+		Multiply(a, b)
+
+		stats := agg.Stats()
+		ok := stats["Multiply"].Calls == 2 &&
+			stats["Multiply"].FLOPs == 2*2*(2*3*2) &&
+			stats["Multiply"].Bytes == 2*8*4 &&
+			stats["Sum"].Calls == 1 &&
+			stats["Add"].Errors == 1
+
+		var buf bytes.Buffer
+		agg.Fprint(&buf)
+		ok = ok && strings.Contains(buf.String(), "Multiply") && strings.Contains(buf.String(), "GFLOP/s")
+		m.end(ok)
+	}
+
+	{ // Events carry input shapes
+		m := begin(t, n, "OpEvent shapes")
+		n++
+		var events []OpEvent
+		a, _ := Ones([]int{4, 5})
+		prev := SetTraceHook(func(e OpEvent) { events = append(events, e) })
+		Transpose(a)
+		SetTraceHook(prev)
+
+		m.end(len(events) == 1 && events[0].Op == "Transpose" &&
+			len(events[0].Shapes) == 1 && events[0].Shapes[0][0] == 4 && events[0].Shapes[0][1] == 5)
+	}
+
+	{ // Nested operations are not counted twice
+		m := begin(t, n, "Det()/Invert() report only their own work")
+		n++
+		a, _ := New([]float64{4, 1, 2, 3}, []int{2, 2})
+		agg := NewTraceAggregator()
+		prev := SetTraceHook(agg.Hook)
+		Det(a)
+		Invert(a)
+		SetTraceHook(prev)
+
+		stats := agg.Stats()
+		m.end(stats["LUFactor"].Calls == 2 && stats["Clone"].Calls == 2 &&
+			stats["Det"].FLOPs == 2 && stats["Det"].Bytes == 0 &&
+			stats["Invert"].FLOPs == 2*8 && stats["Invert"].Bytes == 8*4)
+	}
+
+	{ // Sparse kernels and reductions are traced like their dense siblings
+		m := begin(t, n, "Sparse operations traced")
+		n++
+		a, _ := New([]float64{1, 0, 2, 0, 3, 0}, []int{2, 3})
+		s, _ := CSRFromMatx(a)
+		csc := s.ToCSC()
+		agg := NewTraceAggregator()
+		prev := SetTraceHook(agg.Hook)
+		s.MulVecTrans([]float64{1, 1})
+		csc.MulVec([]float64{1, 1, 1})
+		MultiplyCSR(s, s.Transpose())
+		HadamardCSR(s, s)
+		s.Scale(2)
+		SumCSR(s, 0)
+		MeanCSR(s, 1)
+		MinCSR(s, 0)
+		MaxCSR(s, 1)
+		SetTraceHook(prev)
+
+		stats := agg.Stats()
+		ok := stats["MultiplyCSR"].FLOPs == 0 && stats["NumericMultiplyCSR"].FLOPs == 2*2 &&
+			stats["SumCSR"].Calls == 1 && stats["SumCSR"].Bytes == 8*3
+		for _, op := range []string{"SpMVTrans", "SpMVCSC", "NumericMultiplyCSR", "HadamardCSR", "ScaleCSR", "MeanCSR", "MinCSR", "MaxCSR"} {
+			if stats[op].Calls != 1 {
+				t.Logf("%s: %d calls", op, stats[op].Calls)
+				ok = false
+			}
+		}
+		m.end(ok)
+	}
+
+	{ // Helpers and constructors are traced too, with the memory of their results
+		m := begin(t, n, "Coverage of helpers and constructors")
+		n++
+		agg := NewTraceAggregator()
+		prev := SetTraceHook(agg.Hook)
+		a, _ := Rand(3, 4)
+		id, _ := Identity(3, 3)
+		c, _ := Clone(a)
+		IsSymmetric(id)
+		IsInvertible(id)
+		RowSwap(c, 0, 1)
+		GetRow(c, 0)
+		GetCol(c, 1)
+		c.Flatten()
+		Ones([]int{2, 2})
+		MultiplyTiled(id, a, 2)
+		SetTraceHook(prev)
+
+		stats := agg.Stats()
+		ok := stats["MultiplyTiled"].Bytes == 8*3*4 && stats["MultiplyTiled"].FLOPs == 0 &&
+			stats["MultiplyTiledInto"].Bytes == 0 && stats["MultiplyTiledInto"].FLOPs == 2*3*3*4 &&
+			stats["GetCol"].Bytes == 8*3 && stats["Rand"].Bytes == 8*12
+		for _, op := range []string{"Rand", "Identity", "Clone", "IsSymmetric", "IsInvertible", "RowSwap", "GetRow", "GetCol", "Flatten", "Ones"} {
+			if stats[op].Calls == 0 {
+				t.Logf("%s: not traced", op)
+				ok = false
+			}
+		}
+		m.end(ok)
+	}
+}
@@ -1,10 +1,19 @@
 package matx
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"os"
+	"unsafe"
+)
 
 // Clone creates a deep copy of the given matrix `m`, replicating both data and dimensions.
 // Returns the cloned matrix or an error if construction of the new matrix fails.
-func Clone(m *Matx) (*Matx, error) {
+func Clone(m *Matx) (result *Matx, err error) {
+	if span := startTrace("Clone", m); span != nil {
+		defer func() { span.end(0, 8*elementCount(result), err) }()
+	}
+
 	cloneData := make([]float64, len(m.Data))
 	copy(cloneData, m.Data)
 
@@ -18,86 +27,66 @@ func Clone(m *Matx) (*Matx, error) {
 	return cloneMatx, nil
 }
 
-// PrintMatx prints the contents of a matrix in a structured, human-readable format.
-// Optional `format` parameter controls numeric formatting (e.g., float precision, scientific notation).
-func PrintMatx(m *Matx, format ...string) {
-	if m == nil || m.Data == nil || len(m.Dimensions) == 0 {
-		fmt.Println("Invalid or empty matrix")
-		return
-	}
-
-	// Default format
-	f := "%.4f"
+// formatAliases maps the short format names accepted by PrintMatx and the text
+// writers to fmt verbs.
+var formatAliases = map[string]string{
+	"int":   "%.0f",
+	"float": "%.4f",
+	"f":     "%.4f",
+	"short": "%.2f",
+	"sci":   "%e",
+	"g":     "%g",
+}
 
-	// Predefined format aliases
-	formatAliases := map[string]string{
-		"int":   "%.0f",
-		"float": "%.4f",
-		"f":     "%.4f",
-		"short": "%.2f",
-		"sci":   "%e",
-		"g":     "%g",
+// resolveFormat returns the fmt verb for an optional alias or verb, or `def` when none is given.
+func resolveFormat(def string, format ...string) string {
+	if len(format) == 0 || format[0] == "" {
+		return def
 	}
-
-	// Override format if specified
-	if len(format) > 0 {
-		if alias, ok := formatAliases[format[0]]; ok {
-			f = alias
-		} else {
-			f = format[0]
-		}
+	if alias, ok := formatAliases[format[0]]; ok {
+		return alias
 	}
+	return format[0]
+}
 
-	data := m.Data
-	shape := m.Dimensions
-
-	// Recursive printing for nested matrix dimensions
-	var printRecursive func(offset, dim, depth int)
-	printRecursive = func(offset, dim, depth int) {
-		indent := func(d int) {
-			for i := 0; i < d; i++ {
-				fmt.Print("  ")
-			}
+// shapeSize returns the number of elements of a matrix of shape `dims`, or false
+// when a dimension is negative or the product overflows an int.
+func shapeSize(dims []int) (int, bool) {
+	size := 1
+	for _, d := range dims {
+		if d < 0 || (d > 0 && size > math.MaxInt/d) {
+			return 0, false
 		}
+		size *= d
+	}
+	return size, true
+}
 
-		if dim == len(shape)-1 {
-			// Base case: print final dimension elements
-			indent(depth)
-			fmt.Print("{")
-			for i := 0; i < shape[dim]; i++ {
-				if i > 0 {
-					fmt.Print(", ")
-				}
-				fmt.Printf(f, data[offset+i])
-			}
-			fmt.Print("}")
-		} else {
-			// Recursive case: traverse higher dimensions
-			indent(depth)
-			fmt.Print("{\n")
-			stride := 1
-			for _, s := range shape[dim+1:] {
-				stride *= s
-			}
-			for i := 0; i < shape[dim]; i++ {
-				if i > 0 {
-					fmt.Print(",\n")
-				}
-				printRecursive(offset+i*stride, dim+1, depth+1)
-			}
-			fmt.Print("\n")
-			indent(depth)
-			fmt.Print("}")
-		}
+// overlaps reports whether `a` and `b` share any element of their backing storage.
+func overlaps(a, b []float64) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
 	}
+	a0, b0 := uintptr(unsafe.Pointer(&a[0])), uintptr(unsafe.Pointer(&b[0]))
+	return a0 < b0+8*uintptr(len(b)) && b0 < a0+8*uintptr(len(a))
+}
 
-	printRecursive(0, 0, 0)
-	fmt.Println()
+// PrintMatx prints the contents of a matrix to standard output in a structured, human-readable format.
+// Optional `format` parameter controls numeric formatting (e.g., float precision, scientific notation).
+// Columns are right-aligned and large matrices are summarized; see Fprint and PrintOptions.
+func PrintMatx(m *Matx, format ...string) {
+	if err := Fprint(os.Stdout, m, format...); err != nil {
+		fmt.Println("Invalid or empty matrix")
+	}
 }
 
 // Reverse returns a new matrix where the specified axis of the input matrix `m` is reversed.
 // Axis must be within the bounds of the matrix dimensions.
-func Reverse(m *Matx, axis int) (*Matx, error) {
+func Reverse(m *Matx, axis int) (result *Matx, err error) {
+	if span := startTrace("Reverse", m); span != nil {
+		defer func() { span.end(0, 8*elementCount(result), err) }()
+	}
+
 	if m == nil {
 		return nil, fmt.Errorf("Matrix is nil")
 	}